@@ -131,15 +131,24 @@ type TaskSetSpec struct {
 //  3. [File] - Read a file from the component directory.
 //  4. [Kustomize] - Patch and transform prior outputs.
 //  5. [Join] - Concatenate prior outputs.
-//  6. [Command] - Execute a user-defined command.
-//  7. [Artifact] - Write the final artifact (sink).
+//  6. [Filter] - Keep or drop resources of prior outputs.
+//  7. [Patch] - Patch resources of prior outputs.
+//  8. [Transform] - Transform the metadata of resources of prior outputs.
+//  9. [Split] - Split resources of prior outputs into one file each.
+//  10. [Jsonnet] - Evaluate a Jsonnet file from the component directory.
+//  11. [CUE] - Validate or transform resources of prior outputs with CUE.
+//  12. [Command] - Execute a user-defined command.
+//  13. [Artifact] - Write the final artifact (sink).
 //
 // The Go type does not enforce the constraint; holos enforces it with
 // per-kind guards in the published CUE schema and revalidates it at execution
 // time, along with the per-kind Inputs and Output cardinality rules.
 type Task struct {
 	// Kind discriminates the task behavior.
-	Kind string `json:"kind" yaml:"kind" cue:"\"Resources\" | \"Helm\" | \"File\" | \"Kustomize\" | \"Join\" | \"Command\" | \"Artifact\""`
+	Kind string `json:"kind" yaml:"kind" cue:"\"Resources\" | \"Helm\" | \"File\" | \"Kustomize\" | \"Join\" | \"Filter\" | \"Patch\" | \"Transform\" | \"Split\" | \"Jsonnet\" | \"CUE\" | \"Command\" | \"Artifact\""`
+	// Metadata represents data about the task.  Labels select the tasks to
+	// execute with the --task-selector flag of holos render.
+	Metadata TaskMetadata `json:"metadata,omitempty" yaml:"metadata,omitempty"`
 	// DependsOn declares tasks that must complete before this task runs, keyed
 	// by task name or canonical ID — a struct, not a list, so mixins compose
 	// ordering edges by unification.  Use for ordering constraints with no data
@@ -152,6 +161,17 @@ type Task struct {
 	// one TaskSet.  The platform merge namespaces store paths by component,
 	// extending the rule platform-wide.
 	Output FileOrDirectoryPath `json:"output,omitempty" yaml:"output,omitempty"`
+	// Timeout bounds the duration of one attempt of the task in Go
+	// time.ParseDuration syntax, for example "30s" or "10m".  An attempt
+	// exceeding the timeout fails.  Helm tasks default to "5m", bounding the
+	// chart pull.  Other kinds have no timeout by default.
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+	// Retry re-executes a failed task, including an attempt exceeding Timeout.
+	Retry Retry `json:"retry,omitempty" yaml:"retry,omitempty"`
+	// Order represents the order of the Kubernetes resources written by a
+	// Resources or Join task.  Resources tasks default to Kind, Join tasks
+	// default to Input.  Must not be set for other kinds.
+	Order Order `json:"order,omitempty" yaml:"order,omitempty" cue:"\"Kind\" | \"Label\" | \"Input\""`
 	// Resources task config.  Ignored unless kind is Resources.
 	Resources Resources `json:"resources,omitempty" yaml:"resources,omitempty"`
 	// Helm task config.  Ignored unless kind is Helm.
@@ -162,24 +182,85 @@ type Task struct {
 	Kustomize Kustomize `json:"kustomize,omitempty" yaml:"kustomize,omitempty"`
 	// Join task config.  Ignored unless kind is Join.
 	Join Join `json:"join,omitempty" yaml:"join,omitempty"`
+	// Filter task config.  Ignored unless kind is Filter.
+	Filter Filter `json:"filter,omitempty" yaml:"filter,omitempty"`
+	// Patch task config.  Ignored unless kind is Patch.
+	Patch Patch `json:"patch,omitempty" yaml:"patch,omitempty"`
+	// Transform task config.  Ignored unless kind is Transform.
+	Transform Transform `json:"transform,omitempty" yaml:"transform,omitempty"`
+	// Split task config.  Ignored unless kind is Split.
+	Split Split `json:"split,omitempty" yaml:"split,omitempty"`
+	// Jsonnet task config.  Ignored unless kind is Jsonnet.
+	Jsonnet Jsonnet `json:"jsonnet,omitempty" yaml:"jsonnet,omitempty"`
+	// CUE task config.  Ignored unless kind is CUE.
+	CUE CUE `json:"cue,omitempty" yaml:"cue,omitempty"`
 	// Command task config.  Ignored unless kind is Command.
 	Command Command `json:"command,omitempty" yaml:"command,omitempty"`
 	// Artifact task config.  Ignored unless kind is Artifact.
 	Artifact Artifact `json:"artifact,omitempty" yaml:"artifact,omitempty"`
 }
 
+// TaskMetadata represents data about a [Task].
+//
+// Labels select tasks at render time.  Given one or more --task-selector flags,
+// holos executes only the tasks selected by at least one selector, for example
+// to skip expensive validators locally or run only security scanners in a
+// dedicated CI job.  Holos additionally executes every task producing an input
+// of a selected task.  Artifact tasks not selected by a selector execute unless
+// every task they depend on is pruned.
+type TaskMetadata struct {
+	// Labels represents task labels matched by task selectors.
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+}
+
 // Dependency represents one explicit ordering edge declared in
 // [Task.DependsOn].  It is deliberately empty — the edge is the struct key —
 // so future fields (for example an optional edge) may be added without
 // breaking composition.
 type Dependency struct{}
 
+// Retry represents the retry policy of a [Task].  Useful for commands and chart
+// pulls subject to transient network errors.  The zero value executes the task
+// once.
+type Retry struct {
+	// Attempts represents the maximum number of attempts including the first.
+	// Defaults to 1, no retry.
+	Attempts int `json:"attempts,omitempty" yaml:"attempts,omitempty" cue:">=1"`
+	// Backoff represents the duration to wait before the second attempt in Go
+	// time.ParseDuration syntax.  The wait doubles before each subsequent
+	// attempt.  Defaults to "1s".
+	Backoff string `json:"backoff,omitempty" yaml:"backoff,omitempty"`
+}
+
+// Order represents the order of the Kubernetes resources written by a [Task].
+// Stable ordering keeps git diffs of the rendered manifests quiet.
+//
+//  1. Kind - Namespaces first, then CustomResourceDefinitions, then
+//     cluster-scoped resources, then all other resources.  Each group is
+//     sorted by kind, namespace, and name.  Cluster-scoped resources are
+//     recognized heuristically by a table of built-in Kubernetes kinds and by
+//     the Cluster kind prefix.  Valid for [Resources] and [Join] tasks, a Join
+//     task separating the sorted documents with its Separator.
+//  2. Label - Sorted by the kind and [InternalLabel] keys of [Resources].
+//     Valid for Resources tasks.
+//  3. Input - The [Join] inputs concatenated in declaration order.  Valid for
+//     Join tasks.
+type Order string
+
 // Command represents a [Task] implemented by executing a user-defined system
 // command.  Command is a first-class Task kind in v1beta1.  Commands execute
-// with the working directory set to the platform root.
+// with the working directory set to the platform root unless WorkDir is set.
 //
 // A command with an output generates or transforms; a command with only inputs
 // validates, gating downstream tasks through [Task.DependsOn] edges.
+//
+// Holos materializes each declared input in the build temp directory and
+// exports its absolute path to the command as HOLOS_INPUT_<n>, where n is the
+// zero based index of the input, for example HOLOS_INPUT_0.  If the task
+// declares an output, HOLOS_OUTPUT holds the absolute path the command writes
+// the output to, unless IsStdoutOutput is true.  Holos creates the parent
+// directory of HOLOS_OUTPUT.  Use these variables instead of paths built from
+// [BuildContext].
 type Command struct {
 	// DisplayName of the command.  The basename of args[0] is used if empty.
 	DisplayName string `json:"displayName,omitempty" yaml:"displayName,omitempty"`
@@ -191,6 +272,18 @@ type Command struct {
 	Stdin FileOrDirectoryPath `json:"stdin,omitempty" yaml:"stdin,omitempty"`
 	// IsStdoutOutput captures the command stdout as the task Output if true.
 	IsStdoutOutput bool `json:"isStdoutOutput,omitempty" yaml:"isStdoutOutput,omitempty"`
+	// Env represents environment variables of the command, keyed by name.  Env
+	// takes precedence over the inherited environment.  The HOLOS_INPUT_<n> and
+	// HOLOS_OUTPUT variables take precedence over Env.
+	Env map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+	// ClearEnv executes the command without the environment inherited from
+	// holos if true, so the command receives only Env and the HOLOS_INPUT_<n>
+	// and HOLOS_OUTPUT variables.  Useful for reproducible output.  Holos still
+	// resolves args[0] using its own PATH.
+	ClearEnv bool `json:"clearEnv,omitempty" yaml:"clearEnv,omitempty"`
+	// WorkDir represents the working directory of the command relative to the
+	// platform root.  Defaults to the platform root.
+	WorkDir FilePath `json:"workDir,omitempty" yaml:"workDir,omitempty"`
 }
 
 // Artifact represents the sink [Task] kind.  It writes its single input from
@@ -222,7 +315,8 @@ type File struct {
 	Source FilePath `json:"source" yaml:"source"`
 }
 
-// Helm represents a [Task] that renders a helm [Chart].
+// Helm represents a [Task] that renders a helm [Chart] in-process with the helm
+// sdk, equivalent to helm template.  No helm executable is required.
 type Helm struct {
 	// Chart represents a helm chart to manage.
 	Chart Chart `json:"chart" yaml:"chart"`
@@ -281,6 +375,9 @@ type Repository struct {
 }
 
 // Auth represents environment variable names containing auth credentials.
+// Holos redacts the resolved username and password from logs and error
+// messages, except values shorter than 4 characters which would mask common
+// text.
 type Auth struct {
 	Username AuthSource `json:"username" yaml:"username"`
 	Password AuthSource `json:"password" yaml:"password"`
@@ -302,13 +399,259 @@ type Join struct {
 	Separator string `json:"separator,omitempty" yaml:"separator,omitempty"`
 }
 
+// Filter represents a [Task] keeping or dropping the Kubernetes resources of
+// its inputs in-process.  Useful to drop the test Pods of a [Helm] chart
+// without a [Kustomize] or [Command] task.
+//
+// Filter concatenates the YAML documents of its inputs in declaration order,
+// then keeps each resource matching any Include selector, or every resource if
+// Include is empty, unless the resource matches any Exclude selector.  Kept
+// documents are copied verbatim, preserving comments and formatting.
+type Filter struct {
+	// Include selects the resources to keep.  Keeps every resource if empty.
+	Include []ResourceSelector `json:"include,omitempty" yaml:"include,omitempty"`
+	// Exclude selects the resources to drop.  Takes precedence over Include.
+	Exclude []ResourceSelector `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+}
+
+// ResourceSelector selects Kubernetes resources for a [Filter] task.  A
+// resource matches when every non-empty field matches.
+type ResourceSelector struct {
+	// APIVersion matches the resource apiVersion exactly, for example "apps/v1".
+	APIVersion string `json:"apiVersion,omitempty" yaml:"apiVersion,omitempty"`
+	// Kind matches the resource kind exactly, for example "Pod".
+	Kind string `json:"kind,omitempty" yaml:"kind,omitempty"`
+	// Name matches the resource metadata.name with [path.Match] glob syntax,
+	// for example "*-test-*".
+	//
+	// [path.Match]: https://pkg.go.dev/path#Match
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Namespace matches the resource metadata.namespace with [path.Match] glob
+	// syntax.  Cluster-scoped resources have an empty namespace.
+	//
+	// [path.Match]: https://pkg.go.dev/path#Match
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	// LabelSelector matches the resource metadata.labels using the syntax of
+	// the holos --selector flag, for example "app=web,tier!=test".
+	LabelSelector string `json:"labelSelector,omitempty" yaml:"labelSelector,omitempty"`
+}
+
+// Patch represents a [Task] patching the Kubernetes resources of its inputs
+// in-process.  Useful to patch a [Helm] chart without a [Kustomize] task.
+//
+// Patch concatenates the YAML documents of its inputs in declaration order,
+// then applies each [ResourcePatch] in order to every resource it targets.
+// Unpatched documents are copied verbatim.  It is an error for a patch to
+// target no resources.
+type Patch struct {
+	// Patches represents the patches to apply in order.
+	Patches []ResourcePatch `json:"patches" yaml:"patches"`
+}
+
+// ResourcePatch represents one patch of a [Patch] task.  Type discriminates
+// the patch format:
+//
+//  1. JSON6902 - Apply the RFC 6902 JSON patch Operations.
+//  2. Merge - Apply the RFC 7386 JSON merge patch Merge.  A null value
+//     removes the field.
+//  3. StrategicMerge - Apply the Kubernetes strategic merge patch Merge.
+//     Lists of built-in kinds merge by their patch merge key, for example the
+//     name of a container.  Kinds holos does not know, for example custom
+//     resources, fall back to a JSON merge patch.
+type ResourcePatch struct {
+	// Type represents the patch format.
+	Type string `json:"type" yaml:"type" cue:"\"JSON6902\" | \"Merge\" | \"StrategicMerge\""`
+	// Target selects the resources to patch.  Targets every resource if empty.
+	Target ResourceSelector `json:"target,omitempty" yaml:"target,omitempty"`
+	// Operations represents the JSON patch operations.  Ignored unless type is
+	// JSON6902.
+	Operations []PatchOperation `json:"operations,omitempty" yaml:"operations,omitempty"`
+	// Merge represents the partial resource merged into each target.  Ignored
+	// unless type is Merge or StrategicMerge.
+	Merge map[string]any `json:"merge,omitempty" yaml:"merge,omitempty"`
+}
+
+// PatchOperation represents one RFC 6902 JSON patch operation of a
+// [ResourcePatch].
+type PatchOperation struct {
+	// Op represents the operation.
+	Op string `json:"op" yaml:"op" cue:"\"add\" | \"remove\" | \"replace\" | \"move\" | \"copy\" | \"test\""`
+	// Path represents the JSON pointer to the target location, for example
+	// "/spec/replicas".
+	Path string `json:"path" yaml:"path"`
+	// From represents the JSON pointer to the source location of a move or
+	// copy operation.
+	From string `json:"from,omitempty" yaml:"from,omitempty"`
+	// Value represents the value of an add, replace, or test operation.
+	Value any `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// Transform represents a [Task] transforming the metadata of every Kubernetes
+// resource of its inputs in-process.  Useful to normalize [Helm] output
+// without a [Kustomize] task.
+//
+// Transform concatenates the YAML documents of its inputs in declaration
+// order, then applies the operations to each resource in field order:
+// RemoveFields first, so Labels may replace a removed label.  Untransformed
+// documents are copied verbatim.  Transform does not update references
+// between resources, for example a prefixed ConfigMap name referenced by a
+// Deployment.
+type Transform struct {
+	// RemoveFields represents RFC 6901 JSON pointers to fields to remove.  A
+	// "/" within a key is escaped as "~1", for example
+	// "/metadata/labels/helm.sh~1chart".  Missing fields are ignored.
+	RemoveFields []string `json:"removeFields,omitempty" yaml:"removeFields,omitempty"`
+	// Namespace sets the namespace of namespaced resources lacking one.
+	// Namespaces, CustomResourceDefinitions, and built-in cluster-scoped kinds
+	// are left unchanged, as are kinds prefixed with Cluster and kinds listed
+	// in ClusterScopedKinds.
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+	// ClusterScopedKinds represents additional cluster-scoped kinds Namespace
+	// leaves unchanged, for example cluster-scoped custom resources.  holos
+	// renders without access to a cluster, so the scope of a custom resource
+	// is not discovered.
+	ClusterScopedKinds []string `json:"clusterScopedKinds,omitempty" yaml:"clusterScopedKinds,omitempty"`
+	// Labels represents labels to add to every resource, replacing existing
+	// values.
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	// Annotations represents annotations to add to every resource, replacing
+	// existing values.
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+	// NamePrefix represents a prefix to add to the name of every resource
+	// except Namespaces and CustomResourceDefinitions.
+	NamePrefix string `json:"namePrefix,omitempty" yaml:"namePrefix,omitempty"`
+	// NameSuffix represents a suffix to add to the name of every resource
+	// except Namespaces and CustomResourceDefinitions.
+	NameSuffix string `json:"nameSuffix,omitempty" yaml:"nameSuffix,omitempty"`
+}
+
+// Split represents a [Task] splitting the Kubernetes resources of its inputs
+// in-process into one file per resource under the output directory.  Useful to
+// commit each resource to its own file, equivalent to holos kubectl-slice.
+//
+// Split reads the YAML documents of its inputs in declaration order and copies
+// each selected document verbatim to the file named by Template.  Documents
+// sharing a file name are joined into one file in input order.
+type Split struct {
+	// Template represents the file name of each resource relative to the
+	// output directory.  The placeholders {kind}, {name}, {namespace},
+	// {group}, and {version} are replaced with the lower case value of the
+	// resource field, for example "{namespace}/{kind}-{name}.yaml".  Empty path
+	// segments are dropped, so cluster-scoped resources of the example are
+	// written to the output directory itself.  Defaults to "{kind}-{name}.yaml".
+	Template string `json:"template,omitempty" yaml:"template,omitempty"`
+	// Include selects the resources to split.  Splits every resource if empty.
+	Include []ResourceSelector `json:"include,omitempty" yaml:"include,omitempty"`
+	// Exclude selects the resources to drop.  Takes precedence over Include.
+	Exclude []ResourceSelector `json:"exclude,omitempty" yaml:"exclude,omitempty"`
+}
+
+// Jsonnet represents a [Task] evaluating a Jsonnet file from the component
+// directory in-process with go-jsonnet.  Useful to render upstream projects
+// shipping only Jsonnet, for example kube-prometheus, without a jsonnet
+// executable.
+//
+// The evaluated value is flattened into a YAML stream of Kubernetes resources.
+// An object with both apiVersion and kind is one resource.  Arrays are
+// flattened in order and other objects are flattened by sorted key, so both a
+// list of resources and the kube-prometheus object of resources are supported.
+// Null values are dropped.
+type Jsonnet struct {
+	// Source represents the Jsonnet file sub-path relative to the component
+	// path.
+	Source FilePath `json:"source" yaml:"source"`
+	// ExtVars represents external variables available to std.extVar, keyed by
+	// name.  Each value is passed as Jsonnet code encoded as JSON, equivalent to
+	// the jsonnet --ext-code flag, so strings and structured values defined in
+	// CUE both work.
+	ExtVars map[string]any `json:"extVars,omitempty" yaml:"extVars,omitempty"`
+	// TLAs represents top-level arguments of a top-level function, keyed by
+	// parameter name.  Each value is passed as Jsonnet code encoded as JSON,
+	// equivalent to the jsonnet --tla-code flag.
+	TLAs map[string]any `json:"tlas,omitempty" yaml:"tlas,omitempty"`
+	// JPath represents library search paths relative to the component path,
+	// equivalent to the jsonnet --jpath flag, for example "vendor".
+	JPath []FilePath `json:"jpath,omitempty" yaml:"jpath,omitempty"`
+}
+
+// CUE represents a [Task] unifying the Kubernetes resources of its inputs with
+// a CUE package of the platform module in-process.  Useful to validate
+// rendered manifests against a policy, or to transform them, without a
+// [Command] task executing holos cue vet once per artifact.
+//
+// Holos loads the input resources into the resources field of the package
+// keyed by input path, GVK, and namespaced name, for example
+// resources: "chart.yaml": "apps/v1/Deployment": "web/web": {...}.  The GVK is
+// the apiVersion, a "/", and the kind.  The namespaced name is the namespace,
+// a "/", and the name, or the bare name when the namespace is empty.  Two
+// resources with the same key are an error.  Holos then validates the package
+// value, failing the task with the CUE error positions of any conflict.
+//
+// A CUE task without an output is a validator, gating downstream tasks through
+// [Task.DependsOn] edges.  A CUE task with an output exports Expression.
+type CUE struct {
+	// Package represents the CUE package directory relative to the platform
+	// root, for example "policy/secrets".
+	Package FilePath `json:"package" yaml:"package"`
+	// Expression represents a CUE expression evaluated in the scope of the
+	// package value, equivalent to the cue export --expression flag.  A list
+	// exports one YAML document per element, any other value one document.
+	// Required if the task declares an output, otherwise must be empty.
+	Expression string `json:"expression,omitempty" yaml:"expression,omitempty"`
+}
+
 // Kustomize represents a kustomization [Task] to patch and transform prior
-// task outputs.
+// task outputs.  Holos builds the kustomization in-process with the kustomize
+// API against an in-memory filesystem holding the kustomization, Files, and
+// the task inputs, so kubectl is not required.
+//
+// The in-memory filesystem has no access to the local disk.  Helm charts and
+// alpha plugins run outside of holos and need files on disk, so holos builds
+// on disk in a temporary directory if EnableHelm or EnableAlphaPlugins is set.
+// Holos also builds on disk if LoadRestrictor is LoadRestrictionsNone, so the
+// kustomization may load files from the local disk outside of its root.  The
+// root is then a temporary directory within the component directory, so a
+// relative reference such as ../base resolves against the component
+// directory.
 type Kustomize struct {
 	// Kustomization represents the decoded kustomization.yaml file
 	Kustomization Kustomization `json:"kustomization" yaml:"kustomization"`
 	// Files holds file contents for kustomize, e.g. patch files.
 	Files FileContentMap `json:"files,omitempty" yaml:"files,omitempty"`
+	// LoadRestrictor represents the kustomize build --load-restrictor flag.
+	// Defaults to LoadRestrictionsRootOnly.  LoadRestrictionsNone builds on
+	// disk within the component directory.
+	LoadRestrictor string `json:"loadRestrictor,omitempty" yaml:"loadRestrictor,omitempty" cue:"\"LoadRestrictionsRootOnly\" | \"LoadRestrictionsNone\""`
+	// EnableHelm represents the kustomize build --enable-helm flag, enabling
+	// the helmCharts field of the kustomization.
+	EnableHelm bool `json:"enableHelm,omitempty" yaml:"enableHelm,omitempty"`
+	// HelmCommand represents the kustomize build --helm-command flag.
+	// Defaults to helm.
+	HelmCommand string `json:"helmCommand,omitempty" yaml:"helmCommand,omitempty"`
+	// EnableAlphaPlugins represents the kustomize build --enable-alpha-plugins
+	// flag.
+	EnableAlphaPlugins bool `json:"enableAlphaPlugins,omitempty" yaml:"enableAlphaPlugins,omitempty"`
+	// EnableExec represents the kustomize build --enable-exec flag, enabling
+	// exec KRM functions.  Requires EnableAlphaPlugins.
+	EnableExec bool `json:"enableExec,omitempty" yaml:"enableExec,omitempty"`
+	// Network represents the kustomize build --network flag, enabling network
+	// access for container KRM functions.  Requires EnableAlphaPlugins.
+	Network bool `json:"network,omitempty" yaml:"network,omitempty"`
+	// NetworkName represents the kustomize build --network-name flag.
+	// Defaults to bridge.  Requires Network.
+	NetworkName string `json:"networkName,omitempty" yaml:"networkName,omitempty"`
+	// Mounts represents the kustomize build --mount flags mounting storage
+	// into container KRM functions, for example
+	// "type=bind,src=/tmp/data,dst=/data".  Requires EnableAlphaPlugins.
+	Mounts []string `json:"mounts,omitempty" yaml:"mounts,omitempty"`
+	// Env represents the kustomize build --env flags passing environment
+	// variables to KRM functions, for example "KEY=value" or "KEY" to pass the
+	// value of the holos process.  Requires EnableAlphaPlugins.
+	Env []string `json:"env,omitempty" yaml:"env,omitempty"`
+	// AsCurrentUser represents the kustomize build --as-current-user flag
+	// running container KRM functions as the current user.  Requires
+	// EnableAlphaPlugins.
+	AsCurrentUser bool `json:"asCurrentUser,omitempty" yaml:"asCurrentUser,omitempty"`
 }
 
 // Kustomization represents a kustomization.yaml file for use with the
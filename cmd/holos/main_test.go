@@ -48,6 +48,7 @@ func params(dir string) testscript.Params {
 		Setup: func(env *testscript.Env) error {
 			// Just like cmd/cue/cmd.TestScript, set up separate cache and config dirs per test.
 			env.Setenv("CUE_CACHE_DIR", filepath.Join(env.WorkDir, "tmp/cachedir"))
+			env.Setenv("HOLOS_CACHE_DIR", filepath.Join(env.WorkDir, "tmp/holos-cache"))
 			configDir := filepath.Join(env.WorkDir, "tmp/configdir")
 			env.Setenv("CUE_CONFIG_DIR", configDir)
 			return nil
@@ -32,6 +32,8 @@ type Store interface {
 	Save(dir, path string) error
 	// Load a file or directory from the filesystem into the store.
 	Load(dir, path string) error
+	// Keys returns every path set in the store in no particular order.
+	Keys() []string
 }
 
 func NewStore() *MapStore {
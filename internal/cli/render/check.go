@@ -0,0 +1,46 @@
+package render
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/holos-run/holos/internal/component/v1beta1"
+	"github.com/holos-run/holos/internal/errors"
+	"github.com/holos-run/holos/internal/stage"
+)
+
+// checkDrift compares the staged render with the writeTo directory instead of
+// promoting it.  The files the render would add, change, or remove, including
+// stale files it would prune and the render manifest itself, are written to
+// stdout.  Any difference is an error.
+func (r *renderPlatform) checkDrift(s *stage.Stage, artifacts []string, writeTo string, manifest *v1beta1.Manifest, stale []string) error {
+	changes, err := s.Diff(artifacts)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	for _, file := range stale {
+		if _, err := os.Lstat(filepath.Join(writeTo, filepath.FromSlash(file))); err == nil {
+			changes.Removed = append(changes.Removed, file)
+		}
+	}
+	slices.Sort(changes.Removed)
+	changes.Removed = slices.Compact(changes.Removed)
+
+	want, err := manifest.Marshal()
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	have, err := os.ReadFile(filepath.Join(writeTo, v1beta1.ManifestName))
+	switch {
+	case err == nil && bytes.Equal(have, want):
+	case err == nil:
+		changes.Changed = append(changes.Changed, v1beta1.ManifestName)
+	default:
+		changes.Added = append(changes.Added, v1beta1.ManifestName)
+	}
+
+	return errors.Wrap(changes.Check(r.pcfg.Stdout, r.pcfg.WriteTo))
+}
@@ -0,0 +1,412 @@
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/holos-run/holos/internal/compare"
+	"github.com/holos-run/holos/internal/component/v1beta1"
+	"github.com/holos-run/holos/internal/errors"
+	"github.com/holos-run/holos/internal/holos"
+	"github.com/holos-run/holos/internal/platform"
+	"github.com/holos-run/holos/internal/stage"
+	"github.com/holos-run/holos/internal/util"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// againstDeploy represents the --against value comparing the render with the
+// write-to directory.
+const againstDeploy = "deploy"
+
+// NewDiffCommand returns the holos diff command comparing the objects of a
+// platform render with the write-to directory or a git ref.
+func NewDiffCommand(cfg *holos.Config) *cobra.Command {
+	pcfg := platform.NewConfig()
+	d := &diffPlatform{
+		r:       &renderPlatform{cfg: cfg, pcfg: pcfg, cacheDir: holos.DefaultCacheDir(), prune: true, private: true},
+		against: againstDeploy,
+		format:  "text",
+	}
+	cmd := platform.NewCommand(pcfg, d.Run)
+	cmd.Use = "diff"
+	cmd.Short = "compare rendered objects with the write-to directory or a git ref"
+	cmd.Long = `Render the platform without writing to the write-to directory, then compare
+the Kubernetes objects of the render with the objects in the write-to
+directory or in the write-to directory of a git ref.  Objects are matched by
+apiVersion, kind, namespace, and name regardless of the file containing them.
+
+The render is written to a private directory in the system temporary
+directory, not held in memory, because components earlier than v1beta1 render
+in sub processes writing files.  The directory is removed when the command
+exits.
+
+The values of Secret data and stringData are masked unless --show-secrets is
+set.  Files other than .yaml, .yml, and .json files are not compared.`
+	cmd.Flags().AddFlagSet(pcfg.FlagSet())
+	cmd.Flags().AddFlagSet(d.flagSet())
+	return cmd
+}
+
+// diffPlatform implements the holos diff command.
+type diffPlatform struct {
+	r *renderPlatform
+	// against represents the previous render, the write-to directory if
+	// "deploy", otherwise a git ref.
+	against string
+	// format represents the report format, text, markdown, or json.
+	format string
+	// ignore represents the rules excluding volatile fields from the
+	// comparison.
+	ignore []string
+	// showSecrets shows the values of Secret data in the report instead of
+	// masking them.
+	showSecrets bool
+}
+
+func (d *diffPlatform) flagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+	fs.StringVar(&d.against, "against", d.against, "compare with the write-to directory (deploy) or the write-to directory of a git ref")
+	fs.StringVar(&d.format, "format", d.format, "text, markdown, or json format")
+	fs.StringArrayVar(&d.ignore, "ignore", d.ignore, "ignore a volatile field of the form [kind:]path, for example metadata.annotations[checksum/config] (repeatable)")
+	fs.BoolVar(&d.showSecrets, "show-secrets", d.showSecrets, "show the values of Secret data and stringData instead of masking them")
+	fs.StringVar(&d.r.cacheDir, "cache-dir", d.r.cacheDir, fmt.Sprintf("task result cache directory, empty disables the cache (%s)", holos.CacheDirEnvVar))
+	return fs
+}
+
+// Run renders the selected platform components into a private stage, compares
+// the staged objects with the previous render, then removes the stage.  The
+// previous render includes the files promoting the stage would change or
+// remove, including the stale files a full render prunes.
+//
+// The render is deliberately on disk rather than in memory: legacy components
+// render as opaque sub processes writing files, so only a directory captures
+// every component alike.
+func (d *diffPlatform) Run(ctx context.Context, p *platform.Platform) error {
+	switch d.format {
+	case "text", "markdown", "json":
+	default:
+		return errors.Format("invalid format %s: must be text, markdown, or json", d.format)
+	}
+	ignore := make([]compare.Ignore, 0, len(d.ignore))
+	for _, rule := range d.ignore {
+		i, err := compare.ParseIgnore(rule)
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		ignore = append(ignore, i)
+	}
+
+	var src source
+	var err error
+	if d.against == againstDeploy {
+		src, err = newDeploySource(filepath.Join(p.Root(), d.r.pcfg.WriteTo))
+	} else {
+		src, err = newGitSource(ctx, d.r.pcfg.Stderr, p.Root(), d.r.pcfg.WriteTo, d.against)
+	}
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	s, graph, cleanup, err := d.r.build(ctx, p)
+	defer cleanup()
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	files, err := s.Files()
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	prev := v1beta1.NewManifest()
+	if data, err := src.read(ctx, []string{v1beta1.ManifestName}); err != nil {
+		return errors.Wrap(err)
+	} else if manifest, ok := data[v1beta1.ManifestName]; ok {
+		if prev, err = v1beta1.ParseManifest(path.Join(d.against, v1beta1.ManifestName), manifest); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+	_, stale := d.r.manifest(prev, files)
+
+	// The previous render is every existing file the promotion would change,
+	// replace, or prune.
+	artifacts := graph.Artifacts()
+	var previous []string
+	for _, file := range src.files() {
+		_, rendered := slices.BinarySearch(files, file)
+		if rendered || slices.Contains(stale, file) || underAny(file, artifacts) {
+			previous = append(previous, file)
+		}
+	}
+
+	after, err := parseObjects(files, func(files []string) (map[string][]byte, error) {
+		return readFiles(s.Dir(), files)
+	})
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	before, err := parseObjects(previous, func(files []string) (map[string][]byte, error) {
+		return src.read(ctx, files)
+	})
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	if !d.showSecrets {
+		compare.MaskSecrets(before, after)
+	}
+	diffs, err := compare.DiffObjects(before, after, ignore, d.against, "render")
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	// Attribute each object to the v1beta1 component owning the artifact
+	// containing it, otherwise to the directory of the file.
+	owners := make(map[string]string)
+	for idx, c := range p.Select(d.r.pcfg.ComponentSelectors...) {
+		if ts := graph.Components[idx].TaskSet; ts != nil {
+			for _, artifact := range ts.Artifacts() {
+				owners[artifact] = c.Describe()
+			}
+		}
+	}
+	for idx := range diffs {
+		diffs[idx].Component = owner(owners, diffs[idx].File)
+	}
+
+	report := compare.NewReport(d.against, diffs)
+	switch d.format {
+	case "markdown":
+		return errors.Wrap(report.WriteMarkdown(d.r.pcfg.Stdout))
+	case "json":
+		encoder := json.NewEncoder(d.r.pcfg.Stdout)
+		encoder.SetIndent("", "  ")
+		return errors.Wrap(encoder.Encode(report))
+	default:
+		return errors.Wrap(report.WriteText(d.r.pcfg.Stdout))
+	}
+}
+
+// parseObjects reads the manifest files with read and returns their objects.
+func parseObjects(files []string, read func([]string) (map[string][]byte, error)) ([]compare.Object, error) {
+	files = slices.DeleteFunc(slices.Clone(files), func(file string) bool {
+		switch path.Ext(file) {
+		case ".yaml", ".yml", ".json":
+			return file == v1beta1.ManifestName
+		default:
+			return true
+		}
+	})
+	data, err := read(files)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	var objects []compare.Object
+	for _, file := range files {
+		objs, err := compare.ParseObjects(file, data[file])
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		objects = append(objects, objs...)
+	}
+	return objects, nil
+}
+
+// owner returns the owner of the longest artifact path containing file, or
+// the directory of file if no artifact contains it.
+func owner(owners map[string]string, file string) string {
+	var found string
+	for artifact := range owners {
+		if under(file, artifact) && len(artifact) > len(found) {
+			found = artifact
+		}
+	}
+	if found == "" {
+		return path.Dir(file)
+	}
+	return owners[found]
+}
+
+// under returns true if the slash separated file is dir or is within dir.
+func under(file, dir string) bool {
+	return file == dir || strings.HasPrefix(file, dir+"/")
+}
+
+func underAny(file string, dirs []string) bool {
+	return slices.ContainsFunc(dirs, func(dir string) bool { return under(file, dir) })
+}
+
+// source represents a previous render.
+type source interface {
+	// files returns the sorted, slash separated paths of the existing files
+	// relative to the write-to directory.
+	files() []string
+	// read returns the content of the existing files keyed by path.  Missing
+	// files are omitted.
+	read(ctx context.Context, files []string) (map[string][]byte, error)
+}
+
+// deploySource represents the write-to directory.
+type deploySource struct {
+	dir      string
+	existing []string
+}
+
+func newDeploySource(dir string) (*deploySource, error) {
+	src := &deploySource{dir: dir}
+	err := filepath.WalkDir(dir, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name != dir && stage.IsStage(d.Name()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+		src.existing = append(src.existing, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, errors.Format("could not list %s: %w", dir, err)
+	}
+	slices.Sort(src.existing)
+	return src, nil
+}
+
+func (s *deploySource) files() []string {
+	return s.existing
+}
+
+func (s *deploySource) read(_ context.Context, files []string) (map[string][]byte, error) {
+	return readFiles(s.dir, files)
+}
+
+// readFiles reads the slash separated files relative to dir.  Missing files
+// are omitted.
+func readFiles(dir string, files []string) (map[string][]byte, error) {
+	data := make(map[string][]byte, len(files))
+	for _, file := range files {
+		content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file)))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, errors.Wrap(err)
+		}
+		data[file] = content
+	}
+	return data, nil
+}
+
+// gitSource represents the write-to directory of a git ref.  Files are read
+// from the git object database without checking out the ref.
+type gitSource struct {
+	root   string
+	stderr io.Writer
+	// blobs maps each file relative to the write-to directory to its blob id.
+	blobs map[string]string
+}
+
+func newGitSource(ctx context.Context, stderr io.Writer, root, writeTo, ref string) (*gitSource, error) {
+	if strings.HasPrefix(ref, "-") {
+		return nil, errors.Format("invalid git ref %s", ref)
+	}
+	result, err := util.RunCmdW(ctx, stderr, "git", "-C", root, "rev-parse", "--verify", ref+"^{commit}")
+	if err != nil {
+		return nil, errors.Format("could not resolve git ref %s: %w", ref, err)
+	}
+	commit := strings.TrimSpace(result.Stdout.String())
+
+	// ls-tree lists paths relative to the platform root.
+	dir := filepath.ToSlash(filepath.Clean(writeTo))
+	result, err = util.RunCmdW(ctx, stderr, "git", "-C", root, "ls-tree", "-r", "-z", commit, "--", dir+"/")
+	if err != nil {
+		return nil, errors.Format("could not list %s at git ref %s: %w", dir, ref, err)
+	}
+	src := &gitSource{root: root, stderr: stderr, blobs: make(map[string]string)}
+	for _, entry := range strings.Split(result.Stdout.String(), "\x00") {
+		// <mode> SP <type> SP <object> TAB <file>
+		meta, file, ok := strings.Cut(entry, "\t")
+		fields := strings.Fields(meta)
+		if !ok || len(fields) != 3 || fields[1] != "blob" {
+			continue
+		}
+		if rel := strings.TrimPrefix(file, dir+"/"); rel != file {
+			src.blobs[rel] = fields[2]
+		}
+	}
+	return src, nil
+}
+
+func (s *gitSource) files() []string {
+	files := make([]string, 0, len(s.blobs))
+	for file := range s.blobs {
+		files = append(files, file)
+	}
+	slices.Sort(files)
+	return files
+}
+
+// read reads the blobs of files with one git cat-file --batch process.
+func (s *gitSource) read(ctx context.Context, files []string) (map[string][]byte, error) {
+	var stdin bytes.Buffer
+	var found []string
+	for _, file := range files {
+		if blob, ok := s.blobs[file]; ok {
+			fmt.Fprintln(&stdin, blob)
+			found = append(found, file)
+		}
+	}
+	data := make(map[string][]byte, len(found))
+	if len(found) == 0 {
+		return data, nil
+	}
+
+	result, err := util.RunCmdFunc(ctx, s.stderr, "git", []string{"-C", s.root, "cat-file", "--batch"}, func(cmd *exec.Cmd) error {
+		cmd.Stdin = &stdin
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Format("could not read git objects: %w", err)
+	}
+
+	// Each object is a header line, <object> SP <type> SP <size>, followed by
+	// the content and a newline.
+	r := bufio.NewReader(result.Stdout)
+	for _, file := range found {
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return nil, errors.Format("could not read %s: %w", file, err)
+		}
+		var id, kind string
+		var size int
+		if _, err := fmt.Sscanf(header, "%s %s %d", &id, &kind, &size); err != nil {
+			return nil, errors.Format("could not read %s: unexpected header %q", file, strings.TrimSpace(header))
+		}
+		content := make([]byte, size+1)
+		if _, err := io.ReadFull(r, content); err != nil {
+			return nil, errors.Format("could not read %s: %w", file, err)
+		}
+		data[file] = content[:size]
+	}
+	return data, nil
+}
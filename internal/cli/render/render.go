@@ -2,14 +2,25 @@ package render
 
 import (
 	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"cuelang.org/go/cue/cuecontext"
 	"github.com/holos-run/holos/internal/cli/command"
+	"github.com/holos-run/holos/internal/compile"
 	"github.com/holos-run/holos/internal/component"
+	"github.com/holos-run/holos/internal/component/v1beta1"
 	"github.com/holos-run/holos/internal/errors"
 	"github.com/holos-run/holos/internal/holos"
+	"github.com/holos-run/holos/internal/logger"
 	"github.com/holos-run/holos/internal/platform"
+	"github.com/holos-run/holos/internal/stage"
+	"github.com/holos-run/holos/internal/trace"
 	"github.com/holos-run/holos/internal/util"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 func New(cfg *holos.Config) *cobra.Command {
@@ -22,10 +33,24 @@ func New(cfg *holos.Config) *cobra.Command {
 }
 
 func NewRenderPlatformCommand(cfg *holos.Config, pcfg *platform.Config) (cmd *cobra.Command) {
-	rp := &renderPlatform{cfg: cfg, pcfg: pcfg}
+	rp := &renderPlatform{cfg: cfg, pcfg: pcfg, cacheDir: holos.DefaultCacheDir()}
 	cmd = platform.NewCommand(pcfg, rp.Run)
 	cmd.Short = "render an entire platform"
 	cmd.Flags().AddFlagSet(pcfg.FlagSet())
+	cmd.Flags().AddFlagSet(rp.flagSet())
+	cmd.Flags().AddFlagSet(rp.trace.FlagSet())
+	cmd.MarkFlagsMutuallyExclusive("check", "plan")
+	cmd.MarkFlagsMutuallyExclusive("check", "watch")
+
+	// Trace the whole command, including the platform cue instance build.
+	runE := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		root := cmd.Root()
+		return rp.trace.Run(root.Context(), pcfg.Stderr, func(ctx context.Context) error {
+			root.SetContext(ctx)
+			return runE(cmd, args)
+		})
+	}
 	return cmd
 }
 
@@ -33,66 +58,361 @@ func NewRenderPlatformCommand(cfg *holos.Config, pcfg *platform.Config) (cmd *co
 type renderPlatform struct {
 	cfg  *holos.Config
 	pcfg *platform.Config
+	// cacheDir represents the task result cache directory.
+	cacheDir string
+	// plan prints the derived platform task graph without executing it.
+	plan bool
+	// keepGoing executes every task not depending on a failed task instead of
+	// failing fast.
+	keepGoing bool
+	// taskSelectors select the v1beta1 tasks to execute by label.
+	taskSelectors holos.Selectors
+	// trace records render spans to a trace file.
+	trace trace.Config
+	// watch re-renders the components affected by file changes until
+	// interrupted.
+	watch bool
+	// watchInterval represents the interval between scans of the platform
+	// root in watch mode.
+	watchInterval time.Duration
+	// changed holds the paths of the components to re-render in watch mode.
+	// Every component renders if nil.
+	changed map[string]bool
+	// compiled caches the compiled TaskSet of each v1beta1 component in watch
+	// mode, keyed by path and tags, to skip compiling unchanged components.
+	compiled map[string][]byte
+	// check compares the render with the write-to directory instead of
+	// writing to it.
+	check bool
+	// prune deletes the files recorded by the previous full render and not
+	// rendered again.
+	prune bool
+	// private renders into a private stage outside the write-to directory,
+	// for a render never promoted.
+	private bool
+	// tempDirs holds the build temp directory of each v1beta1 component in
+	// watch mode, keyed like compiled.  A cached TaskSet refers to its temp
+	// directory, so the directory is emptied and reused by each cycle.
+	tempDirs map[string]string
 }
 
-// Run executes the holos render component command concurrently for each
-// platform component.  The overall approach is to marshal the component into
-// cue tags, pass the log level and format, then execute the command as a sub
-// process.
-//
-// Note that the marshalling of component fields through the argument vector has
-// been quite awkward to maintain.  Consider refactoring to an approach of
-// marshaling the entire component structure via stdin to a sub process that
-// returns a build plan on stdout.  The purpose of using a sub process is to
-// execute cue concurrently.  Cue is not safe for concurrent use within the same
-// process.
+func (r *renderPlatform) flagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+	fs.BoolVar(&r.plan, "plan", r.plan, "print the derived task graph without executing it")
+	fs.BoolVar(&r.keepGoing, "keep-going", r.keepGoing, "keep executing tasks not depending on a failed task, then report every failure")
+	fs.Var(&r.taskSelectors, "task-selector", holos.TaskSelectorHelp)
+	fs.StringVar(&r.cacheDir, "cache-dir", r.cacheDir, fmt.Sprintf("task result cache directory, empty disables the cache (%s)", holos.CacheDirEnvVar))
+	fs.BoolVar(&r.check, "check", r.check, "render to a temporary directory, print the files that differ from the write-to directory, and fail if any differ")
+	fs.BoolVar(&r.prune, "prune", true, fmt.Sprintf("delete files listed in %s by the previous full render and not rendered again", v1beta1.ManifestName))
+	fs.BoolVar(&r.watch, "watch", r.watch, "re-render the components affected by file changes until interrupted")
+	fs.DurationVar(&r.watchInterval, "watch-interval", defaultWatchInterval, "interval between scans of the platform root for changes with --watch")
+	return fs
+}
+
+// Run renders every selected platform component as one platform-wide DAG.
+// One scheduler bounded by --concurrency executes the merged graph.
 func (r *renderPlatform) Run(ctx context.Context, p *platform.Platform) error {
-	prefixArgs := []string{
-		"--log-level", r.cfg.LogConfig().Level(),
-		"--log-format", r.cfg.LogConfig().Format(),
-	}
-
-	opts := platform.BuildOpts{
-		PerComponentFunc: func(ctx context.Context, i int, c holos.Component) error {
-			select {
-			case <-ctx.Done():
-				return errors.Wrap(ctx.Err())
-			default:
-				args := make([]string, 0, 100)
-				args = append(args, prefixArgs...)
-				args = append(args, "render", "component")
-				// Add the write-to flag
-				args = append(args, "--write-to", r.pcfg.WriteTo)
-				// holos render platform --inject tags
-				for _, tag := range r.pcfg.TagMap.Tags() {
-					args = append(args, "--inject", tag)
-				}
-				// component tags (name, labels, annotations)
-				if tags, err := c.Tags(); err != nil {
-					return errors.Wrap(err)
-				} else {
-					for _, tag := range tags {
-						args = append(args, "--inject", tag)
-					}
-				}
-				// component path
-				args = append(args, c.Path())
-
-				// Get current executable path.
-				holosPath, err := util.Executable()
-				if err != nil {
-					return errors.Wrap(err)
-				}
-
-				// Run holos render component ...
-				if _, err := util.RunCmdA(ctx, r.pcfg.Stderr, holosPath, args...); err != nil {
-					return errors.Format("could not render component: %w", err)
-				}
+	if r.watch {
+		if r.plan {
+			return errors.Format("--watch and --plan are mutually exclusive")
+		}
+		return errors.Wrap(r.watchLoop(ctx, p))
+	}
+	return errors.Wrap(r.render(ctx, p))
+}
+
+// render renders the selected platform components once.  Components render
+// into a stage promoted into the write-to directory once every task succeeds,
+// so a failed render leaves the write-to directory untouched.
+func (r *renderPlatform) render(ctx context.Context, p *platform.Platform) error {
+	start := time.Now()
+	log := logger.FromContext(ctx)
+
+	// Print the derived graph without executing it.
+	if r.plan {
+		graph, cleanup, err := r.graph(ctx, p, r.pcfg.WriteTo)
+		defer cleanup()
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		plan, err := graph.Plan(ctx)
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		return errors.Wrap(plan.Write(r.pcfg.Stdout))
+	}
+
+	s, graph, cleanup, err := r.build(ctx, p)
+	defer cleanup()
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	files, err := s.Files()
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	writeTo := filepath.Join(p.Root(), r.pcfg.WriteTo)
+	prev, err := v1beta1.ReadManifest(writeTo)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	manifest, stale := r.manifest(prev, files)
+	if r.check {
+		return errors.Wrap(r.checkDrift(s, graph.Artifacts(), writeTo, manifest, stale))
+	}
+
+	changes, err := s.Promote(ctx, graph.Artifacts())
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	if err := v1beta1.Prune(ctx, writeTo, stale); err != nil {
+		return errors.Wrap(err)
+	}
+	if err := manifest.Write(writeTo); err != nil {
+		return errors.Wrap(err)
+	}
+
+	duration := time.Since(start)
+	msg := fmt.Sprintf("rendered platform in %s", duration)
+	log.InfoContext(ctx, msg, "duration", duration, "added", len(changes.Added), "changed", len(changes.Changed), "removed", len(changes.Removed))
+	return nil
+}
+
+// build renders the selected platform components into a new stage, a private
+// stage if r.private is set.  The caller must call cleanup to remove the stage
+// and the build temp directories, including when build returns an error.
+func (r *renderPlatform) build(ctx context.Context, p *platform.Platform) (*stage.Stage, *v1beta1.Platform, func(), error) {
+	var s *stage.Stage
+	var err error
+	if r.private {
+		s, err = stage.NewPrivate(p.Root())
+	} else {
+		s, err = stage.New(p.Root(), r.pcfg.WriteTo)
+	}
+	if err != nil {
+		return nil, nil, func() {}, errors.Wrap(err)
+	}
+
+	graph, cleanupGraph, err := r.graph(ctx, p, s.WriteTo())
+	cleanup := func() {
+		cleanupGraph()
+		s.Remove(ctx)
+	}
+	if err != nil {
+		return nil, nil, cleanup, errors.Wrap(err)
+	}
+	graph.KeepGoing = r.keepGoing
+	graph.Stderr = r.pcfg.Stderr
+	if err := graph.Build(ctx); err != nil {
+		return nil, nil, cleanup, errors.Wrap(err)
+	}
+	return s, graph, cleanup, nil
+}
+
+// manifest returns the render manifest recording the files of a successful
+// render and the stale files to prune given the previous manifest.  A full
+// render, selecting every component and every task, prunes the files recorded
+// by the previous render and not written again.  A partial render, or a render
+// with --prune=false, adds the files written to the previous manifest instead
+// so a later full render prunes them.
+func (r *renderPlatform) manifest(prev *v1beta1.Manifest, files []string) (next *v1beta1.Manifest, stale []string) {
+	next = v1beta1.NewManifest(files...)
+
+	full := len(r.pcfg.ComponentSelectors) == 0 && len(r.taskSelectors) == 0 && r.changed == nil
+	if full && r.prune {
+		return next, prev.Stale(next)
+	}
+	return prev.Merge(next), nil
+}
+
+// Plan returns the task graph of every selected platform component without
+// executing any task.  Used by holos show tasks.
+func Plan(ctx context.Context, pcfg *platform.Config, p *platform.Platform) (*v1beta1.Plan, error) {
+	r := &renderPlatform{pcfg: pcfg}
+	graph, cleanup, err := r.graph(ctx, p, pcfg.WriteTo)
+	defer cleanup()
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	plan, err := graph.Plan(ctx)
+	return plan, errors.Wrap(err)
+}
+
+// graph merges every selected platform component into one platform-wide
+// graph.  v1beta1 components are compiled to TaskSets by a pool of holos
+// compile subprocesses, then join the graph natively keyed by canonical task
+// id so a task in one component may depend on, or consume the output of, a
+// task in another.  Earlier component versions join the graph as one opaque
+// node executing the holos render component command as a sub process.  The
+// caller must call cleanup to remove the build temp directories, including
+// when graph returns an error.  Components write to the writeTo directory
+// relative to the platform root.
+//
+// The purpose of using sub processes is to execute cue concurrently.  Cue is
+// not safe for concurrent use within the same process.
+func (r *renderPlatform) graph(ctx context.Context, p *platform.Platform, writeTo string) (graph *v1beta1.Platform, cleanup func(), err error) {
+	log := logger.FromContext(ctx)
+	components := p.Select(r.pcfg.ComponentSelectors...)
+	total := len(components)
+
+	var tempDirs []string
+	cleanup = func() {
+		for _, tempDir := range tempDirs {
+			util.Remove(ctx, tempDir)
+		}
+	}
+
+	// Discriminate the api version of each component without cue.
+	graph = &v1beta1.Platform{
+		Components:    make([]v1beta1.PlatformComponent, total),
+		Concurrency:   r.pcfg.Concurrency,
+		TaskSelectors: r.taskSelectors,
+	}
+	reqs := make([]compile.BuildPlanRequest, 0, total)
+	reqIdx := make([]int, 0, total)
+	// raws holds the compiled TaskSet of each request, nil until compiled.
+	raws := make([][]byte, 0, total)
+	keys := make([]string, 0, total)
+	for idx, c := range components {
+		done := func(ctx context.Context, duration time.Duration) {
+			msg := fmt.Sprintf("rendered %s in %s", c.Describe(), duration)
+			log.With("num", idx+1, "total", total).InfoContext(ctx, msg, "duration", duration)
+		}
+		graph.Components[idx].Done = done
+		graph.Components[idx].Unchanged = r.changed != nil && !r.changed[filepath.ToSlash(filepath.Clean(c.Path()))]
+
+		tm, err := component.New(p.Root(), c.Path()).TypeMeta()
+		if err != nil {
+			return nil, cleanup, errors.Format("could not discriminate component type: %w", err)
+		}
+		tags, err := c.Tags()
+		if err != nil {
+			return nil, cleanup, errors.Wrap(err)
+		}
+
+		if tm.APIVersion != "v1beta1" {
+			graph.Components[idx].ID = c.Path() + ":" + c.Describe()
+			graph.Components[idx].Kind = tm.Kind
+			graph.Components[idx].Run = r.renderComponentFunc(c, tags, writeTo)
+			continue
+		}
+
+		// temp directory is an important part of the build context.
+		key := c.Path() + "\x00" + strings.Join(tags, "\x00")
+		tempDir, err := r.tempDir(key)
+		if err != nil {
+			return nil, cleanup, errors.Format("could not make temp dir: %w", err)
+		}
+		if r.tempDirs == nil {
+			tempDirs = append(tempDirs, tempDir)
+		}
+
+		// Reuse the TaskSet compiled by a previous watch cycle if the
+		// component has not changed.
+		var raw []byte
+		if graph.Components[idx].Unchanged {
+			raw = r.compiled[key]
+		}
+		raws = append(raws, raw)
+		keys = append(keys, key)
+		reqs = append(reqs, compile.BuildPlanRequest{
+			APIVersion: "v1alpha6",
+			Kind:       holos.BuildPlanRequest,
+			Root:       p.Root(),
+			Leaf:       c.Path(),
+			WriteTo:    writeTo,
+			TempDir:    tempDir,
+			Tags:       append(r.pcfg.TagMap.Tags(), tags...),
+		})
+		reqIdx = append(reqIdx, idx)
+	}
+
+	// Compile the v1beta1 TaskSets not cached concurrently.
+	pending := make([]compile.BuildPlanRequest, 0, len(reqs))
+	pendingIdx := make([]int, 0, len(reqs))
+	for i, raw := range raws {
+		if raw == nil {
+			pending = append(pending, reqs[i])
+			pendingIdx = append(pendingIdx, i)
+		}
+	}
+	if len(pending) > 0 {
+		resp, err := compile.Compile(ctx, r.pcfg.Concurrency, pending)
+		if err != nil {
+			// Recompile every pending TaskSet on the next watch cycle.
+			for _, i := range pendingIdx {
+				delete(r.compiled, keys[i])
+			}
+			return nil, cleanup, errors.Format("could not compile task sets: %w", err)
+		}
+		for j, res := range resp {
+			i := pendingIdx[j]
+			raws[i] = res.RawMessage
+			if r.compiled != nil {
+				r.compiled[keys[i]] = res.RawMessage
 			}
-			return nil
-		},
-		InfoEnabled: true,
+		}
 	}
+	if len(reqs) > 0 {
+		// Load each TaskSet through cue, the same code path holos render
+		// component uses, so values decode identically.
+		cueCtx := cuecontext.New()
+		for i, raw := range raws {
+			req := reqs[i]
+			opts := holos.NewBuildOpts(req.Root, req.Leaf, req.WriteTo, req.TempDir)
+			opts.Stderr = r.pcfg.Stderr
+			opts.Concurrency = r.pcfg.Concurrency
+			opts.CacheDir = r.cacheDir
+			ts := &v1beta1.TaskSet{Opts: opts}
+			_, span := trace.Start(ctx, "cue", "Load", "path", req.Leaf)
+			err := ts.Load(cueCtx.CompileBytes(raw))
+			span.End()
+			if err != nil {
+				return nil, cleanup, errors.Format("could not load task set %s: %w", req.Leaf, err)
+			}
+			graph.Components[reqIdx[i]].TaskSet = ts
+		}
+	}
+
+	return graph, cleanup, nil
+}
 
-	return errors.Wrap(p.Build(ctx, opts))
+// renderComponentFunc returns a function executing the holos render component
+// command as a sub process for a component earlier than v1beta1.  The overall
+// approach is to marshal the component into cue tags, pass the log level and
+// format, then execute the command.
+func (r *renderPlatform) renderComponentFunc(c holos.Component, tags []string, writeTo string) func(context.Context) error {
+	return func(ctx context.Context) error {
+		args := make([]string, 0, 100)
+		args = append(args,
+			"--log-level", r.cfg.LogConfig().Level(),
+			"--log-format", r.cfg.LogConfig().Format(),
+		)
+		args = append(args, "render", "component")
+		// Add the write-to flag
+		args = append(args, "--write-to", writeTo)
+		// holos render platform --inject tags
+		for _, tag := range r.pcfg.TagMap.Tags() {
+			args = append(args, "--inject", tag)
+		}
+		// component tags (name, labels, annotations)
+		for _, tag := range tags {
+			args = append(args, "--inject", tag)
+		}
+		// component path
+		args = append(args, c.Path())
+
+		// Get current executable path.
+		holosPath, err := util.Executable()
+		if err != nil {
+			return errors.Wrap(err)
+		}
+
+		// Run holos render component ...
+		if _, err := util.RunCmdA(ctx, r.pcfg.Stderr, holosPath, args...); err != nil {
+			return errors.Format("could not render component: %w", err)
+		}
+		return nil
+	}
 }
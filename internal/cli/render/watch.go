@@ -0,0 +1,343 @@
+package render
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/holos-run/holos/internal/errors"
+	"github.com/holos-run/holos/internal/logger"
+	"github.com/holos-run/holos/internal/platform"
+	"github.com/holos-run/holos/internal/util"
+)
+
+// defaultWatchInterval represents the default interval between scans of the
+// platform root in watch mode.
+const defaultWatchInterval = 500 * time.Millisecond
+
+// fileState represents the state of one file used to detect changes.
+type fileState struct {
+	modTime time.Time
+	size    int64
+	mode    fs.FileMode
+}
+
+// watchScope represents the paths relative to the platform root scanned for
+// changes in watch mode.  Scanning the whole root on every tick is slow with
+// large vendored charts, so only the paths able to affect a render are
+// scanned.
+type watchScope struct {
+	// dirs represents the directories scanned recursively: cue.mod, the
+	// platform directory, and each component directory.
+	dirs []string
+	// parents represents the parent directories of each component.  Only the
+	// cue files directly in a parent directory are scanned, they are part of
+	// the component package.
+	parents []string
+	// skip represents the directories not scanned: the write-to directory and
+	// the vendored charts of each component.
+	skip []string
+}
+
+// newWatchScope returns the scope of the scan of the platform root.  CUE
+// packages imported from outside cue.mod, the platform directory, and the
+// component directories are not watched.
+func newWatchScope(writeTo, platformDir string, components []string) watchScope {
+	scope := watchScope{skip: []string{writeTo}}
+	dirs := append([]string{"cue.mod", platformDir}, components...)
+	parents := make(map[string]bool)
+	for _, dir := range dirs {
+		// Nested directories are scanned by their ancestor.
+		if !slices.ContainsFunc(dirs, func(other string) bool { return other != dir && within(dir, other) }) && !slices.Contains(scope.dirs, dir) {
+			scope.dirs = append(scope.dirs, dir)
+		}
+	}
+	for _, component := range components {
+		scope.skip = append(scope.skip, path.Join(component, "vendor"))
+		for dir := component; dir != "."; {
+			dir = path.Dir(dir)
+			if !slices.ContainsFunc(scope.dirs, func(other string) bool { return within(dir, other) }) {
+				parents[dir] = true
+			}
+		}
+	}
+	scope.parents = slices.Sorted(maps.Keys(parents))
+	return scope
+}
+
+// scanFiles returns the state of every file in scope keyed by slash separated
+// path relative to root.  Directories named .git are not scanned.
+func scanFiles(root string, scope watchScope) (map[string]fileState, error) {
+	files := make(map[string]fileState)
+	add := func(rel string, d fs.DirEntry) error {
+		info, err := d.Info()
+		if err != nil {
+			// A file removed during the scan is a change the next scan reports.
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		files[rel] = fileState{modTime: info.ModTime(), size: info.Size(), mode: info.Mode()}
+		return nil
+	}
+	for _, dir := range scope.dirs {
+		err := filepath.WalkDir(filepath.Join(root, dir), func(fullPath string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			rel, err := filepath.Rel(root, fullPath)
+			if err != nil {
+				return err
+			}
+			rel = filepath.ToSlash(rel)
+			if d.IsDir() {
+				if d.Name() == ".git" || slices.Contains(scope.skip, rel) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			return add(rel, d)
+		})
+		if err != nil {
+			return nil, errors.Format("could not scan %s: %w", filepath.Join(root, dir), err)
+		}
+	}
+	for _, dir := range scope.parents {
+		entries, err := os.ReadDir(filepath.Join(root, dir))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, errors.Format("could not scan %s: %w", filepath.Join(root, dir), err)
+		}
+		for _, d := range entries {
+			if d.IsDir() || path.Ext(d.Name()) != ".cue" {
+				continue
+			}
+			if err := add(path.Join(dir, d.Name()), d); err != nil {
+				return nil, errors.Format("could not scan %s: %w", filepath.Join(root, dir), err)
+			}
+		}
+	}
+	return files, nil
+}
+
+// changedFiles returns the sorted paths added, removed, or modified between
+// two scans.
+func changedFiles(prev, next map[string]fileState) []string {
+	var changed []string
+	for name, state := range next {
+		if old, ok := prev[name]; !ok || old != state {
+			changed = append(changed, name)
+		}
+	}
+	for name := range prev {
+		if _, ok := next[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
+
+// within returns true if name is dir or is under dir.  Every path is under
+// the "." dir.
+func within(name, dir string) bool {
+	return dir == "." || name == dir || strings.HasPrefix(name, dir+"/")
+}
+
+// affectedComponents maps changed files to the component paths to re-render.
+//
+//   - A file under a component directory affects the component, both as a CUE
+//     package file and as a component directory input.
+//   - A cue file in a parent directory of a component is part of the
+//     component package, so it affects the component.
+//   - A file under cue.mod or the platform directory affects every component.
+//     A change to the platform directory reloads the platform as well.
+//   - Any other cue file may belong to an imported package, so it affects
+//     every component.  Any other file affects no component.
+func affectedComponents(changed []string, platformDir string, components []string) (affected map[string]bool, all bool, reload bool) {
+	affected = make(map[string]bool)
+	for _, name := range changed {
+		isCUE := path.Ext(name) == ".cue"
+		if within(name, "cue.mod") {
+			all = true
+			continue
+		}
+		if within(name, platformDir) {
+			all, reload = true, true
+			continue
+		}
+		matched := false
+		for _, component := range components {
+			if within(name, component) || (isCUE && within(component, path.Dir(name))) {
+				affected[component] = true
+				matched = true
+			}
+		}
+		if isCUE && !matched {
+			all = true
+		}
+	}
+	return affected, all, reload
+}
+
+// tempDir returns the build temp directory for the component key.  Watch mode
+// reuses one emptied directory per component across cycles because a cached
+// TaskSet refers to its temp directory.
+func (r *renderPlatform) tempDir(key string) (string, error) {
+	if r.tempDirs == nil {
+		return os.MkdirTemp("", "holos.render")
+	}
+	if dir, ok := r.tempDirs[key]; ok {
+		if err := os.RemoveAll(dir); err != nil {
+			return "", err
+		}
+		return dir, os.Mkdir(dir, 0o700)
+	}
+	dir, err := os.MkdirTemp("", "holos.render")
+	if err != nil {
+		return "", err
+	}
+	r.tempDirs[key] = dir
+	return dir, nil
+}
+
+// watchLoop renders the platform, then scans the [watchScope] of the platform
+// root every watchInterval and re-renders the components affected by changed files
+// until ctx is done or the process is interrupted.  A compact summary is
+// written to stdout after each cycle.  Render errors are reported in the
+// summary and do not stop the loop.
+//
+// Files written by a render are not changes: the root is scanned again after
+// each render.
+func (r *renderPlatform) watchLoop(ctx context.Context, p *platform.Platform) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	r.compiled = make(map[string][]byte)
+	r.tempDirs = make(map[string]string)
+	defer func() {
+		for _, dir := range r.tempDirs {
+			util.Remove(ctx, dir)
+		}
+	}()
+
+	root := p.Root()
+	writeTo := filepath.ToSlash(filepath.Clean(r.pcfg.WriteTo))
+	if filepath.IsAbs(r.pcfg.WriteTo) {
+		if rel, err := filepath.Rel(root, r.pcfg.WriteTo); err == nil {
+			writeTo = filepath.ToSlash(rel)
+		}
+	}
+	platformDir := filepath.ToSlash(filepath.Clean(p.Leaf()))
+	// scope returns the scan scope of the selected components, which change
+	// when the platform reloads.
+	scope := func() (watchScope, []string) {
+		var paths []string
+		for _, c := range p.Select(r.pcfg.ComponentSelectors...) {
+			paths = append(paths, filepath.ToSlash(filepath.Clean(c.Path())))
+		}
+		return newWatchScope(writeTo, platformDir, paths), paths
+	}
+
+	r.changed = nil
+	r.cycle(ctx, p, nil)
+	current, _ := scope()
+	prev, err := scanFiles(root, current)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	ticker := time.NewTicker(max(r.watchInterval, 10*time.Millisecond))
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		current, paths := scope()
+		next, err := scanFiles(root, current)
+		if err != nil {
+			logger.FromContext(ctx).WarnContext(ctx, err.Error(), "err", err)
+			continue
+		}
+		changed := changedFiles(prev, next)
+		if len(changed) == 0 {
+			continue
+		}
+
+		affected, all, reload := affectedComponents(changed, platformDir, paths)
+		if reload {
+			if err := p.Load(ctx); err != nil {
+				r.summarize(changed, 0, 0, 0, err)
+				prev = next
+				continue
+			}
+		}
+		if !all && len(affected) == 0 {
+			// Not a summary, stdout may be redirected to a file under root.
+			logger.FromContext(ctx).DebugContext(ctx, fmt.Sprintf("watch: %d %s changed, no components affected", len(changed), util.Plural(len(changed), "file", "files")), "files", changed)
+			prev = next
+			continue
+		}
+		r.changed = affected
+		if all {
+			r.changed = nil
+		}
+		r.cycle(ctx, p, changed)
+
+		// Absorb the files written by the render.  A reload may select new
+		// components, so the scope is derived again.
+		current, _ = scope()
+		if prev, err = scanFiles(root, current); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+}
+
+// cycle renders the changed components once and summarizes the result.
+func (r *renderPlatform) cycle(ctx context.Context, p *platform.Platform, changed []string) {
+	start := time.Now()
+	total := len(p.Select(r.pcfg.ComponentSelectors...))
+	rendered := total
+	if r.changed != nil {
+		rendered = len(r.changed)
+	}
+	err := r.render(ctx, p)
+	r.summarize(changed, rendered, total, time.Since(start), err)
+}
+
+// summarize writes a one line summary of a watch cycle to stdout.
+func (r *renderPlatform) summarize(changed []string, rendered, total int, duration time.Duration, err error) {
+	var msg strings.Builder
+	msg.WriteString("watch: ")
+	if changed != nil {
+		fmt.Fprintf(&msg, "%d %s changed, ", len(changed), util.Plural(len(changed), "file", "files"))
+	}
+	switch {
+	case err != nil:
+		fmt.Fprintf(&msg, "render failed: %s", err)
+	default:
+		fmt.Fprintf(&msg, "rendered %d of %d components in %s", rendered, total, duration.Round(time.Millisecond))
+		if r.changed != nil {
+			msg.WriteString(": ")
+			msg.WriteString(strings.Join(slices.Sorted(maps.Keys(r.changed)), ", "))
+		}
+	}
+	fmt.Fprintln(r.pcfg.Stdout, msg.String())
+}
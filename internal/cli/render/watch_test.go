@@ -0,0 +1,162 @@
+package render
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAffectedComponents(t *testing.T) {
+	components := []string{"components/web", "components/web/canary", "components/db", "projects/api/backend"}
+
+	for _, tc := range []struct {
+		name     string
+		changed  []string
+		affected []string
+		all      bool
+		reload   bool
+	}{
+		{
+			name:     "ComponentInput",
+			changed:  []string{"components/db/values.yaml"},
+			affected: []string{"components/db"},
+		},
+		{
+			name:     "NestedComponent",
+			changed:  []string{"components/web/canary/canary.cue"},
+			affected: []string{"components/web", "components/web/canary"},
+		},
+		{
+			name:     "ParentPackageFile",
+			changed:  []string{"components/web/web.cue"},
+			affected: []string{"components/web", "components/web/canary"},
+		},
+		{
+			name:     "AncestorPackageFile",
+			changed:  []string{"projects/project.cue"},
+			affected: []string{"projects/api/backend"},
+		},
+		{
+			name:     "AncestorOtherFile",
+			changed:  []string{"projects/README.md"},
+			affected: []string{},
+		},
+		{
+			name:     "PrefixIsNotParent",
+			changed:  []string{"components/dbx/values.yaml"},
+			affected: []string{},
+		},
+		{
+			name:     "RootPackageFile",
+			changed:  []string{"schema.cue"},
+			affected: []string{"components/db", "components/web", "components/web/canary", "projects/api/backend"},
+		},
+		{
+			name:     "CueModule",
+			changed:  []string{"cue.mod/gen/example.cue"},
+			affected: []string{},
+			all:      true,
+		},
+		{
+			name:     "ImportedPackage",
+			changed:  []string{"lib/labels/labels.cue"},
+			affected: []string{},
+			all:      true,
+		},
+		{
+			name:     "Platform",
+			changed:  []string{"platform/components.cue"},
+			affected: []string{},
+			all:      true,
+			reload:   true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			affected, all, reload := affectedComponents(tc.changed, "platform", components)
+			have := make([]string, 0, len(affected))
+			for component := range affected {
+				have = append(have, component)
+			}
+			assert.ElementsMatch(t, tc.affected, have)
+			assert.Equal(t, tc.all, all)
+			assert.Equal(t, tc.reload, reload)
+		})
+	}
+}
+
+func TestChangedFiles(t *testing.T) {
+	root := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o777))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o666))
+	}
+	write("components/web/web.cue", "package holos\n")
+	write("components/db/db.cue", "package holos\n")
+	write("deploy/components/web/web.gen.yaml", "{}\n")
+	write(".git/HEAD", "ref: refs/heads/main\n")
+
+	scope := newWatchScope("deploy", "platform", []string{"components/web", "components/db"})
+	prev, err := scanFiles(root, scope)
+	require.NoError(t, err)
+	assert.Len(t, prev, 2, "expected deploy and .git not to be scanned")
+
+	write("components/web/web.cue", "package holos\n\nx: 1\n")
+	write("components/web/values.yaml", "{}\n")
+	write("deploy/components/web/web.gen.yaml", "{a: 1}\n")
+	require.NoError(t, os.Remove(filepath.Join(root, "components/db/db.cue")))
+	// Changes within the modification time granularity are detected by size.
+	now := time.Now()
+	require.NoError(t, os.Chtimes(filepath.Join(root, "components/web/web.cue"), now, now))
+
+	next, err := scanFiles(root, scope)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"components/db/db.cue",
+		"components/web/values.yaml",
+		"components/web/web.cue",
+	}, changedFiles(prev, next))
+	assert.Empty(t, changedFiles(next, next))
+}
+
+func TestScanFilesScope(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{
+		"cue.mod/module.cue",
+		"platform/components.cue",
+		"components/web/web.cue",
+		"components/web/vendor/1.0.0/chart/values.yaml",
+		"components/web/canary/canary.cue",
+		"components/components.cue",
+		"components/README.md",
+		"schema.cue",
+		"lib/labels/labels.cue",
+		"docs/index.md",
+	} {
+		path := filepath.Join(root, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o777))
+		require.NoError(t, os.WriteFile(path, []byte("{}\n"), 0o666))
+	}
+
+	scope := newWatchScope("deploy", "platform", []string{"components/web", "components/web/canary"})
+	assert.Equal(t, []string{"cue.mod", "platform", "components/web"}, scope.dirs, "expected nested components scanned by their ancestor")
+	assert.Equal(t, []string{".", "components"}, scope.parents)
+
+	files, err := scanFiles(root, scope)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"cue.mod/module.cue",
+		"platform/components.cue",
+		"components/web/web.cue",
+		"components/web/canary/canary.cue",
+		"components/components.cue",
+		"schema.cue",
+	}, slices.Collect(maps.Keys(files)), "expected vendored charts, parent non-cue files, and other directories not to be scanned")
+}
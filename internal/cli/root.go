@@ -14,6 +14,7 @@ import (
 	"github.com/holos-run/holos/internal/holos"
 	"github.com/holos-run/holos/internal/logger"
 	"github.com/holos-run/holos/internal/platform"
+	"github.com/holos-run/holos/internal/redact"
 
 	"github.com/holos-run/holos/internal/cli/command"
 	"github.com/holos-run/holos/internal/cli/render"
@@ -66,6 +67,7 @@ func New(cfg *holos.Config) *cobra.Command {
 
 	// subcommands
 	rootCmd.AddCommand(render.New(cfg))
+	rootCmd.AddCommand(render.NewDiffCommand(cfg))
 	rootCmd.AddCommand(newInitCommand())
 
 	// Maybe not needed?
@@ -124,7 +126,7 @@ func HandleError(ctx context.Context, err error, hc *holos.Config) (exitCode int
 
 	// cue errors are bundled up as a list and refer to multiple files / lines.
 	if errors.As(err, &cueErr) {
-		msg := cue_errors.Details(cueErr, nil)
+		msg := redact.String(cue_errors.Details(cueErr, nil))
 		if _, err := fmt.Fprint(hc.Stderr(), msg); err != nil {
 			log.ErrorContext(ctx, "could not write CUE error details: "+err.Error(), "err", err)
 		}
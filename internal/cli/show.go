@@ -12,6 +12,7 @@ import (
 	v1alpha6 "github.com/holos-run/holos/api/core/v1alpha6"
 	v1beta1 "github.com/holos-run/holos/api/core/v1beta1"
 	"github.com/holos-run/holos/internal/cli/command"
+	"github.com/holos-run/holos/internal/cli/render"
 	"github.com/holos-run/holos/internal/compile"
 	"github.com/holos-run/holos/internal/errors"
 	"github.com/holos-run/holos/internal/holos"
@@ -23,6 +24,9 @@ import (
 //go:embed long-show-buildplans.txt
 var longShowBuildPlansHelp string
 
+//go:embed long-show-tasks.txt
+var longShowTasksHelp string
+
 func NewShowCmd(cfg *platform.Config) (cmd *cobra.Command) {
 	cmd = command.New("show")
 	cmd.Short = "show a platform or build plans"
@@ -48,6 +52,19 @@ func NewShowCmd(cfg *platform.Config) (cmd *cobra.Command) {
 	sbCmd.Flags().AddFlagSet(cfg.FlagSet())
 	sbCmd.Flags().AddFlagSet(sbp.flagSet())
 	cmd.AddCommand(sbCmd)
+
+	stp := &showTasks{
+		format: "dot",
+		cfg:    cfg,
+	}
+	stCmd := platform.NewCommand(cfg, stp.Run)
+	stCmd.Use = "tasks"
+	stCmd.Short = "show the task graph"
+	stCmd.Long = longShowTasksHelp
+	stCmd.Aliases = []string{"task"}
+	stCmd.Flags().AddFlagSet(cfg.FlagSet())
+	stCmd.Flags().AddFlagSet(stp.flagSet())
+	cmd.AddCommand(stCmd)
 	return cmd
 }
 
@@ -149,3 +166,39 @@ func (s *showBuildPlans) Run(ctx context.Context, p *platform.Platform) error {
 
 	return nil
 }
+
+type showTasks struct {
+	format string
+	cfg    *platform.Config
+}
+
+func (s *showTasks) flagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+	fs.StringVar(&s.format, "format", s.format, "dot, mermaid, or json format")
+	return fs
+}
+
+func (s *showTasks) Run(ctx context.Context, p *platform.Platform) error {
+	switch s.format {
+	case "dot", "mermaid", "json":
+	default:
+		return errors.Format("invalid format %s: must be dot, mermaid, or json", s.format)
+	}
+
+	plan, err := render.Plan(ctx, s.cfg, p)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	graph := plan.Graph()
+
+	switch s.format {
+	case "mermaid":
+		return errors.Wrap(graph.WriteMermaid(s.cfg.Stdout))
+	case "json":
+		encoder := json.NewEncoder(s.cfg.Stdout)
+		encoder.SetIndent("", "  ")
+		return errors.Wrap(encoder.Encode(graph))
+	default:
+		return errors.Wrap(graph.WriteDOT(s.cfg.Stdout))
+	}
+}
@@ -0,0 +1,423 @@
+package compare
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/holos-run/holos/internal/errors"
+	"github.com/holos-run/holos/internal/redact"
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v3"
+)
+
+// Change values of an [ObjectDiff].
+const (
+	Added   = "added"
+	Changed = "changed"
+	Removed = "removed"
+)
+
+// ObjectKey identifies a Kubernetes object independent of the file containing
+// it.
+type ObjectKey struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+// String returns the key as apiVersion, kind, then namespace/name, for example
+// "apps/v1 Deployment default/podinfo".  Cluster scoped objects omit the
+// namespace.  A document named by its position in a file is only the name.
+func (k ObjectKey) String() string {
+	name := k.Name
+	if k.Namespace != "" {
+		name = k.Namespace + "/" + k.Name
+	}
+	if k.APIVersion == "" && k.Kind == "" {
+		return name
+	}
+	return fmt.Sprintf("%s %s %s", k.APIVersion, k.Kind, name)
+}
+
+func (k ObjectKey) compare(other ObjectKey) int {
+	return strings.Compare(k.String(), other.String())
+}
+
+// Object represents one Kubernetes object parsed from a manifest file.
+type Object struct {
+	Key ObjectKey
+	// File represents the slash separated path of the file containing the
+	// object.
+	File  string
+	Value map[string]interface{}
+}
+
+// ParseObjects parses the objects of the yaml stream content of file.  A
+// document without an apiVersion, kind, or metadata.name, for example a plain
+// values file, is named by its file and position in the stream so it is still
+// compared.
+func ParseObjects(file string, content []byte) ([]Object, error) {
+	docs, err := parseYAMLStream(content)
+	if err != nil {
+		return nil, errors.Format("could not parse %s: %w", file, err)
+	}
+	objects := make([]Object, 0, len(docs))
+	for idx, doc := range docs {
+		key := ObjectKey{
+			APIVersion: stringField(doc, "apiVersion"),
+			Kind:       stringField(doc, "kind"),
+		}
+		if metadata, ok := doc["metadata"].(map[string]interface{}); ok {
+			key.Namespace = stringField(metadata, "namespace")
+			key.Name = stringField(metadata, "name")
+		}
+		if key.APIVersion == "" || key.Kind == "" || key.Name == "" {
+			key = ObjectKey{Name: fmt.Sprintf("%s#%d", file, idx)}
+		}
+		objects = append(objects, Object{Key: key, File: file, Value: doc})
+	}
+	return objects, nil
+}
+
+func stringField(m map[string]interface{}, field string) string {
+	s, _ := m[field].(string)
+	return s
+}
+
+// Ignore represents a rule excluding a volatile field from the comparison of
+// objects, for example a checksum annotation or a generated certificate.
+type Ignore struct {
+	// Kind limits the rule to objects of one kind.  Empty matches every kind.
+	Kind string
+	// Path represents the field path.  The segment "*" matches every field of
+	// a map or item of a list.  A numeric segment also matches a list index.
+	// A path ending in a list item removes the item from the list, so the
+	// items after it are compared at their new index.
+	Path []string
+}
+
+// ParseIgnore parses an ignore rule of the form [kind:]path where path is a
+// dot separated field path.  A segment containing a dot is enclosed in square
+// brackets.  For example:
+//
+//	metadata.annotations[checksum/config]
+//	Secret:data
+//	Deployment:spec.template.metadata.annotations[kubectl.kubernetes.io/restartedAt]
+//	metadata.labels.*
+func ParseIgnore(rule string) (Ignore, error) {
+	var ignore Ignore
+	path := rule
+	if idx := strings.IndexAny(rule, ":.["); idx > 0 && rule[idx] == ':' {
+		ignore.Kind, path = rule[:idx], rule[idx+1:]
+	}
+	for len(path) > 0 {
+		var segment string
+		if path[0] == '[' {
+			end := strings.IndexByte(path, ']')
+			if end < 0 {
+				return ignore, errors.Format("could not parse ignore rule %q: missing ]", rule)
+			}
+			segment, path = path[1:end], path[end+1:]
+		} else {
+			end := strings.IndexAny(path, ".[")
+			if end < 0 {
+				end = len(path)
+			}
+			segment, path = path[:end], path[end:]
+		}
+		if segment == "" {
+			return ignore, errors.Format("could not parse ignore rule %q: empty field", rule)
+		}
+		ignore.Path = append(ignore.Path, segment)
+		path = strings.TrimPrefix(path, ".")
+	}
+	if len(ignore.Path) == 0 {
+		return ignore, errors.Format("could not parse ignore rule %q: empty path", rule)
+	}
+	return ignore, nil
+}
+
+// String returns the rule in the form parsed by [ParseIgnore].
+func (i Ignore) String() string {
+	var sb strings.Builder
+	if i.Kind != "" {
+		sb.WriteString(i.Kind + ":")
+	}
+	for idx, segment := range i.Path {
+		switch {
+		case strings.ContainsAny(segment, ".[]:"):
+			sb.WriteString("[" + segment + "]")
+		case idx > 0:
+			sb.WriteString("." + segment)
+		default:
+			sb.WriteString(segment)
+		}
+	}
+	return sb.String()
+}
+
+// apply removes the fields matched by the rule from value in place.
+func (i Ignore) apply(kind string, value interface{}) {
+	if i.Kind != "" && i.Kind != kind {
+		return
+	}
+	remove(value, i.Path)
+}
+
+// remove returns value with the fields and list items matched by path
+// removed.  Maps are modified in place, lists are replaced.
+func remove(value interface{}, path []string) interface{} {
+	segment, last := path[0], len(path) == 1
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key := range v {
+			if segment != "*" && segment != key {
+				continue
+			}
+			if last {
+				delete(v, key)
+				continue
+			}
+			v[key] = remove(v[key], path[1:])
+			// An object or list left empty is equivalent to a missing one.
+			switch child := v[key].(type) {
+			case map[string]interface{}:
+				if len(child) == 0 {
+					delete(v, key)
+				}
+			case []interface{}:
+				if len(child) == 0 {
+					delete(v, key)
+				}
+			}
+		}
+		return v
+	case []interface{}:
+		items := make([]interface{}, 0, len(v))
+		for idx, item := range v {
+			if segment == "*" || segment == strconv.Itoa(idx) {
+				if last {
+					continue
+				}
+				item = remove(item, path[1:])
+			}
+			items = append(items, item)
+		}
+		return items
+	default:
+		return value
+	}
+}
+
+// Masks of the Secret values replaced by [MaskSecrets].
+const (
+	Mask       = "***"
+	MaskBefore = "*** (before)"
+	MaskAfter  = "*** (after)"
+)
+
+// MaskSecrets replaces the values of the data and stringData fields of each v1
+// Secret in before and after in place, like kubectl diff.  A value differing
+// between the Secret before and the Secret after is masked as [MaskBefore] and
+// [MaskAfter] so the change remains visible, other values as [Mask].
+func MaskSecrets(before, after []Object) {
+	secrets := func(objects []Object) map[ObjectKey]map[string]interface{} {
+		m := make(map[ObjectKey]map[string]interface{})
+		for _, obj := range objects {
+			if obj.Key.APIVersion == "v1" && obj.Key.Kind == "Secret" {
+				m[obj.Key] = obj.Value
+			}
+		}
+		return m
+	}
+	have, want := secrets(before), secrets(after)
+	for key, b := range have {
+		for _, field := range []string{"data", "stringData"} {
+			bv, _ := b[field].(map[string]interface{})
+			av, _ := want[key][field].(map[string]interface{})
+			maskValues(bv, av)
+		}
+	}
+	for key, a := range want {
+		if _, found := have[key]; !found {
+			for _, field := range []string{"data", "stringData"} {
+				av, _ := a[field].(map[string]interface{})
+				maskValues(nil, av)
+			}
+		}
+	}
+}
+
+// maskValues masks the values of the Secret data before and after.  Either may
+// be nil.
+func maskValues(before, after map[string]interface{}) {
+	for key, b := range before {
+		a, found := after[key]
+		switch {
+		case !found:
+			before[key] = Mask
+		case cmp.Equal(b, a):
+			before[key], after[key] = Mask, Mask
+		default:
+			before[key], after[key] = MaskBefore, MaskAfter
+		}
+	}
+	for key := range after {
+		if _, found := before[key]; !found {
+			after[key] = Mask
+		}
+	}
+}
+
+// ObjectDiff represents one object added, changed, or removed.
+type ObjectDiff struct {
+	ObjectKey
+	// Component represents the platform component rendering the object, set by
+	// the caller.
+	Component string `json:"component"`
+	// Change is one of added, changed, or removed.
+	Change string `json:"change"`
+	// File represents the slash separated path of the file containing the
+	// object, the file before if removed.
+	File string `json:"file"`
+	// Diff represents the unified diff of the object encoded as yaml.
+	Diff string `json:"diff"`
+}
+
+// DiffObjects matches the objects of before and after by apiVersion, kind,
+// namespace, and name and returns the objects added, changed, or removed
+// sorted by key.  Objects equal once the ignore rules are applied are omitted,
+// including objects moved from one file to another.  The from and to labels
+// name each side in the unified diff headers.  Two objects with the same key
+// on one side is an error.
+func DiffObjects(before, after []Object, ignore []Ignore, from, to string) ([]ObjectDiff, error) {
+	have, err := index(before, ignore)
+	if err != nil {
+		return nil, errors.Format("%s: %w", from, err)
+	}
+	want, err := index(after, ignore)
+	if err != nil {
+		return nil, errors.Format("%s: %w", to, err)
+	}
+
+	var diffs []ObjectDiff
+	for key, a := range want {
+		b, found := have[key]
+		switch {
+		case !found:
+			diffs = append(diffs, ObjectDiff{ObjectKey: key, Change: Added, File: a.File})
+		case !cmp.Equal(b.Value, a.Value, cmpopts.EquateEmpty()):
+			diffs = append(diffs, ObjectDiff{ObjectKey: key, Change: Changed, File: a.File})
+		default:
+			continue
+		}
+		if err := unified(&diffs[len(diffs)-1], b.Value, a.Value, from, to); err != nil {
+			return nil, err
+		}
+	}
+	for key, b := range have {
+		if _, found := want[key]; found {
+			continue
+		}
+		diffs = append(diffs, ObjectDiff{ObjectKey: key, Change: Removed, File: b.File})
+		if err := unified(&diffs[len(diffs)-1], b.Value, nil, from, to); err != nil {
+			return nil, err
+		}
+	}
+
+	slices.SortFunc(diffs, func(a, b ObjectDiff) int { return a.ObjectKey.compare(b.ObjectKey) })
+	return diffs, nil
+}
+
+// index returns the objects keyed by [ObjectKey] with the ignore rules applied
+// to a copy of each value.
+func index(objects []Object, ignore []Ignore) (map[ObjectKey]Object, error) {
+	m := make(map[ObjectKey]Object, len(objects))
+	for _, obj := range objects {
+		if prev, found := m[obj.Key]; found {
+			return nil, errors.Format("duplicate object %s in %s and %s", obj.Key, prev.File, obj.File)
+		}
+		value, _ := deepCopy(obj.Value).(map[string]interface{})
+		for _, rule := range ignore {
+			rule.apply(obj.Key.Kind, value)
+		}
+		obj.Value = value
+		m[obj.Key] = obj
+	}
+	return m, nil
+}
+
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[key] = deepCopy(item)
+		}
+		return m
+	case []interface{}:
+		l := make([]interface{}, len(v))
+		for idx, item := range v {
+			l[idx] = deepCopy(item)
+		}
+		return l
+	default:
+		return v
+	}
+}
+
+// unified sets the unified diff of d from before to after with sensitive values
+// registered with package redact masked.  A nil value is an absent object.
+func unified(d *ObjectDiff, before, after map[string]interface{}, from, to string) error {
+	a, err := encode(before)
+	if err != nil {
+		return err
+	}
+	b, err := encode(after)
+	if err != nil {
+		return err
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(a),
+		B:        splitLines(b),
+		FromFile: fmt.Sprintf("%s (%s)", d.ObjectKey, from),
+		ToFile:   fmt.Sprintf("%s (%s)", d.ObjectKey, to),
+		Context:  3,
+	})
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	d.Diff = redact.String(diff)
+	return nil
+}
+
+// splitLines splits s into lines, none if s is empty.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return difflib.SplitLines(strings.TrimSuffix(s, "\n"))
+}
+
+// encode returns value as yaml with sorted keys, empty if value is nil.
+func encode(value map[string]interface{}) (string, error) {
+	if value == nil {
+		return "", nil
+	}
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(value); err != nil {
+		return "", errors.Wrap(err)
+	}
+	if err := encoder.Close(); err != nil {
+		return "", errors.Wrap(err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,284 @@
+package compare
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIgnore(t *testing.T) {
+	tests := []struct {
+		rule string
+		want Ignore
+	}{
+		{rule: "metadata.labels", want: Ignore{Path: []string{"metadata", "labels"}}},
+		{rule: "Secret:data", want: Ignore{Kind: "Secret", Path: []string{"data"}}},
+		{rule: "metadata.annotations[checksum/config]", want: Ignore{Path: []string{"metadata", "annotations", "checksum/config"}}},
+		{rule: "Deployment:spec.template.metadata.annotations[kubectl.kubernetes.io/restartedAt]", want: Ignore{Kind: "Deployment", Path: []string{"spec", "template", "metadata", "annotations", "kubectl.kubernetes.io/restartedAt"}}},
+		{rule: "spec.containers.*.image", want: Ignore{Path: []string{"spec", "containers", "*", "image"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			got, err := ParseIgnore(tt.rule)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			again, err := ParseIgnore(got.String())
+			require.NoError(t, err)
+			assert.Equal(t, got, again)
+		})
+	}
+
+	for _, rule := range []string{"", "Secret:", "metadata..name", "metadata.annotations[x"} {
+		_, err := ParseIgnore(rule)
+		assert.Error(t, err, rule)
+	}
+}
+
+func TestDiffObjects(t *testing.T) {
+	parse := func(file, content string) []Object {
+		t.Helper()
+		objects, err := ParseObjects(file, []byte(content))
+		require.NoError(t, err)
+		return objects
+	}
+	before := append(
+		parse("a.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: moved
+data:
+  key: value
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: volatile
+  annotations:
+    checksum/config: "1"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: removed
+`),
+		parse("b.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: default
+spec:
+  replicas: 1
+`)...)
+	after := append(
+		parse("b.yaml", `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: default
+spec:
+  replicas: 2
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: volatile
+  annotations:
+    checksum/config: "2"
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: default
+`),
+		parse("c.yaml", `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: moved
+data:
+  key: value
+`)...)
+
+	ignore, err := ParseIgnore("metadata.annotations[checksum/config]")
+	require.NoError(t, err)
+	diffs, err := DiffObjects(before, after, []Ignore{ignore}, "before", "after")
+	require.NoError(t, err)
+
+	type change struct{ key, change, file string }
+	var got []change
+	for _, d := range diffs {
+		got = append(got, change{d.ObjectKey.String(), d.Change, d.File})
+	}
+	assert.Equal(t, []change{
+		{"apps/v1 Deployment default/app", Changed, "b.yaml"},
+		{"v1 ConfigMap removed", Removed, "a.yaml"},
+		{"v1 Namespace default", Added, "b.yaml"},
+	}, got)
+
+	assert.Equal(t, `--- apps/v1 Deployment default/app (before)
++++ apps/v1 Deployment default/app (after)
+@@ -4,4 +4,4 @@
+   name: app
+   namespace: default
+ spec:
+-  replicas: 1
++  replicas: 2
+`, diffs[0].Diff)
+
+	// The same key twice on one side is an error.
+	_, err = DiffObjects(append(before, before[0]), after, nil, "before", "after")
+	assert.ErrorContains(t, err, "duplicate object v1 ConfigMap moved in a.yaml and a.yaml")
+}
+
+func TestIgnoreListItems(t *testing.T) {
+	value := func() map[string]interface{} {
+		return map[string]interface{}{
+			"spec": map[string]interface{}{
+				"args":        []interface{}{"--a", "--volatile", "--b"},
+				"tolerations": []interface{}{map[string]interface{}{"key": "x"}},
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "image": "app:1"},
+					map[string]interface{}{"name": "sidecar", "image": "sidecar:1"},
+				},
+			},
+		}
+	}
+	for _, tt := range []struct {
+		rule string
+		key  string
+		// want represents the value of the key in spec, nil if removed.
+		want interface{}
+	}{
+		{rule: "spec.args.1", key: "args", want: []interface{}{"--a", "--b"}},
+		// A list left empty is removed like a map left empty.
+		{rule: "spec.tolerations.*", key: "tolerations"},
+		{rule: "spec.containers.*.image", key: "containers", want: []interface{}{
+			map[string]interface{}{"name": "app"},
+			map[string]interface{}{"name": "sidecar"},
+		}},
+	} {
+		t.Run(tt.rule, func(t *testing.T) {
+			rule, err := ParseIgnore(tt.rule)
+			require.NoError(t, err)
+			v := value()
+			rule.apply("Deployment", v)
+			spec := v["spec"].(map[string]interface{})
+			if tt.want == nil {
+				assert.NotContains(t, spec, tt.key)
+			} else {
+				assert.Equal(t, tt.want, spec[tt.key])
+			}
+		})
+	}
+}
+
+func TestMaskSecrets(t *testing.T) {
+	parse := func(content string) []Object {
+		t.Helper()
+		objects, err := ParseObjects("secret.yaml", []byte(content))
+		require.NoError(t, err)
+		return objects
+	}
+	before := parse(`
+apiVersion: v1
+kind: Secret
+metadata:
+  name: changed
+data:
+  same: c2FtZQ==
+  changed: b2xk
+  removed: Z29uZQ==
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: removed
+stringData:
+  password: hunter1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: value
+`)
+	after := parse(`
+apiVersion: v1
+kind: Secret
+metadata:
+  name: changed
+data:
+  same: c2FtZQ==
+  changed: bmV3
+  added: bmV3
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: added
+stringData:
+  password: hunter2
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: value
+`)
+	MaskSecrets(before, after)
+
+	assert.Equal(t, map[string]interface{}{"same": Mask, "changed": MaskBefore, "removed": Mask}, before[0].Value["data"])
+	assert.Equal(t, map[string]interface{}{"same": Mask, "changed": MaskAfter, "added": Mask}, after[0].Value["data"])
+	assert.Equal(t, map[string]interface{}{"password": Mask}, before[1].Value["stringData"])
+	assert.Equal(t, map[string]interface{}{"password": Mask}, after[1].Value["stringData"])
+	assert.Equal(t, map[string]interface{}{"key": "value"}, after[2].Value["data"], "only secrets are masked")
+
+	// The change remains visible.
+	diffs, err := DiffObjects(before, after, nil, "before", "after")
+	require.NoError(t, err)
+	require.Len(t, diffs, 3)
+	assert.Contains(t, diffs[1].Diff, "-  changed: '*** (before)'\n")
+	assert.Contains(t, diffs[1].Diff, "+  changed: '*** (after)'\n")
+	for _, d := range diffs {
+		assert.NotContains(t, d.Diff, "hunter")
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	r := NewReport("deploy", []ObjectDiff{
+		{Component: "b", Change: Added},
+		{Component: "a", Change: Changed},
+		{Component: "a", Change: Removed},
+		{Component: "a", Change: Removed},
+	})
+	assert.Equal(t, []ComponentSummary{
+		{Component: "a", Changed: 1, Removed: 2},
+		{Component: "b", Added: 1},
+	}, r.Summary)
+
+	assert.True(t, NewReport("deploy", nil).Empty())
+}
+
+func TestWriteMarkdownFence(t *testing.T) {
+	r := NewReport("deploy", []ObjectDiff{{
+		ObjectKey: ObjectKey{APIVersion: "v1", Kind: "ConfigMap", Name: "docs"},
+		Component: "docs",
+		Change:    Added,
+		File:      "docs.yaml",
+		Diff:      "+data:\n+  README.md: |\n+    ````go\n+    ````\n",
+	}})
+	var buf bytes.Buffer
+	require.NoError(t, r.WriteMarkdown(&buf))
+	// The fence is longer than the backtick run within the diff.
+	assert.Contains(t, buf.String(), "\n`````diff\n+data:\n")
+	assert.Contains(t, buf.String(), "+    ````\n`````\n")
+
+	assert.Equal(t, "```", fence("no backticks"))
+}
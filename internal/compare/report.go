@@ -0,0 +1,122 @@
+package compare
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/holos-run/holos/internal/errors"
+)
+
+// Report represents the objects a render adds, changes, or removes relative to
+// a previous render, for example the write-to directory or a git ref.
+type Report struct {
+	// From represents the previous render.
+	From string `json:"from"`
+	// Summary counts the changed objects of each component sorted by
+	// component.
+	Summary []ComponentSummary `json:"summary"`
+	// Objects represents the changed objects sorted by key.
+	Objects []ObjectDiff `json:"objects"`
+}
+
+// ComponentSummary counts the objects of one component added, changed, or
+// removed.
+type ComponentSummary struct {
+	Component string `json:"component"`
+	Added     int    `json:"added"`
+	Changed   int    `json:"changed"`
+	Removed   int    `json:"removed"`
+}
+
+// NewReport returns a report of diffs summarized by component.
+func NewReport(from string, diffs []ObjectDiff) *Report {
+	counts := make(map[string]*ComponentSummary)
+	for _, d := range diffs {
+		s := counts[d.Component]
+		if s == nil {
+			s = &ComponentSummary{Component: d.Component}
+			counts[d.Component] = s
+		}
+		switch d.Change {
+		case Added:
+			s.Added++
+		case Changed:
+			s.Changed++
+		case Removed:
+			s.Removed++
+		}
+	}
+	r := &Report{From: from, Summary: make([]ComponentSummary, 0, len(counts)), Objects: diffs}
+	if r.Objects == nil {
+		r.Objects = []ObjectDiff{}
+	}
+	for _, s := range counts {
+		r.Summary = append(r.Summary, *s)
+	}
+	slices.SortFunc(r.Summary, func(a, b ComponentSummary) int { return strings.Compare(a.Component, b.Component) })
+	return r
+}
+
+// Empty returns true if no object differs.
+func (r *Report) Empty() bool {
+	return len(r.Objects) == 0
+}
+
+// WriteText writes the unified diff of each object followed by the summary of
+// each component.
+func (r *Report) WriteText(w io.Writer) error {
+	if r.Empty() {
+		_, err := fmt.Fprintf(w, "no objects differ from %s\n", r.From)
+		return errors.Wrap(err)
+	}
+	var sb strings.Builder
+	for _, d := range r.Objects {
+		fmt.Fprintf(&sb, "%s %s in %s\n%s\n", d.Change, d.ObjectKey, d.File, d.Diff)
+	}
+	for _, s := range r.Summary {
+		fmt.Fprintf(&sb, "%s: %d added, %d changed, %d removed\n", s.Component, s.Added, s.Changed, s.Removed)
+	}
+	_, err := io.WriteString(w, sb.String())
+	return errors.Wrap(err)
+}
+
+// WriteMarkdown writes the report as markdown suitable for a pull request
+// comment: a summary table followed by the unified diff of each object in a
+// collapsed section.
+func (r *Report) WriteMarkdown(w io.Writer) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "### Rendered objects compared with %s\n\n", "`"+r.From+"`")
+	if r.Empty() {
+		sb.WriteString("No objects differ.\n")
+	} else {
+		sb.WriteString("| Component | Added | Changed | Removed |\n")
+		sb.WriteString("| --- | ---: | ---: | ---: |\n")
+		for _, s := range r.Summary {
+			fmt.Fprintf(&sb, "| %s | %d | %d | %d |\n", s.Component, s.Added, s.Changed, s.Removed)
+		}
+		for _, d := range r.Objects {
+			fmt.Fprintf(&sb, "\n<details>\n<summary>%s <code>%s</code> in <code>%s</code></summary>\n\n", d.Change, d.ObjectKey, d.File)
+			f := fence(d.Diff)
+			fmt.Fprintf(&sb, "%sdiff\n%s%s\n\n</details>\n", f, d.Diff, f)
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return errors.Wrap(err)
+}
+
+// fence returns a markdown code fence longer than any run of backticks in s so
+// the content cannot close the fence early.
+func fence(s string) string {
+	longest, run := 0, 0
+	for _, r := range s {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	return strings.Repeat("`", max(3, longest+1))
+}
@@ -14,12 +14,14 @@ import (
 	"log/slog"
 	"os"
 	"os/exec"
+	"strconv"
 	"time"
 
 	componentPkg "github.com/holos-run/holos/internal/component"
 	"github.com/holos-run/holos/internal/errors"
 	"github.com/holos-run/holos/internal/holos"
 	"github.com/holos-run/holos/internal/logger"
+	"github.com/holos-run/holos/internal/trace"
 	"github.com/holos-run/holos/internal/util"
 	"golang.org/x/sync/errgroup"
 )
@@ -126,13 +128,19 @@ func (c *Compiler) Run(ctx context.Context) error {
 		if err != nil {
 			return errors.Wrap(err)
 		}
-		// TODO(jjm): Decide how to handle the temp directory.
-		opts := holos.NewBuildOpts(req.Root, req.Leaf, req.WriteTo, "${TMPDIR_PLACEHOLDER}")
+		// The temp directory is injected into the build context.  holos render
+		// platform provides a real directory so the parent may execute the
+		// TaskSet; holos show buildplans provides a placeholder.
+		tempDir := req.TempDir
+		if tempDir == "" {
+			tempDir = "${TMPDIR_PLACEHOLDER}"
+		}
+		opts := holos.NewBuildOpts(req.Root, req.Leaf, req.WriteTo, tempDir)
 
 		// Component name, label, annotations passed via tags to cue.
 		opts.Tags = req.Tags
 
-		bp, err := component.BuildPlan(tm, opts, cfg.TagMap)
+		bp, err := component.BuildPlan(ctx, tm, opts, cfg.TagMap)
 		if err != nil {
 			return errors.Wrap(err)
 		}
@@ -240,12 +248,18 @@ func compiler(ctx context.Context, id int, tasks chan task, resp []BuildPlanResp
 				log.DebugContext(ctx, fmt.Sprintf("%s: tasks channel closed: returning normally", msg))
 				return nil
 			}
+			// The span covers the round trip to the compiler, which builds the
+			// cue instance of the component.
+			_, span := trace.Start(ctx, "cue", "BuildInstance", "path", tsk.req.Leaf, "compiler", strconv.Itoa(id))
 			log.DebugContext(ctx, fmt.Sprintf("%s: encoding request seq=%d", msg, tsk.idx))
 			if err := encoder.Encode(tsk.req); err != nil {
+				span.End()
 				return errors.Format("could not encode request for %s: %w", msg, err)
 			}
 			log.DebugContext(ctx, fmt.Sprintf("%s: decoding response seq=%d", msg, tsk.idx))
-			if err := decoder.Decode(&resp[tsk.idx].RawMessage); err != nil {
+			err := decoder.Decode(&resp[tsk.idx].RawMessage)
+			span.End()
+			if err != nil {
 				return errors.Format("could not decode response from %s: %w\n%s", msg, err, stderrBuf.String())
 			}
 			log.DebugContext(ctx, fmt.Sprintf("%s: ok finished task seq=%d", msg, tsk.idx))
@@ -14,6 +14,8 @@ import (
 	"github.com/holos-run/holos/internal/errors"
 	"github.com/holos-run/holos/internal/holos"
 	"github.com/holos-run/holos/internal/logger"
+	"github.com/holos-run/holos/internal/stage"
+	"github.com/holos-run/holos/internal/trace"
 	"github.com/holos-run/holos/internal/util"
 	"gopkg.in/yaml.v3"
 )
@@ -36,6 +38,33 @@ type Component struct {
 	Root string
 	// Path represents the component path relative to Root.
 	Path string
+	// CacheDir represents the task result cache directory used by v1beta1
+	// TaskSets.  Empty disables the cache.
+	CacheDir string
+	// Plan writes the derived task graph of a v1beta1 TaskSet to Stdout
+	// instead of executing it.
+	Plan bool
+	// KeepGoing executes every task of a v1beta1 TaskSet not depending on a
+	// failed task instead of failing fast.
+	KeepGoing bool
+	// TaskSelectors select the tasks of a v1beta1 TaskSet to execute by label.
+	TaskSelectors holos.Selectors
+	// Task represents the name of the one v1beta1 task to execute along with
+	// its transitive predecessors.  Every task executes if empty.
+	Task string
+	// TaskOutput represents where to write the output of Task after the build,
+	// "-" for Stdout or a directory.  Nothing is written if empty.
+	TaskOutput string
+	// Check writes the files the render would add, change, or remove in the
+	// write-to directory to Stdout instead of writing them, failing if any
+	// differ.
+	Check bool
+	// Stdout represents the standard output pipe.
+	Stdout io.Writer
+
+	// artifacts represents the artifact paths written by the Artifact tasks of
+	// a v1beta1 TaskSet, replaced as a whole when the stage is promoted.
+	artifacts []string
 }
 
 // TypeMeta returns the [holos.TypeMeta] of the resource the component produces.
@@ -70,6 +99,29 @@ func (c *Component) Render(ctx context.Context, writeTo string, stderr io.Writer
 		return errors.Format("could not discriminate component type: %w", err)
 	}
 
+	if c.Plan && tm.APIVersion != "v1beta1" {
+		return errors.Format("could not plan %s: unsupported version %s: plans require v1beta1", c.Path, tm.APIVersion)
+	}
+	if c.Task != "" && tm.APIVersion != "v1beta1" {
+		return errors.Format("could not render task %s of %s: unsupported version %s: tasks require v1beta1", c.Task, c.Path, tm.APIVersion)
+	}
+	if c.TaskOutput != "" && c.Task == "" {
+		return errors.Format("could not render %s: task output requires a task", c.Path)
+	}
+
+	// Render into a stage promoted into the write-to directory once every task
+	// succeeds.  A render into the stage of holos render platform writes
+	// directly.
+	var s *stage.Stage
+	dest := writeTo
+	if !c.Plan && (c.Check || !stage.IsStage(writeTo)) {
+		if s, err = stage.New(c.Root, writeTo); err != nil {
+			return errors.Wrap(err)
+		}
+		defer s.Remove(ctx)
+		writeTo = s.WriteTo()
+	}
+
 	switch tm.APIVersion {
 	case "v1alpha6", "v1beta1":
 		if err := c.render(ctx, tm, writeTo, stderr, concurrency, tagMap); err != nil {
@@ -82,11 +134,24 @@ func (c *Component) Render(ctx context.Context, writeTo string, stderr io.Writer
 	default:
 		return errors.Format("unsupported version: %v", tm.APIVersion)
 	}
+
+	if s != nil && c.Check {
+		changes, err := s.Diff(c.artifacts)
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		return errors.Wrap(changes.Check(c.Stdout, dest))
+	}
+	if s != nil {
+		if _, err := s.Promote(ctx, c.artifacts); err != nil {
+			return errors.Wrap(err)
+		}
+	}
 	return nil
 }
 
 // BuildPlan returns the BuildPlan for the component.
-func (c *Component) BuildPlan(tm holos.TypeMeta, opts holos.BuildOpts, tagMap holos.TagMap) (BuildPlan, error) {
+func (c *Component) BuildPlan(ctx context.Context, tm holos.TypeMeta, opts holos.BuildOpts, tagMap holos.TagMap) (BuildPlan, error) {
 	// Generic build plan wrapper for all api versions.
 	var bp BuildPlan
 	// All versions allow tags explicitly injected using the --inject flag.
@@ -133,7 +198,9 @@ func (c *Component) BuildPlan(tm holos.TypeMeta, opts holos.BuildOpts, tagMap ho
 		return bp, errors.Format("unsupported version: %s", tm.APIVersion)
 	}
 
+	_, span := trace.Start(ctx, "cue", "BuildInstance", "path", c.Path)
 	inst, err := BuildInstance(c.Root, c.Path, tags)
+	span.End()
 	if err != nil {
 		return bp, errors.Format("could not load cue instance: %w", err)
 	}
@@ -145,7 +212,10 @@ func (c *Component) BuildPlan(tm holos.TypeMeta, opts holos.BuildOpts, tagMap ho
 	}
 
 	// Load the BuildPlan from the cue value.
-	if err := bp.Load(v); err != nil {
+	_, span = trace.Start(ctx, "cue", "Load", "path", c.Path)
+	err = bp.Load(v)
+	span.End()
+	if err != nil {
 		return bp, errors.Wrap(err)
 	}
 
@@ -177,19 +247,55 @@ func (c *Component) render(ctx context.Context, tm holos.TypeMeta, writeTo strin
 	opts := holos.NewBuildOpts(c.Root, c.Path, writeTo, tempDir)
 	opts.Stderr = stderr
 	opts.Concurrency = concurrency
+	opts.CacheDir = c.CacheDir
+	opts.KeepGoing = c.KeepGoing
+	opts.TaskSelectors = c.TaskSelectors
+	opts.Task = c.Task
 
 	log := logger.FromContext(ctx)
 	log.DebugContext(ctx, fmt.Sprintf("rendering %s kind %s version %s", c.Path, tm.Kind, tm.APIVersion), "kind", tm.Kind, "apiVersion", tm.APIVersion, "path", c.Path)
 
 	// Get the BuildPlan from cue.
-	bp, err := c.BuildPlan(tm, opts, tagMap)
+	bp, err := c.BuildPlan(ctx, tm, opts, tagMap)
 	if err != nil {
 		return errors.Wrap(err)
 	}
+	// Print the derived task graph without executing it.
+	if c.Plan {
+		ts, ok := bp.BuildPlan.(*v1beta1.TaskSet)
+		if !ok {
+			return errors.Format("could not plan %s: not a task set", c.Path)
+		}
+		plan, err := ts.Plan(ctx)
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		return errors.Wrap(plan.Write(c.Stdout))
+	}
+	// Check the task has an output to write before executing any task.
+	var ts *v1beta1.TaskSet
+	if c.TaskOutput != "" {
+		var ok bool
+		if ts, ok = bp.BuildPlan.(*v1beta1.TaskSet); !ok {
+			return errors.Format("could not write task output of %s: not a task set", c.Path)
+		}
+		if _, err := ts.TaskOutput(c.Task); err != nil {
+			return errors.Wrap(err)
+		}
+	}
 	// Execute the build.
 	if err := bp.Build(ctx); err != nil {
 		return errors.Wrap(err)
 	}
+	if ts, ok := bp.BuildPlan.(*v1beta1.TaskSet); ok {
+		c.artifacts = ts.Artifacts()
+	}
+	// Write the output of the one task rendered.
+	if ts != nil {
+		if err := ts.WriteTaskOutput(c.Task, c.TaskOutput, c.Stdout); err != nil {
+			return errors.Wrap(err)
+		}
+	}
 
 	return nil
 }
@@ -219,7 +325,7 @@ func (c *Component) renderAlpha5(ctx context.Context, writeTo string, stderr io.
 	}
 
 	// Get the BuildPlan from cue.
-	bp, err := c.BuildPlan(tm, opts, tagMap)
+	bp, err := c.BuildPlan(ctx, tm, opts, tagMap)
 	if err != nil {
 		return errors.Wrap(err)
 	}
@@ -1,6 +1,7 @@
 package component
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -9,6 +10,7 @@ import (
 	"github.com/holos-run/holos/internal/cli/command"
 	"github.com/holos-run/holos/internal/errors"
 	"github.com/holos-run/holos/internal/holos"
+	"github.com/holos-run/holos/internal/trace"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
@@ -23,6 +25,27 @@ type Config struct {
 	// Stderr represents the standard error output pipe.  Used to copy stderr
 	// output from subcommands.
 	Stderr io.Writer
+	// CacheDir represents the task result cache directory.  Empty disables the
+	// cache.
+	CacheDir string
+	// Plan prints the derived task graph without executing it.
+	Plan bool
+	// KeepGoing executes every task not depending on a failed task instead of
+	// failing fast.
+	KeepGoing bool
+	// TaskSelectors select the tasks to execute by label.
+	TaskSelectors holos.Selectors
+	// Task represents the one task to execute along with its transitive
+	// predecessors.
+	Task string
+	// TaskOutput represents where to write the output of Task, "-" for stdout
+	// or a directory.
+	TaskOutput string
+	// Check compares the render with the write-to directory instead of writing
+	// to it.
+	Check bool
+	// Trace records render spans to a trace file.
+	Trace trace.Config
 }
 
 func (c *Config) flagSet() *pflag.FlagSet {
@@ -30,6 +53,14 @@ func (c *Config) flagSet() *pflag.FlagSet {
 	fs.StringVar(&c.WriteTo, "write-to", c.WriteTo, fmt.Sprintf("write to directory (%s)", holos.WriteToEnvVar))
 	fs.VarP(c.TagMap, "inject", "t", holos.TagMapHelp)
 	fs.IntVar(&c.Concurrency, "concurrency", c.Concurrency, "number of concurrent build steps")
+	fs.BoolVar(&c.Plan, "plan", c.Plan, "print the derived task graph without executing it (v1beta1)")
+	fs.BoolVar(&c.KeepGoing, "keep-going", c.KeepGoing, "keep executing tasks not depending on a failed task, then report every failure (v1beta1)")
+	fs.Var(&c.TaskSelectors, "task-selector", holos.TaskSelectorHelp+" (v1beta1)")
+	fs.StringVar(&c.Task, "task", c.Task, "execute only the named task and its transitive predecessors (v1beta1)")
+	fs.StringVar(&c.TaskOutput, "task-output", c.TaskOutput, "write the output of --task to stdout if \"-\" or to the named directory (v1beta1)")
+	fs.BoolVar(&c.Check, "check", c.Check, "render to a temporary directory, print the files that differ from the write-to directory, and fail if any differ")
+	fs.StringVar(&c.CacheDir, "cache-dir", c.CacheDir, fmt.Sprintf("task result cache directory, empty disables the cache (%s)", holos.CacheDirEnvVar))
+	fs.AddFlagSet(c.Trace.FlagSet())
 	return fs
 }
 
@@ -39,6 +70,7 @@ func NewConfig() Config {
 		TagMap:      make(holos.TagMap),
 		Stderr:      os.Stderr,
 		WriteTo:     os.Getenv(holos.WriteToEnvVar),
+		CacheDir:    holos.DefaultCacheDir(),
 	}
 	if cfg.WriteTo == "" {
 		cfg.WriteTo = holos.WriteToDefault
@@ -52,6 +84,9 @@ func NewCommand(cfg Config) *cobra.Command {
 	cmd.Args = cobra.ExactArgs(1)
 	cmd.Short = "render a platform component"
 	cmd.Flags().AddFlagSet(cfg.flagSet())
+	cmd.MarkFlagsMutuallyExclusive("task", "task-selector")
+	cmd.MarkFlagsMutuallyExclusive("check", "plan")
+	cmd.MarkFlagsMutuallyExclusive("check", "task-output")
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Root().Context()
 		// TODO(jjm): Handle fully qualified paths for tests where cwd != tempdir
@@ -60,7 +95,17 @@ func NewCommand(cfg Config) *cobra.Command {
 			return errors.Wrap(err)
 		}
 		component := New(root, args[0])
-		return component.Render(ctx, cfg.WriteTo, cmd.ErrOrStderr(), cfg.Concurrency, cfg.TagMap)
+		component.CacheDir = cfg.CacheDir
+		component.Plan = cfg.Plan
+		component.KeepGoing = cfg.KeepGoing
+		component.TaskSelectors = cfg.TaskSelectors
+		component.Task = cfg.Task
+		component.TaskOutput = cfg.TaskOutput
+		component.Check = cfg.Check
+		component.Stdout = cmd.OutOrStdout()
+		return cfg.Trace.Run(ctx, cmd.ErrOrStderr(), func(ctx context.Context) error {
+			return component.Render(ctx, cfg.WriteTo, cmd.ErrOrStderr(), cfg.Concurrency, cfg.TagMap)
+		})
 	}
 	return cmd
 }
@@ -32,7 +32,7 @@ func TestComponents(t *testing.T) {
 		})
 
 		t.Run("BuildPlan", func(t *testing.T) {
-			bp, err := c.BuildPlan(tm, holos.NewBuildOpts(h.Root(), leaf, "deploy", t.TempDir()), holos.TagMap{})
+			bp, err := c.BuildPlan(h.Ctx(), tm, holos.NewBuildOpts(h.Root(), leaf, "deploy", t.TempDir()), holos.TagMap{})
 			require.NoError(t, err, msg)
 			err = bp.Build(h.Ctx())
 			require.NoError(t, err, msg)
@@ -56,7 +56,7 @@ func TestComponents(t *testing.T) {
 				require.NoError(t, err, msg)
 				assert.Equal(t, apiVersion, tm.APIVersion, msg)
 
-				_, err = c.BuildPlan(tm, holos.NewBuildOpts(h.Root(), leaf, "deploy", t.TempDir()), holos.TagMap{})
+				_, err = c.BuildPlan(h.Ctx(), tm, holos.NewBuildOpts(h.Root(), leaf, "deploy", t.TempDir()), holos.TagMap{})
 				require.Error(t, err, msg)
 				assert.ErrorContains(t, err, "repository.url", msg)
 				assert.ErrorContains(t, err, "required", msg)
@@ -73,7 +73,7 @@ func TestComponents(t *testing.T) {
 				require.NoError(t, err, msg)
 				assert.Equal(t, apiVersion, tm.APIVersion, msg)
 
-				_, err = c.BuildPlan(tm, holos.NewBuildOpts(h.Root(), leaf, "deploy", t.TempDir()), holos.TagMap{})
+				_, err = c.BuildPlan(h.Ctx(), tm, holos.NewBuildOpts(h.Root(), leaf, "deploy", t.TempDir()), holos.TagMap{})
 				require.Error(t, err, msg)
 				assert.ErrorContains(t, err, "repository.url", msg)
 				assert.ErrorContains(t, err, "not allowed", msg)
@@ -103,7 +103,7 @@ func TestComponents(t *testing.T) {
 					assert.Equal(t, tm.APIVersion, apiVersion)
 
 					t.Run("Build", func(t *testing.T) {
-						bp, err := c.BuildPlan(tm, holos.NewBuildOpts(h.Root(), leaf, "deploy", t.TempDir()), holos.TagMap{})
+						bp, err := c.BuildPlan(h.Ctx(), tm, holos.NewBuildOpts(h.Root(), leaf, "deploy", t.TempDir()), holos.TagMap{})
 						require.NoError(t, err, msg)
 						err = bp.Build(h.Ctx())
 						assert.ErrorContains(t, err, "could not validate", msg)
@@ -128,7 +128,7 @@ func testComponent(t *testing.T, h *testutil.ComponentHarness, kind, name string
 		assert.Equal(t, tm.APIVersion, apiVersion)
 
 		t.Run("Build", func(t *testing.T) {
-			bp, err := c.BuildPlan(tm, holos.NewBuildOpts(h.Root(), leaf, "deploy", t.TempDir()), holos.TagMap{})
+			bp, err := c.BuildPlan(h.Ctx(), tm, holos.NewBuildOpts(h.Root(), leaf, "deploy", t.TempDir()), holos.TagMap{})
 			require.NoError(t, err, msg)
 			err = bp.Build(h.Ctx())
 			require.NoError(t, err, msg)
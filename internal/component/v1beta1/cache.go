@@ -0,0 +1,189 @@
+package v1beta1
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	core "github.com/holos-run/holos/api/core/v1beta1"
+	"github.com/holos-run/holos/internal/errors"
+	"github.com/holos-run/holos/internal/logger"
+	"github.com/holos-run/holos/version"
+)
+
+// tempDirPlaceholder replaces the build temp directory when hashing task
+// config.  The temp directory is random per render, so commands referring to
+// buildContext.tempDir would otherwise never hit the cache.
+const tempDirPlaceholder = "${TEMP_DIR}"
+
+// cacheable reports whether the task result is cached.  Helm, Kustomize, and
+// Command tasks execute external programs and dominate render time.  The
+// remaining kinds are cheaper to execute than to hash.  A command without an
+// output validates, so there is no result to cache.
+func cacheable(task core.Task) bool {
+	switch task.Kind {
+	case "Helm", "Kustomize":
+		return true
+	case "Command":
+		return task.Output != ""
+	default:
+		return false
+	}
+}
+
+// cached executes fn unless the task result is in the cache.  On a hit the
+// output is restored from the cache into the artifact store instead.  On a
+// miss fn executes and the output is added to the cache.  The cache key
+// addresses the kind specific task config, the bytes of the resolved inputs,
+// and the holos version, so any change to these executes fn again.
+//
+// Command tasks must declare everything they read as inputs.  Files a command
+// reads directly from the platform root are not part of the key.
+func (t *taskRunner) cached(ctx context.Context, fn func(context.Context) error) error {
+	if t.opts.CacheDir == "" || !cacheable(t.task) {
+		return fn(ctx)
+	}
+	log := logger.FromContext(ctx)
+
+	key, err := t.cacheKey(ctx)
+	if err != nil {
+		return errors.Format("could not compute cache key: %w", err)
+	}
+	output := string(t.task.Output)
+	entry := filepath.Join(t.opts.CacheDir, "tasks", key[:2], key)
+
+	if _, err := os.Stat(filepath.Join(entry, output)); err == nil {
+		if err := t.opts.Store.Load(entry, output); err != nil {
+			return errors.Format("could not restore %s from cache: %w", output, err)
+		}
+		log.DebugContext(ctx, fmt.Sprintf("task %s cache hit %s", t.id(), key), "key", key)
+		return nil
+	}
+	log.DebugContext(ctx, fmt.Sprintf("task %s cache miss %s", t.id(), key), "key", key)
+
+	if err := fn(ctx); err != nil {
+		return err
+	}
+
+	// Failing to populate the cache does not fail the task.
+	if err := t.saveCache(entry, output); err != nil {
+		log.WarnContext(ctx, fmt.Sprintf("could not cache %s: %s", t.id(), err), "key", key, "err", err)
+	}
+	return nil
+}
+
+// saveCache saves the output from the artifact store into the cache entry.
+// The entry is staged in a sibling directory then renamed into place so
+// concurrent holos processes never observe a partial entry.
+func (t *taskRunner) saveCache(entry, output string) error {
+	parent := filepath.Dir(entry)
+	if err := os.MkdirAll(parent, 0o777); err != nil {
+		return errors.Wrap(err)
+	}
+	stage, err := os.MkdirTemp(parent, ".tmp")
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	if err := t.opts.Store.Save(stage, output); err != nil {
+		_ = os.RemoveAll(stage)
+		return errors.Wrap(err)
+	}
+	if err := os.Rename(stage, entry); err != nil {
+		_ = os.RemoveAll(stage)
+		// Another process populated the same entry first.
+		if _, statErr := os.Stat(entry); statErr == nil {
+			return nil
+		}
+		return errors.Wrap(err)
+	}
+	return nil
+}
+
+// cacheKey returns the hex encoded sha256 content address of the task result.
+func (t *taskRunner) cacheKey(ctx context.Context) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "holos %s\n", version.GetVersion())
+
+	// Kind specific config.  Only the config of the task kind is hashed, so
+	// unrelated fields such as dependsOn do not invalidate the result.
+	var config any
+	switch t.task.Kind {
+	case "Helm":
+		config = t.task.Helm
+	case "Kustomize":
+		config = t.task.Kustomize
+	case "Command":
+		config = t.task.Command
+	}
+	data, err := json.Marshal(struct {
+		Kind   string                     `json:"kind"`
+		Inputs []core.FileOrDirectoryPath `json:"inputs"`
+		Output core.FileOrDirectoryPath   `json:"output"`
+		Config any                        `json:"config"`
+	}{t.task.Kind, t.task.Inputs, t.task.Output, config})
+	if err != nil {
+		return "", errors.Wrap(err)
+	}
+	if tempDir := t.opts.TempDir(); tempDir != "" {
+		data = bytes.ReplaceAll(data, []byte(tempDir), []byte(tempDirPlaceholder))
+	}
+	fmt.Fprintf(h, "config %d\n", len(data))
+	h.Write(data)
+
+	// Resolved input bytes from the artifact store.
+	keys := t.opts.Store.Keys()
+	sort.Strings(keys)
+	for _, input := range t.task.Inputs {
+		path := string(input)
+		for _, key := range keys {
+			if key != path && !strings.HasPrefix(key, path+"/") {
+				continue
+			}
+			data, _ := t.opts.Store.Get(key)
+			fmt.Fprintf(h, "input %s %d\n", key, len(data))
+			h.Write(data)
+		}
+	}
+
+	// The helm chart is an input read from the vendor directory.
+	if t.task.Kind == "Helm" {
+		chart, err := t.helmChart(ctx)
+		if err != nil {
+			return "", errors.Wrap(err)
+		}
+		if err := hashTree(h, "chart", chart); err != nil {
+			return "", errors.Wrap(err)
+		}
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// hashTree writes every regular file under dir into h in lexical order.
+func hashTree(h hash.Hash, label, dir string) error {
+	fsys := os.DirFS(dir)
+	return fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s %s %d\n", label, path, len(data))
+		h.Write(data)
+		return nil
+	})
+}
@@ -0,0 +1,151 @@
+package v1beta1
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	core "github.com/holos-run/holos/api/core/v1beta1"
+	"github.com/holos-run/holos/internal/holos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingCommand returns a Command task copying a.gen.yaml to the output and
+// appending one line to runs.log in the platform root each time it executes.
+func countingCommand() core.Task {
+	return core.Task{
+		Kind:   "Command",
+		Inputs: []core.FileOrDirectoryPath{"a.gen.yaml"},
+		Output: "copy.gen.yaml",
+		Command: core.Command{
+			Args:           []string{"sh", "-c", "echo run >> runs.log && cat"},
+			Stdin:          "a.gen.yaml",
+			IsStdoutOutput: true,
+		},
+	}
+}
+
+// rebuild returns a TaskSet sharing the platform root and cache directory of
+// b with a fresh artifact store and build temp directory, simulating a second
+// render of the same component.
+func rebuild(t *testing.T, b *TaskSet, tasks map[string]core.Task) *TaskSet {
+	t.Helper()
+	opts := holos.NewBuildOpts(b.Opts.Root(), b.Opts.Leaf(), "deploy", t.TempDir())
+	opts.CacheDir = b.Opts.CacheDir
+	return &TaskSet{TaskSet: core.TaskSet{
+		APIVersion: b.APIVersion,
+		Kind:       b.Kind,
+		Metadata:   b.Metadata,
+		Spec:       core.TaskSetSpec{Tasks: tasks},
+	}, Opts: opts}
+}
+
+func runs(t *testing.T, b *TaskSet) int {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(b.Opts.Root(), "runs.log"))
+	if os.IsNotExist(err) {
+		return 0
+	}
+	require.NoError(t, err)
+	return strings.Count(string(data), "run\n")
+}
+
+func TestBuildTaskCache(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test depends on the sh command")
+	}
+	tasks := map[string]core.Task{
+		"gen":  resourcesTask("a", "a.gen.yaml"),
+		"copy": countingCommand(),
+	}
+
+	t.Run("Hit", func(t *testing.T) {
+		b := newTestTaskSet(t, tasks)
+		b.Opts.CacheDir = t.TempDir()
+		require.NoError(t, b.Build(t.Context()))
+		assert.Equal(t, 1, runs(t, b))
+
+		// The temp directory differs, the config and inputs do not.
+		b2 := rebuild(t, b, tasks)
+		require.NoError(t, b2.Build(t.Context()))
+		assert.Equal(t, 1, runs(t, b2), "expected cache hit")
+
+		want, ok := b.Opts.Store.Get("copy.gen.yaml")
+		require.True(t, ok)
+		have, ok := b2.Opts.Store.Get("copy.gen.yaml")
+		require.True(t, ok, "expected output restored from cache")
+		assert.Equal(t, string(want), string(have))
+	})
+
+	t.Run("InputChanged", func(t *testing.T) {
+		b := newTestTaskSet(t, tasks)
+		b.Opts.CacheDir = t.TempDir()
+		require.NoError(t, b.Build(t.Context()))
+
+		b2 := rebuild(t, b, map[string]core.Task{
+			"gen":  resourcesTask("b", "a.gen.yaml"),
+			"copy": countingCommand(),
+		})
+		require.NoError(t, b2.Build(t.Context()))
+		assert.Equal(t, 2, runs(t, b2), "expected cache miss")
+	})
+
+	t.Run("ConfigChanged", func(t *testing.T) {
+		b := newTestTaskSet(t, tasks)
+		b.Opts.CacheDir = t.TempDir()
+		require.NoError(t, b.Build(t.Context()))
+
+		task := countingCommand()
+		task.Command.Args = append(task.Command.Args, "--")
+		b2 := rebuild(t, b, map[string]core.Task{
+			"gen":  resourcesTask("a", "a.gen.yaml"),
+			"copy": task,
+		})
+		require.NoError(t, b2.Build(t.Context()))
+		assert.Equal(t, 2, runs(t, b2), "expected cache miss")
+	})
+
+	t.Run("DependsOnIgnored", func(t *testing.T) {
+		b := newTestTaskSet(t, tasks)
+		b.Opts.CacheDir = t.TempDir()
+		require.NoError(t, b.Build(t.Context()))
+
+		task := countingCommand()
+		task.DependsOn = map[string]core.Dependency{"gen": {}}
+		b2 := rebuild(t, b, map[string]core.Task{
+			"gen":  resourcesTask("a", "a.gen.yaml"),
+			"copy": task,
+		})
+		require.NoError(t, b2.Build(t.Context()))
+		assert.Equal(t, 1, runs(t, b2), "expected cache hit")
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		b := newTestTaskSet(t, tasks)
+		require.NoError(t, b.Build(t.Context()))
+		b2 := rebuild(t, b, tasks)
+		require.NoError(t, b2.Build(t.Context()))
+		assert.Equal(t, 2, runs(t, b2), "expected no caching without a cache dir")
+	})
+
+	t.Run("Validator", func(t *testing.T) {
+		// A command without an output has no result to restore.
+		validate := map[string]core.Task{
+			"gen": resourcesTask("a", "a.gen.yaml"),
+			"validate": {
+				Kind:    "Command",
+				Inputs:  []core.FileOrDirectoryPath{"a.gen.yaml"},
+				Command: core.Command{Args: []string{"sh", "-c", "echo run >> runs.log"}},
+			},
+		}
+		b := newTestTaskSet(t, validate)
+		b.Opts.CacheDir = t.TempDir()
+		require.NoError(t, b.Build(t.Context()))
+		b2 := rebuild(t, b, validate)
+		require.NoError(t, b2.Build(t.Context()))
+		assert.Equal(t, 2, runs(t, b2), "expected validators to always execute")
+	})
+}
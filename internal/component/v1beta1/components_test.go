@@ -37,7 +37,7 @@ func TestComponents(t *testing.T) {
 		})
 
 		t.Run("Build", func(t *testing.T) {
-			bp, err := c.BuildPlan(tm, holos.NewBuildOpts(h.Root(), leaf, "deploy", t.TempDir()), holos.TagMap{})
+			bp, err := c.BuildPlan(h.Ctx(), tm, holos.NewBuildOpts(h.Root(), leaf, "deploy", t.TempDir()), holos.TagMap{})
 			require.NoError(t, err, msg)
 			err = bp.Build(h.Ctx())
 			require.NoError(t, err, msg)
@@ -69,7 +69,7 @@ func TestComponents(t *testing.T) {
 						// Capture stderr to assert on the policy rejection.
 						var stderr bytes.Buffer
 						opts.Stderr = &stderr
-						bp, err := c.BuildPlan(tm, opts, holos.TagMap{})
+						bp, err := c.BuildPlan(h.Ctx(), tm, opts, holos.TagMap{})
 						require.NoError(t, err, msg)
 						err = bp.Build(h.Ctx())
 						assert.ErrorContains(t, err, "could not run command", msg)
@@ -97,7 +97,7 @@ func testComponent(t *testing.T, h *testutil.ComponentHarness, kind, name string
 		assert.Equal(t, apiVersion, tm.APIVersion)
 
 		t.Run("Build", func(t *testing.T) {
-			bp, err := c.BuildPlan(tm, holos.NewBuildOpts(h.Root(), leaf, "deploy", t.TempDir()), holos.TagMap{})
+			bp, err := c.BuildPlan(h.Ctx(), tm, holos.NewBuildOpts(h.Root(), leaf, "deploy", t.TempDir()), holos.TagMap{})
 			require.NoError(t, err, msg)
 			err = bp.Build(h.Ctx())
 			require.NoError(t, err, msg)
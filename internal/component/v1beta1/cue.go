@@ -0,0 +1,148 @@
+package v1beta1
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"sync"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+	"cuelang.org/go/cue/interpreter/embed"
+	"cuelang.org/go/cue/load"
+	"github.com/holos-run/holos/internal/errors"
+	"github.com/holos-run/holos/internal/util"
+	"gopkg.in/yaml.v3"
+)
+
+// cue context and loading is not safe for concurrent use.
+var cueMutex sync.Mutex
+
+// resourceKeys returns the GVK and namespaced name keys of resource r,
+// returning an error if a field contains a "/" beyond the group separator of
+// the apiVersion.
+func resourceKeys(r map[string]any) (gvk string, name string, err error) {
+	key := newOrderKey(r)
+	if key.apiVersion == "" || key.kind == "" || key.name == "" {
+		return "", "", errors.Format("resource must have an apiVersion, kind, and metadata.name")
+	}
+	if group, _, ok := strings.Cut(key.apiVersion, "/"); strings.Count(key.apiVersion, "/") > 1 || (ok && group == "") {
+		return "", "", errors.Format("invalid apiVersion %q", key.apiVersion)
+	}
+	for _, field := range [][2]string{{"kind", key.kind}, {"metadata.namespace", key.namespace}, {"metadata.name", key.name}} {
+		if strings.Contains(field[1], "/") {
+			return "", "", errors.Format("invalid %s %q: must not contain /", field[0], field[1])
+		}
+	}
+	gvk = key.apiVersion + "/" + key.kind
+	name = key.name
+	if key.namespace != "" {
+		name = key.namespace + "/" + key.name
+	}
+	return gvk, name, nil
+}
+
+// loadResources returns the resources of the task inputs keyed by store path,
+// GVK, and namespaced name.  Each file of a directory input is keyed by its own
+// store path.
+func (t *taskRunner) loadResources() (map[string]any, error) {
+	store := t.opts.Store
+	keys := store.Keys()
+	slices.Sort(keys)
+	resources := make(map[string]any)
+	for _, input := range t.task.Inputs {
+		path := string(input)
+		for _, key := range keys {
+			if key != path && !strings.HasPrefix(key, path+"/") {
+				continue
+			}
+			data, _ := store.Get(key)
+			docs, err := splitDocuments(data)
+			if err != nil {
+				return nil, errors.Format("could not load %s: %w", key, err)
+			}
+			file := make(map[string]map[string]any)
+			// index holds the document index of each key to report duplicates.
+			index := make(map[string]int)
+			for idx, doc := range docs {
+				gvk, name, err := resourceKeys(doc.resource)
+				if err != nil {
+					return nil, errors.Format("could not load %s: document %d: %w", key, idx, err)
+				}
+				if prev, ok := index[gvk+" "+name]; ok {
+					return nil, errors.Format("duplicate resource: %s: %s %s: document %d duplicates document %d", key, gvk, name, idx, prev)
+				}
+				index[gvk+" "+name] = idx
+				if file[gvk] == nil {
+					file[gvk] = make(map[string]any)
+				}
+				file[gvk][name] = doc.resource
+			}
+			resources[key] = file
+		}
+	}
+	return resources, nil
+}
+
+// cue unifies the resources of the task inputs with a cue package from the
+// platform module, then validates the package value.  If the task declares an
+// output, cue exports the expression evaluated in the scope of the package
+// value to the output.
+func (t *taskRunner) cue() error {
+	c := t.task.CUE
+	resources, err := t.loadResources()
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	cueMutex.Lock()
+	defer cueMutex.Unlock()
+
+	root := t.opts.Root()
+	cfg := &load.Config{Dir: root, ModuleRoot: root}
+	ctxt := cuecontext.New(cuecontext.Interpreter(embed.New()))
+	values, err := ctxt.BuildInstances(load.Instances([]string{util.DotSlash(string(c.Package))}, cfg))
+	if err != nil {
+		return errors.Format("could not load package %s: %w", c.Package, err)
+	}
+
+	value := values[0].FillPath(cue.ParsePath("resources"), resources)
+	if err := value.Validate(); err != nil {
+		return errors.Format("package %s: %w", c.Package, err)
+	}
+	if t.task.Output == "" {
+		return nil
+	}
+
+	out := ctxt.CompileString(c.Expression, cue.Scope(value), cue.InferBuiltins(true))
+	if err := out.Validate(cue.Concrete(true)); err != nil {
+		return errors.Format("could not evaluate %s: %w", c.Expression, err)
+	}
+	data, err := out.MarshalJSON()
+	if err != nil {
+		return errors.Format("could not export %s: %w", c.Expression, err)
+	}
+	var v any
+	if err := decodeJSON(data, &v); err != nil {
+		return errors.Format("could not decode %s: %w", c.Expression, err)
+	}
+	docs, ok := v.([]any)
+	if !ok {
+		docs = []any{v}
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	for _, doc := range docs {
+		if err := encoder.Encode(doc); err != nil {
+			return errors.Format("could not encode %s: %w", c.Expression, err)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return errors.Wrap(err)
+	}
+	if err := t.opts.Store.Set(string(t.task.Output), buf.Bytes()); err != nil {
+		return errors.Wrap(err)
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package v1beta1
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	core "github.com/holos-run/holos/api/core/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceKeys(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		resource map[string]any
+		gvk      string
+		key      string
+		errText  string
+	}{
+		{
+			name:     "Namespaced",
+			resource: map[string]any{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": map[string]any{"name": "web", "namespace": "prod"}},
+			gvk:      "apps/v1/Deployment",
+			key:      "prod/web",
+		},
+		{
+			name:     "ClusterScoped",
+			resource: map[string]any{"apiVersion": "v1", "kind": "Namespace", "metadata": map[string]any{"name": "prod"}},
+			gvk:      "v1/Namespace",
+			key:      "prod",
+		},
+		{
+			name:     "InvalidAPIVersion",
+			resource: map[string]any{"apiVersion": "a/b/c", "kind": "Thing", "metadata": map[string]any{"name": "x"}},
+			errText:  `invalid apiVersion "a/b/c"`,
+		},
+		{
+			name:     "InvalidName",
+			resource: map[string]any{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]any{"name": "a/b"}},
+			errText:  `invalid metadata.name "a/b": must not contain /`,
+		},
+		{
+			name:     "MissingName",
+			resource: map[string]any{"apiVersion": "v1", "kind": "ConfigMap"},
+			errText:  "resource must have an apiVersion, kind, and metadata.name",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			gvk, key, err := resourceKeys(tc.resource)
+			if tc.errText != "" {
+				assert.ErrorContains(t, err, tc.errText)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.gvk, gvk)
+			assert.Equal(t, tc.key, key)
+		})
+	}
+}
+
+func TestBuildCUE(t *testing.T) {
+	manifests := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web
+  namespace: prod
+data:
+  replicas: "3"
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: web
+  namespace: prod
+`
+	policy := `package policy
+
+// Secrets are forbidden.
+resources: [_]: "v1/Secret"?: [_]: kind: "Forbidden: use an ExternalSecret"
+`
+	transform := `package transform
+
+resources: [_]: [_]: [_]: metadata: labels: "app.kubernetes.io/managed-by": "holos"
+configMaps: [for f in resources for gvk, r in f if gvk == "v1/ConfigMap" for x in r {x}]
+`
+	newTaskSet := func(t *testing.T, tasks map[string]core.Task) *TaskSet {
+		t.Helper()
+		tasks["gen"] = core.Task{Kind: "File", File: core.File{Source: "manifests.yaml"}, Output: "manifests.yaml"}
+		b := newTestTaskSet(t, tasks)
+		files := map[string]string{
+			"cue.mod/module.cue":             "module: \"holos.example\"\nlanguage: version: \"v0.11.1\"\n",
+			"policy/policy.cue":              policy,
+			"transform/transform.cue":        transform,
+			"components/test/manifests.yaml": manifests,
+		}
+		for path, content := range files {
+			path = filepath.Join(b.Opts.Root(), path)
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o777))
+			require.NoError(t, os.WriteFile(path, []byte(content), 0o666))
+		}
+		return b
+	}
+
+	t.Run("Validate", func(t *testing.T) {
+		b := newTaskSet(t, map[string]core.Task{
+			"policy": {
+				Kind:   "CUE",
+				Inputs: []core.FileOrDirectoryPath{"manifests.yaml"},
+				CUE:    core.CUE{Package: "policy"},
+			},
+		})
+		err := b.Build(t.Context())
+		require.Error(t, err)
+		assert.ErrorContains(t, err, `resources."manifests.yaml"."v1/Secret"."prod/web".kind: conflicting values "Forbidden: use an ExternalSecret" and "Secret"`)
+	})
+
+	t.Run("Export", func(t *testing.T) {
+		b := newTaskSet(t, map[string]core.Task{
+			"transform": {
+				Kind:   "CUE",
+				Inputs: []core.FileOrDirectoryPath{"manifests.yaml"},
+				Output: "configmaps.gen.yaml",
+				CUE:    core.CUE{Package: "transform", Expression: "configMaps"},
+			},
+		})
+		require.NoError(t, b.Build(t.Context()))
+		data, ok := b.Opts.Store.Get("configmaps.gen.yaml")
+		require.True(t, ok)
+		assert.Equal(t, `apiVersion: v1
+data:
+    replicas: "3"
+kind: ConfigMap
+metadata:
+    labels:
+        app.kubernetes.io/managed-by: holos
+    name: web
+    namespace: prod
+`, string(data))
+	})
+
+	t.Run("Duplicate", func(t *testing.T) {
+		b := newTaskSet(t, map[string]core.Task{
+			"join": {
+				Kind:   "Join",
+				Inputs: []core.FileOrDirectoryPath{"manifests.yaml", "manifests.yaml"},
+				Output: "joined.yaml",
+				Join:   core.Join{Separator: "---\n"},
+			},
+			"policy": {
+				Kind:   "CUE",
+				Inputs: []core.FileOrDirectoryPath{"joined.yaml"},
+				CUE:    core.CUE{Package: "transform"},
+			},
+		})
+		err := b.Build(t.Context())
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "duplicate resource: joined.yaml: v1/ConfigMap prod/web: document 2 duplicates document 0")
+	})
+}
@@ -0,0 +1,153 @@
+package v1beta1
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/holos-run/holos/internal/errors"
+)
+
+// Graph represents a [Plan] as nodes and edges for diagrams.  Files read from
+// a component directory are nodes alongside tasks so every data-flow edge has
+// a source.
+type Graph struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// GraphNode represents a task or a file read from a component directory.
+type GraphNode struct {
+	// ID represents the canonical task id, or the file path relative to the
+	// platform root.
+	ID string `json:"id"`
+	// Type is task or file.
+	Type string `json:"type"`
+	// Kind represents the task kind.
+	Kind string `json:"kind,omitempty"`
+	// Artifact represents the final artifact path written by a sink.
+	Artifact string `json:"artifact,omitempty"`
+}
+
+// GraphEdge represents a data-flow edge derived from inputs and outputs, or an
+// explicit dependsOn edge.
+type GraphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Type is input or dependsOn.
+	Type string `json:"type"`
+	// Path represents the input path of a data-flow edge.
+	Path string `json:"path,omitempty"`
+}
+
+// Graph returns the plan as nodes and edges.  Nodes are ordered by first
+// appearance in the plan so diagrams are stable.
+func (p *Plan) Graph() *Graph {
+	g := &Graph{Nodes: make([]GraphNode, 0, len(p.Tasks)), Edges: []GraphEdge{}}
+	files := make(map[string]struct{})
+	for _, task := range p.Tasks {
+		// Opaque nodes have no task set, so leaf is the whole id.
+		leaf, _, _ := strings.Cut(task.ID, ":")
+		for _, input := range task.Inputs {
+			if len(input.Producers) > 0 {
+				continue
+			}
+			file := leaf + "/" + input.Path
+			if _, ok := files[file]; !ok {
+				files[file] = struct{}{}
+				g.Nodes = append(g.Nodes, GraphNode{ID: file, Type: "file"})
+			}
+		}
+		g.Nodes = append(g.Nodes, GraphNode{ID: task.ID, Type: "task", Kind: task.Kind, Artifact: task.Artifact})
+	}
+	for _, task := range p.Tasks {
+		leaf, _, _ := strings.Cut(task.ID, ":")
+		for _, input := range task.Inputs {
+			if len(input.Producers) == 0 {
+				g.Edges = append(g.Edges, GraphEdge{From: leaf + "/" + input.Path, To: task.ID, Type: "input", Path: input.Path})
+				continue
+			}
+			for _, producer := range input.Producers {
+				g.Edges = append(g.Edges, GraphEdge{From: producer, To: task.ID, Type: "input", Path: input.Path})
+			}
+		}
+		for _, dep := range task.DependsOn {
+			g.Edges = append(g.Edges, GraphEdge{From: dep, To: task.ID, Type: "dependsOn"})
+		}
+	}
+	return g
+}
+
+// WriteDOT writes the graph in the Graphviz DOT language.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph tasks {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+	for _, node := range g.Nodes {
+		if node.Type == "file" {
+			fmt.Fprintf(&b, "  %s [shape=note];\n", dotQuote(node.ID))
+			continue
+		}
+		fmt.Fprintf(&b, "  %s [label=%s];\n", dotQuote(node.ID), dotQuote(strings.Join(nodeLabel(node), "\n")))
+	}
+	for _, edge := range g.Edges {
+		if edge.Type == "dependsOn" {
+			fmt.Fprintf(&b, "  %s -> %s [style=dashed, label=\"dependsOn\"];\n", dotQuote(edge.From), dotQuote(edge.To))
+			continue
+		}
+		fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", dotQuote(edge.From), dotQuote(edge.To), dotQuote(edge.Path))
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return errors.Wrap(err)
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart.  Mermaid node ids must
+// be simple identifiers, so nodes are numbered in order and labeled by id.
+func (g *Graph) WriteMermaid(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	ids := make(map[string]string, len(g.Nodes))
+	for idx, node := range g.Nodes {
+		id := fmt.Sprintf("n%d", idx)
+		ids[node.ID] = id
+		if node.Type == "file" {
+			fmt.Fprintf(&b, "  %s[/%s/]\n", id, mermaidQuote(node.ID))
+			continue
+		}
+		fmt.Fprintf(&b, "  %s[%s]\n", id, mermaidQuote(strings.Join(nodeLabel(node), "<br/>")))
+	}
+	for _, edge := range g.Edges {
+		if edge.Type == "dependsOn" {
+			fmt.Fprintf(&b, "  %s -.->|dependsOn| %s\n", ids[edge.From], ids[edge.To])
+			continue
+		}
+		fmt.Fprintf(&b, "  %s -->|%s| %s\n", ids[edge.From], mermaidQuote(edge.Path), ids[edge.To])
+	}
+	_, err := io.WriteString(w, b.String())
+	return errors.Wrap(err)
+}
+
+// nodeLabel returns the lines labeling a task node.
+func nodeLabel(node GraphNode) []string {
+	lines := []string{node.ID, node.Kind}
+	if node.Artifact != "" {
+		lines = append(lines, node.Artifact)
+	}
+	return lines
+}
+
+// dotQuote returns s as a quoted DOT string.  DOT strings accept any UTF-8,
+// so only double quotes and backslashes are escaped and newlines are written
+// as \n line breaks.
+func dotQuote(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return `"` + r.Replace(s) + `"`
+}
+
+// mermaidQuote returns s as a quoted mermaid string, escaping double quotes
+// with the #quot; entity.
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
@@ -0,0 +1,81 @@
+package v1beta1
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// diagramPlan returns a plan with a component directory input, a data-flow
+// edge, a dependsOn edge, and a sink.
+func diagramPlan() *Plan {
+	return &Plan{Tasks: []PlanTask{
+		{ID: "components/a:a/gen", Kind: "Resources"},
+		{ID: "components/a:a/check", Kind: "Command"},
+		{
+			ID:   "components/a:a/combine",
+			Kind: "Join",
+			Inputs: []PlanInput{
+				{Path: "a.gen.yaml", Producers: []string{"components/a:a/gen"}},
+				{Path: "base/local.yaml"},
+			},
+			DependsOn: []string{"components/a:a/check"},
+			Artifact:  "deploy/a.gen.yaml",
+		},
+	}}
+}
+
+func TestPlanGraph(t *testing.T) {
+	g := diagramPlan().Graph()
+	assert.Equal(t, []GraphNode{
+		{ID: "components/a:a/gen", Type: "task", Kind: "Resources"},
+		{ID: "components/a:a/check", Type: "task", Kind: "Command"},
+		{ID: "components/a/base/local.yaml", Type: "file"},
+		{ID: "components/a:a/combine", Type: "task", Kind: "Join", Artifact: "deploy/a.gen.yaml"},
+	}, g.Nodes)
+	assert.Equal(t, []GraphEdge{
+		{From: "components/a:a/gen", To: "components/a:a/combine", Type: "input", Path: "a.gen.yaml"},
+		{From: "components/a/base/local.yaml", To: "components/a:a/combine", Type: "input", Path: "base/local.yaml"},
+		{From: "components/a:a/check", To: "components/a:a/combine", Type: "dependsOn"},
+	}, g.Edges)
+}
+
+func TestGraphWriteDOT(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, diagramPlan().Graph().WriteDOT(&buf))
+	assert.Equal(t, `digraph tasks {
+  rankdir=LR;
+  node [shape=box];
+  "components/a:a/gen" [label="components/a:a/gen\nResources"];
+  "components/a:a/check" [label="components/a:a/check\nCommand"];
+  "components/a/base/local.yaml" [shape=note];
+  "components/a:a/combine" [label="components/a:a/combine\nJoin\ndeploy/a.gen.yaml"];
+  "components/a:a/gen" -> "components/a:a/combine" [label="a.gen.yaml"];
+  "components/a/base/local.yaml" -> "components/a:a/combine" [label="base/local.yaml"];
+  "components/a:a/check" -> "components/a:a/combine" [style=dashed, label="dependsOn"];
+}
+`, buf.String())
+}
+
+func TestDotQuote(t *testing.T) {
+	// Non-ASCII and control characters other than newline pass through
+	// unescaped, DOT has no \u or \x escapes.
+	assert.Equal(t, "\"café/ü\tx\"", dotQuote("café/ü\tx"))
+	assert.Equal(t, `"a\"b\\c\nd"`, dotQuote("a\"b\\c\nd"))
+}
+
+func TestGraphWriteMermaid(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, diagramPlan().Graph().WriteMermaid(&buf))
+	assert.Equal(t, `flowchart LR
+  n0["components/a:a/gen<br/>Resources"]
+  n1["components/a:a/check<br/>Command"]
+  n2[/"components/a/base/local.yaml"/]
+  n3["components/a:a/combine<br/>Join<br/>deploy/a.gen.yaml"]
+  n0 -->|"a.gen.yaml"| n3
+  n2 -->|"base/local.yaml"| n3
+  n1 -.->|dependsOn| n3
+`, buf.String())
+}
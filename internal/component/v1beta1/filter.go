@@ -0,0 +1,150 @@
+package v1beta1
+
+import (
+	"path"
+
+	core "github.com/holos-run/holos/api/core/v1beta1"
+	"github.com/holos-run/holos/internal/errors"
+	"github.com/holos-run/holos/internal/holos"
+)
+
+// resourceSelector represents a parsed [core.ResourceSelector].
+type resourceSelector struct {
+	core.ResourceSelector
+	// labels is nil when LabelSelector is empty, selecting every resource.
+	labels *holos.Selector
+}
+
+// newResourceSelector parses s, returning an error if the name or namespace
+// pattern or the label selector is malformed.
+func newResourceSelector(s core.ResourceSelector) (resourceSelector, error) {
+	sel := resourceSelector{ResourceSelector: s}
+	if _, err := path.Match(s.Name, ""); err != nil {
+		return sel, errors.Format("invalid name pattern %q: %w", s.Name, err)
+	}
+	if _, err := path.Match(s.Namespace, ""); err != nil {
+		return sel, errors.Format("invalid namespace pattern %q: %w", s.Namespace, err)
+	}
+	if s.LabelSelector != "" {
+		sel.labels = &holos.Selector{}
+		if err := sel.labels.Set(s.LabelSelector); err != nil {
+			return sel, errors.Format("invalid label selector %q: %w", s.LabelSelector, err)
+		}
+	}
+	return sel, nil
+}
+
+// matches returns true if every non-empty field of the selector matches r.
+func (s resourceSelector) matches(r map[string]any) bool {
+	apiVersion, _ := r["apiVersion"].(string)
+	kind, _ := r["kind"].(string)
+	metadata, _ := r["metadata"].(map[string]any)
+	name, _ := metadata["name"].(string)
+	namespace, _ := metadata["namespace"].(string)
+
+	if s.APIVersion != "" && s.APIVersion != apiVersion {
+		return false
+	}
+	if s.Kind != "" && s.Kind != kind {
+		return false
+	}
+	if s.Name != "" {
+		if ok, _ := path.Match(s.Name, name); !ok {
+			return false
+		}
+	}
+	if s.Namespace != "" {
+		if ok, _ := path.Match(s.Namespace, namespace); !ok {
+			return false
+		}
+	}
+	if s.labels != nil {
+		labels := holos.Labels{}
+		if m, ok := metadata["labels"].(map[string]any); ok {
+			for k, v := range m {
+				if str, ok := v.(string); ok {
+					labels[k] = str
+				}
+			}
+		}
+		if !s.labels.IsSelected(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// resourceFilter represents the parsed selectors of a [core.Filter].
+type resourceFilter struct {
+	include []resourceSelector
+	exclude []resourceSelector
+}
+
+// newResourceFilter parses the selectors of f.
+func newResourceFilter(f core.Filter) (resourceFilter, error) {
+	var rf resourceFilter
+	for idx, s := range f.Include {
+		sel, err := newResourceSelector(s)
+		if err != nil {
+			return rf, errors.Format("include %d: %w", idx, err)
+		}
+		rf.include = append(rf.include, sel)
+	}
+	for idx, s := range f.Exclude {
+		sel, err := newResourceSelector(s)
+		if err != nil {
+			return rf, errors.Format("exclude %d: %w", idx, err)
+		}
+		rf.exclude = append(rf.exclude, sel)
+	}
+	return rf, nil
+}
+
+// keep returns true if r matches any include selector, or no include
+// selectors are given, and r matches no exclude selector.
+func (f resourceFilter) keep(r map[string]any) bool {
+	for _, s := range f.exclude {
+		if s.matches(r) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, s := range f.include {
+		if s.matches(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// filter keeps or drops the resources of the task inputs in-process, writing
+// the kept documents verbatim to the output.
+func (t *taskRunner) filter() error {
+	rf, err := newResourceFilter(t.task.Filter)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	store := t.opts.Store
+	var kept []document
+	for _, input := range t.task.Inputs {
+		data, ok := store.Get(string(input))
+		if !ok {
+			return errors.Format("missing input %s", input)
+		}
+		docs, err := splitDocuments(data)
+		if err != nil {
+			return errors.Format("could not filter %s: %w", input, err)
+		}
+		for _, doc := range docs {
+			if rf.keep(doc.resource) {
+				kept = append(kept, doc)
+			}
+		}
+	}
+	if err := store.Set(string(t.task.Output), joinDocuments(kept)); err != nil {
+		return errors.Wrap(err)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package v1beta1
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	core "github.com/holos-run/holos/api/core/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceFilter(t *testing.T) {
+	pod := func(namespace, name string, labels map[string]any) map[string]any {
+		return map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata":   map[string]any{"name": name, "namespace": namespace, "labels": labels},
+		}
+	}
+	resources := map[string]map[string]any{
+		"web":      pod("prod", "web", map[string]any{"app": "web", "tier": "frontend"}),
+		"web-test": pod("prod", "web-test-connection", map[string]any{"app": "web", "helm.sh/hook": "test"}),
+		"dev":      pod("dev", "web", map[string]any{"app": "web"}),
+		"psp": {
+			"apiVersion": "policy/v1beta1",
+			"kind":       "PodSecurityPolicy",
+			"metadata":   map[string]any{"name": "restricted"},
+		},
+	}
+
+	for _, tc := range []struct {
+		name   string
+		filter core.Filter
+		want   []string
+	}{
+		{
+			name: "Empty",
+			want: []string{"dev", "psp", "web", "web-test"},
+		},
+		{
+			name:   "ExcludeKind",
+			filter: core.Filter{Exclude: []core.ResourceSelector{{Kind: "PodSecurityPolicy"}}},
+			want:   []string{"dev", "web", "web-test"},
+		},
+		{
+			name:   "ExcludeNameGlob",
+			filter: core.Filter{Exclude: []core.ResourceSelector{{Kind: "Pod", Name: "*-test-*"}}},
+			want:   []string{"dev", "psp", "web"},
+		},
+		{
+			name:   "IncludeAPIVersionAndNamespace",
+			filter: core.Filter{Include: []core.ResourceSelector{{APIVersion: "v1", Namespace: "p*"}}},
+			want:   []string{"web", "web-test"},
+		},
+		{
+			name:   "IncludeClusterScoped",
+			filter: core.Filter{Include: []core.ResourceSelector{{Namespace: ""}, {Kind: "PodSecurityPolicy"}}},
+			want:   []string{"dev", "psp", "web", "web-test"},
+		},
+		{
+			name:   "LabelSelector",
+			filter: core.Filter{Include: []core.ResourceSelector{{LabelSelector: "app=web,helm.sh/hook!=test"}}},
+			want:   []string{"dev", "web"},
+		},
+		{
+			name: "ExcludeTakesPrecedence",
+			filter: core.Filter{
+				Include: []core.ResourceSelector{{Kind: "Pod"}},
+				Exclude: []core.ResourceSelector{{LabelSelector: "tier=frontend"}, {Namespace: "dev"}},
+			},
+			want: []string{"web-test"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rf, err := newResourceFilter(tc.filter)
+			require.NoError(t, err)
+			var have []string
+			for _, key := range []string{"dev", "psp", "web", "web-test"} {
+				if rf.keep(resources[key]) {
+					have = append(have, key)
+				}
+			}
+			assert.Equal(t, tc.want, have)
+		})
+	}
+}
+
+func TestNewResourceFilterInvalid(t *testing.T) {
+	_, err := newResourceFilter(core.Filter{Exclude: []core.ResourceSelector{{}, {Name: "[web"}}})
+	assert.ErrorContains(t, err, "exclude 1: ")
+	assert.ErrorContains(t, err, `invalid name pattern "[web"`)
+
+	_, err = newResourceFilter(core.Filter{Include: []core.ResourceSelector{{LabelSelector: "app"}}})
+	assert.ErrorContains(t, err, "include 0: ")
+	assert.ErrorContains(t, err, `invalid label selector "app"`)
+}
+
+func TestBuildFilter(t *testing.T) {
+	chart := `# Source: web/templates/pod.yaml
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+---
+# Source: web/templates/tests/test-connection.yaml
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web-test-connection
+  annotations:
+    helm.sh/hook: test
+---
+apiVersion: policy/v1beta1
+kind: PodSecurityPolicy
+metadata:
+  name: web
+`
+	b := newTestTaskSet(t, map[string]core.Task{
+		"chart": {
+			Kind:   "File",
+			File:   core.File{Source: "chart.yaml"},
+			Output: "chart.yaml",
+		},
+		"filter": {
+			Kind:   "Filter",
+			Inputs: []core.FileOrDirectoryPath{"chart.yaml"},
+			Output: "filtered.gen.yaml",
+			Filter: core.Filter{Exclude: []core.ResourceSelector{
+				{Kind: "PodSecurityPolicy"},
+				{Kind: "Pod", Name: "*-test-*"},
+			}},
+		},
+	})
+	require.NoError(t, os.WriteFile(filepath.Join(b.Opts.AbsLeaf(), "chart.yaml"), []byte(chart), 0o666))
+	require.NoError(t, b.Build(t.Context()))
+
+	data, ok := b.Opts.Store.Get("filtered.gen.yaml")
+	require.True(t, ok)
+	assert.Equal(t, `# Source: web/templates/pod.yaml
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+`, string(data))
+}
+
+func TestValidateFilterOnlyForFilter(t *testing.T) {
+	// A stray filter block of another kind is not validated as a filter.
+	stray := core.Filter{Include: []core.ResourceSelector{{LabelSelector: "app"}}}
+	join := core.Task{Kind: "Join", Inputs: []core.FileOrDirectoryPath{"a.yaml"}, Output: "b.yaml", Filter: stray}
+	assert.NoError(t, validateTask("join", join))
+
+	filter := join
+	filter.Kind = "Filter"
+	assert.ErrorContains(t, validateTask("filter", filter), `invalid label selector "app"`)
+}
@@ -0,0 +1,138 @@
+package v1beta1
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	core "github.com/holos-run/holos/api/core/v1beta1"
+	"github.com/holos-run/holos/internal/redact"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// vendorChart writes a minimal chart to the vendor cache of the task set so
+// rendering does not pull from a repository.
+func vendorChart(t *testing.T, b *TaskSet, name string, version string) {
+	t.Helper()
+	files := map[string]string{
+		"Chart.yaml":  "apiVersion: v2\nname: " + name + "\nversion: " + version + "\n",
+		"values.yaml": "replicas: 1\nenv: dev\nlabels:\n  app: web\n",
+		"templates/configmap.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Release.Name }}
+  namespace: {{ .Release.Namespace }}
+  labels:
+    {{- toYaml .Values.labels | nindent 4 }}
+data:
+  env: {{ .Values.env | quote }}
+  replicas: {{ .Values.replicas | quote }}
+`,
+		"templates/hook.yaml": `apiVersion: v1
+kind: Pod
+metadata:
+  name: {{ .Release.Name }}-test
+  annotations:
+    helm.sh/hook: test
+`,
+	}
+	dir := filepath.Join(b.Opts.AbsLeaf(), "vendor", version, name)
+	for path, content := range files {
+		path = filepath.Join(dir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o777))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o666))
+	}
+}
+
+func TestBuildHelm(t *testing.T) {
+	// The helm executable must not be required.
+	t.Setenv("PATH", "")
+
+	helmTask := func(enableHooks bool) core.Task {
+		return core.Task{
+			Kind:   "Helm",
+			Output: "helm.gen.yaml",
+			Helm: core.Helm{
+				Chart:     core.Chart{Name: "example/web", Version: "0.1.0", Release: "prod"},
+				Namespace: "web",
+				ValueFiles: []core.ValueFile{
+					{Name: "region.yaml", Kind: "Values", Values: core.Values{"env": "staging", "replicas": 2, "labels": map[string]any{"tier": "frontend"}}},
+				},
+				Values:      core.Values{"env": "prod"},
+				EnableHooks: enableHooks,
+			},
+		}
+	}
+
+	t.Run("MergesValues", func(t *testing.T) {
+		b := newTestTaskSet(t, map[string]core.Task{"helm": helmTask(false)})
+		vendorChart(t, b, "web", "0.1.0")
+		require.NoError(t, b.Build(t.Context()))
+
+		data, ok := b.Opts.Store.Get("helm.gen.yaml")
+		require.True(t, ok)
+		assert.Equal(t, `---
+# Source: web/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: prod
+  namespace: web
+  labels:
+    app: web
+    tier: frontend
+data:
+  env: "prod"
+  replicas: "2"
+`, string(data))
+	})
+
+	t.Run("EnableHooks", func(t *testing.T) {
+		b := newTestTaskSet(t, map[string]core.Task{"helm": helmTask(true)})
+		vendorChart(t, b, "web", "0.1.0")
+		require.NoError(t, b.Build(t.Context()))
+
+		data, ok := b.Opts.Store.Get("helm.gen.yaml")
+		require.True(t, ok)
+		assert.Contains(t, string(data), "---\n# Source: web/templates/hook.yaml\napiVersion: v1\nkind: Pod\n")
+	})
+
+	t.Run("RenderError", func(t *testing.T) {
+		task := helmTask(false)
+		task.Helm.KubeVersion = "not-a-version"
+		b := newTestTaskSet(t, map[string]core.Task{"helm": task})
+		vendorChart(t, b, "web", "0.1.0")
+		err := b.Build(t.Context())
+		require.Error(t, err)
+		assert.ErrorContains(t, err, `invalid kube version "not-a-version"`)
+	})
+
+	t.Run("RedactsAuth", func(t *testing.T) {
+		t.Cleanup(redact.Reset)
+		t.Setenv("HELM_TEST_PASSWORD", "hunter2")
+		task := helmTask(false)
+		task.Helm.Chart.Repository.Auth = core.Auth{
+			Username: core.AuthSource{Value: "deployer"},
+			Password: core.AuthSource{FromEnv: "HELM_TEST_PASSWORD"},
+		}
+		b := newTestTaskSet(t, map[string]core.Task{"helm": task})
+		vendorChart(t, b, "web", "0.1.0")
+		require.NoError(t, b.Build(t.Context()))
+		assert.Equal(t, "login [REDACTED]:[REDACTED]", redact.String("login deployer:hunter2"))
+	})
+
+	t.Run("ShortUsername", func(t *testing.T) {
+		t.Cleanup(redact.Reset)
+		task := helmTask(false)
+		task.Helm.Chart.Repository.Auth = core.Auth{
+			Username: core.AuthSource{Value: "ci"},
+			Password: core.AuthSource{Value: "hunter2"},
+		}
+		b := newTestTaskSet(t, map[string]core.Task{"helm": task})
+		vendorChart(t, b, "web", "0.1.0")
+		require.NoError(t, b.Build(t.Context()))
+		// A short username is not masked, it would mask common text everywhere.
+		assert.Equal(t, "ci login ci:[REDACTED]", redact.String("ci login ci:hunter2"))
+	})
+}
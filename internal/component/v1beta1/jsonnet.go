@@ -0,0 +1,103 @@
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+
+	"github.com/google/go-jsonnet"
+	core "github.com/holos-run/holos/api/core/v1beta1"
+	"github.com/holos-run/holos/internal/errors"
+)
+
+// newJsonnetVM returns a vm configured with the external variables, top-level
+// arguments, and library search paths of j.  Paths are resolved relative to
+// leaf.
+func newJsonnetVM(j core.Jsonnet, leaf string) (*jsonnet.VM, error) {
+	vm := jsonnet.MakeVM()
+	for _, key := range slices.Sorted(maps.Keys(j.ExtVars)) {
+		code, err := json.Marshal(j.ExtVars[key])
+		if err != nil {
+			return nil, errors.Format("could not encode ext var %s: %w", key, err)
+		}
+		vm.ExtCode(key, string(code))
+	}
+	for _, key := range slices.Sorted(maps.Keys(j.TLAs)) {
+		code, err := json.Marshal(j.TLAs[key])
+		if err != nil {
+			return nil, errors.Format("could not encode tla %s: %w", key, err)
+		}
+		vm.TLACode(key, string(code))
+	}
+	jpaths := make([]string, 0, len(j.JPath))
+	for _, path := range j.JPath {
+		jpaths = append(jpaths, filepath.Join(leaf, string(path)))
+	}
+	vm.Importer(&jsonnet.FileImporter{JPaths: jpaths})
+	return vm, nil
+}
+
+// flattenJsonnet appends the resources of the evaluated value v to list.  path
+// locates v within the evaluated value for error messages.
+func flattenJsonnet(list []core.Resource, v any, path string) ([]core.Resource, error) {
+	switch v := v.(type) {
+	case nil:
+		return list, nil
+	case []any:
+		for idx, item := range v {
+			var err error
+			if list, err = flattenJsonnet(list, item, fmt.Sprintf("%s[%d]", path, idx)); err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	case map[string]any:
+		_, hasAPIVersion := v["apiVersion"]
+		_, hasKind := v["kind"]
+		if hasAPIVersion && hasKind {
+			return append(list, core.Resource(v)), nil
+		}
+		for _, key := range slices.Sorted(maps.Keys(v)) {
+			var err error
+			if list, err = flattenJsonnet(list, v[key], fmt.Sprintf("%s[%q]", path, key)); err != nil {
+				return nil, err
+			}
+		}
+		return list, nil
+	default:
+		return nil, errors.Format("%s: expected a resource, array, or object, got %T", path, v)
+	}
+}
+
+// jsonnet evaluates a jsonnet file from the component directory in-process,
+// writing the resulting resources as a YAML stream to the output.
+func (t *taskRunner) jsonnet() error {
+	j := t.task.Jsonnet
+	leaf := t.opts.AbsLeaf()
+	vm, err := newJsonnetVM(j, leaf)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	out, err := vm.EvaluateFile(filepath.Join(leaf, string(j.Source)))
+	if err != nil {
+		return errors.Format("could not evaluate %s: %w", j.Source, err)
+	}
+	var v any
+	if err := decodeJSON([]byte(out), &v); err != nil {
+		return errors.Format("could not decode %s: %w", j.Source, err)
+	}
+	list, err := flattenJsonnet(nil, v, "$")
+	if err != nil {
+		return errors.Format("could not flatten %s: %w", j.Source, err)
+	}
+	buf, err := marshal(list)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	if err := t.opts.Store.Set(string(t.task.Output), buf.Bytes()); err != nil {
+		return errors.Wrap(err)
+	}
+	return nil
+}
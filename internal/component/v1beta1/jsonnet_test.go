@@ -0,0 +1,87 @@
+package v1beta1
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	core "github.com/holos-run/holos/api/core/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlattenJsonnet(t *testing.T) {
+	cm := func(name string) map[string]any {
+		return map[string]any{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]any{"name": name}}
+	}
+	v := map[string]any{
+		"b": []any{cm("b-0"), nil, cm("b-1")},
+		"a": map[string]any{"z": cm("a-z"), "y": cm("a-y")},
+	}
+	list, err := flattenJsonnet(nil, v, "$")
+	require.NoError(t, err)
+	var names []string
+	for _, r := range list {
+		names = append(names, r["metadata"].(map[string]any)["name"].(string))
+	}
+	assert.Equal(t, []string{"a-y", "a-z", "b-0", "b-1"}, names)
+
+	_, err = flattenJsonnet(nil, map[string]any{"a": []any{cm("ok"), "oops"}}, "$")
+	assert.ErrorContains(t, err, `$["a"][1]: expected a resource, array, or object, got string`)
+}
+
+func TestBuildJsonnet(t *testing.T) {
+	// The jsonnet executable must not be required.
+	t.Setenv("PATH", "")
+
+	files := map[string]string{
+		"vendor/lib/configmap.libsonnet": `{
+  new(name, data):: { apiVersion: 'v1', kind: 'ConfigMap', metadata: { name: name }, data: data },
+}
+`,
+		"main.jsonnet": `local configmap = import 'lib/configmap.libsonnet';
+function(replicas) {
+  config: configmap.new(std.extVar('name'), { env: std.extVar('config').env, replicas: std.toString(replicas) }),
+  namespace: { apiVersion: 'v1', kind: 'Namespace', metadata: { name: std.extVar('config').namespace } },
+}
+`,
+	}
+
+	b := newTestTaskSet(t, map[string]core.Task{
+		"jsonnet": {
+			Kind:   "Jsonnet",
+			Output: "jsonnet.gen.yaml",
+			Jsonnet: core.Jsonnet{
+				Source: "main.jsonnet",
+				ExtVars: map[string]any{
+					"name":   "web",
+					"config": map[string]any{"env": "prod", "namespace": "web"},
+				},
+				TLAs:  map[string]any{"replicas": 3},
+				JPath: []core.FilePath{"vendor"},
+			},
+		},
+	})
+	for path, content := range files {
+		path = filepath.Join(b.Opts.AbsLeaf(), path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o777))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o666))
+	}
+	require.NoError(t, b.Build(t.Context()))
+
+	data, ok := b.Opts.Store.Get("jsonnet.gen.yaml")
+	require.True(t, ok)
+	assert.Equal(t, `apiVersion: v1
+data:
+    env: prod
+    replicas: "3"
+kind: ConfigMap
+metadata:
+    name: web
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+    name: web
+`, string(data))
+}
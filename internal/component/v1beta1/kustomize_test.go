@@ -0,0 +1,159 @@
+package v1beta1
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	core "github.com/holos-run/holos/api/core/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/types"
+)
+
+func TestBuildKustomize(t *testing.T) {
+	// kubectl must not be required.
+	t.Setenv("PATH", "")
+
+	b := newTestTaskSet(t, map[string]core.Task{
+		"gen": resourcesTask("web", "resources.gen.yaml"),
+		"kustomize": {
+			Kind:   "Kustomize",
+			Inputs: []core.FileOrDirectoryPath{"resources.gen.yaml"},
+			Output: "kustomized.gen.yaml",
+			Kustomize: core.Kustomize{
+				Kustomization: core.Kustomization{
+					"resources":  []any{"resources.gen.yaml"},
+					"namePrefix": "prod-",
+					"patches":    []any{map[string]any{"path": "patches/data.yaml"}},
+				},
+				Files: core.FileContentMap{
+					"patches/data.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web\ndata:\n  env: prod\n",
+				},
+			},
+		},
+	})
+	require.NoError(t, b.Build(t.Context()))
+
+	data, ok := b.Opts.Store.Get("kustomized.gen.yaml")
+	require.True(t, ok)
+	assert.Equal(t, `apiVersion: v1
+data:
+  env: prod
+kind: ConfigMap
+metadata:
+  name: prod-web
+`, string(data))
+}
+
+func TestBuildKustomizeLoadRestrictor(t *testing.T) {
+	kustomize := func(restrictor string) core.Task {
+		return core.Task{
+			Kind:   "Kustomize",
+			Inputs: []core.FileOrDirectoryPath{"resources.gen.yaml"},
+			Output: "kustomized.gen.yaml",
+			Kustomize: core.Kustomize{
+				Kustomization: core.Kustomization{"resources": []any{"base"}},
+				// The base references a file outside of its own root.
+				Files: core.FileContentMap{
+					"base/kustomization.yaml": "resources:\n- ../resources.gen.yaml\n",
+				},
+				LoadRestrictor: restrictor,
+			},
+		}
+	}
+
+	b := newTestTaskSet(t, map[string]core.Task{
+		"gen":       resourcesTask("web", "resources.gen.yaml"),
+		"kustomize": kustomize(""),
+	})
+	assert.ErrorContains(t, b.Build(t.Context()), "security; file")
+
+	b = newTestTaskSet(t, map[string]core.Task{
+		"gen":       resourcesTask("web", "resources.gen.yaml"),
+		"kustomize": kustomize("LoadRestrictionsNone"),
+	})
+	require.NoError(t, b.Build(t.Context()))
+	data, ok := b.Opts.Store.Get("kustomized.gen.yaml")
+	require.True(t, ok)
+	assert.Contains(t, string(data), "name: web\n")
+
+	// Without load restrictions the kustomization builds on disk and may load
+	// local files outside of its root.
+	local := filepath.Join(t.TempDir(), "local.yaml")
+	require.NoError(t, os.WriteFile(local, []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: local\n"), 0o666))
+	task := kustomize("LoadRestrictionsNone")
+	task.Kustomize.Kustomization = core.Kustomization{"resources": []any{"base", local}}
+	b = newTestTaskSet(t, map[string]core.Task{
+		"gen":       resourcesTask("web", "resources.gen.yaml"),
+		"kustomize": task,
+	})
+	require.NoError(t, b.Build(t.Context()))
+	data, ok = b.Opts.Store.Get("kustomized.gen.yaml")
+	require.True(t, ok)
+	assert.Contains(t, string(data), "name: local\n")
+
+	// A reference outside of the kustomization root resolves against the
+	// component directory, not a temporary directory.
+	task = kustomize("LoadRestrictionsNone")
+	task.Kustomize.Kustomization = core.Kustomization{"resources": []any{"base", "../shared"}}
+	b = newTestTaskSet(t, map[string]core.Task{
+		"gen":       resourcesTask("web", "resources.gen.yaml"),
+		"kustomize": task,
+	})
+	shared := filepath.Join(b.Opts.AbsLeaf(), "shared")
+	require.NoError(t, os.MkdirAll(shared, 0o777))
+	require.NoError(t, os.WriteFile(filepath.Join(shared, "kustomization.yaml"), []byte("resources:\n- configmap.yaml\n"), 0o666))
+	require.NoError(t, os.WriteFile(filepath.Join(shared, "configmap.yaml"), []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: shared\n"), 0o666))
+	require.NoError(t, b.Build(t.Context()))
+	data, ok = b.Opts.Store.Get("kustomized.gen.yaml")
+	require.True(t, ok)
+	assert.Contains(t, string(data), "name: shared\n")
+	assert.Contains(t, string(data), "name: web\n")
+	entries, err := os.ReadDir(b.Opts.AbsLeaf())
+	require.NoError(t, err)
+	for _, e := range entries {
+		assert.NotContains(t, e.Name(), ".holos.kustomize", "the temporary directory is removed")
+	}
+}
+
+func TestKustomizeOptions(t *testing.T) {
+	opts := kustomizeOptions(core.Kustomize{})
+	assert.Equal(t, krusty.ReorderOptionUnspecified, opts.Reorder)
+	assert.Equal(t, types.LoadRestrictionsRootOnly, opts.LoadRestrictions)
+	assert.Equal(t, types.PluginRestrictionsBuiltinsOnly, opts.PluginConfig.PluginRestrictions)
+	assert.False(t, opts.PluginConfig.HelmConfig.Enabled)
+	assert.Equal(t, "helm", opts.PluginConfig.HelmConfig.Command)
+
+	opts = kustomizeOptions(core.Kustomize{
+		LoadRestrictor:     "LoadRestrictionsNone",
+		EnableHelm:         true,
+		HelmCommand:        "/usr/local/bin/helm",
+		EnableAlphaPlugins: true,
+		EnableExec:         true,
+		Network:            true,
+		Env:                []string{"KEY=value"},
+	})
+	assert.Equal(t, types.LoadRestrictionsNone, opts.LoadRestrictions)
+	assert.Equal(t, types.PluginRestrictionsNone, opts.PluginConfig.PluginRestrictions)
+	assert.True(t, opts.PluginConfig.HelmConfig.Enabled)
+	assert.Equal(t, "/usr/local/bin/helm", opts.PluginConfig.HelmConfig.Command)
+	assert.Equal(t, types.FnPluginLoadingOptions{
+		EnableExec:  true,
+		Network:     true,
+		NetworkName: "bridge",
+		Env:         []string{"KEY=value"},
+	}, opts.PluginConfig.FnpLoadingOptions)
+}
+
+func TestValidateKustomizeOnlyForKustomize(t *testing.T) {
+	// A stray kustomize block of another kind is not validated as kustomize.
+	stray := core.Kustomize{LoadRestrictor: "LoadRestrictionsAny", Files: core.FileContentMap{"../x.yaml": ""}}
+	join := core.Task{Kind: "Join", Inputs: []core.FileOrDirectoryPath{"a.yaml"}, Output: "b.yaml", Kustomize: stray}
+	assert.NoError(t, validateTask("join", join))
+
+	kustomize := join
+	kustomize.Kind = "Kustomize"
+	assert.ErrorContains(t, validateTask("kustomize", kustomize), "unsupported kustomize load restrictor")
+}
@@ -0,0 +1,123 @@
+package v1beta1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/holos-run/holos/internal/errors"
+	"github.com/holos-run/holos/internal/logger"
+	"github.com/holos-run/holos/internal/stage"
+)
+
+// ManifestName represents the name of the render manifest file in the
+// write-to directory.
+const ManifestName = ".holos-manifest.json"
+
+// Manifest represents the files written by a platform render, recorded so the
+// next full render can prune files no longer rendered.
+type Manifest struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// Files represents the sorted, slash separated paths of the rendered files
+	// relative to the write-to directory.
+	Files []string `json:"files"`
+}
+
+// NewManifest returns a manifest of files, sorted and deduplicated.
+func NewManifest(files ...string) *Manifest {
+	files = slices.Clone(files)
+	slices.Sort(files)
+	return &Manifest{
+		APIVersion: "v1beta1",
+		Kind:       "RenderManifest",
+		Files:      slices.Compact(files),
+	}
+}
+
+// ReadManifest reads the manifest from the writeTo directory.  A missing
+// manifest is an empty manifest.
+func ReadManifest(writeTo string) (*Manifest, error) {
+	path := filepath.Join(writeTo, ManifestName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewManifest(), nil
+		}
+		return nil, errors.Wrap(err)
+	}
+	return ParseManifest(path, data)
+}
+
+// ParseManifest parses the manifest content data read from path, for example
+// from a git ref.
+func ParseManifest(path string, data []byte) (*Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, errors.Format("could not read render manifest %s: %w", path, err)
+	}
+	for _, file := range m.Files {
+		if !validLocalPath(file) {
+			return nil, errors.Format("could not read render manifest %s: path %s is not local to the write-to directory", path, file)
+		}
+	}
+	return NewManifest(m.Files...), nil
+}
+
+// Marshal returns the manifest file content.
+func (m *Manifest) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	return append(data, '\n'), nil
+}
+
+// Write writes the manifest to the writeTo directory.
+func (m *Manifest) Write(writeTo string) error {
+	data, err := m.Marshal()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(writeTo, 0o777); err != nil {
+		return errors.Wrap(err)
+	}
+	return errors.Wrap(os.WriteFile(filepath.Join(writeTo, ManifestName), data, 0o666))
+}
+
+// Merge returns a manifest of the files in m or other.
+func (m *Manifest) Merge(other *Manifest) *Manifest {
+	return NewManifest(append(slices.Clone(m.Files), other.Files...)...)
+}
+
+// Stale returns the files in m missing from next.
+func (m *Manifest) Stale(next *Manifest) []string {
+	var stale []string
+	for _, file := range m.Files {
+		if _, found := slices.BinarySearch(next.Files, file); !found {
+			stale = append(stale, file)
+		}
+	}
+	return stale
+}
+
+// Prune removes the stale files from the writeTo directory along with the
+// directories left empty, logging each removed file.  Files already removed
+// are ignored.
+func Prune(ctx context.Context, writeTo string, stale []string) error {
+	log := logger.FromContext(ctx)
+	for _, file := range stale {
+		path := filepath.Join(writeTo, filepath.FromSlash(file))
+		if _, err := os.Lstat(path); err != nil {
+			continue
+		}
+		if err := stage.RemoveFiles(writeTo, []string{file}); err != nil {
+			return errors.Format("could not prune %s: %w", file, err)
+		}
+		log.InfoContext(ctx, fmt.Sprintf("pruned %s", file), "path", path)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package v1beta1
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifest(t *testing.T) {
+	t.Run("RoundTrip", func(t *testing.T) {
+		writeTo := filepath.Join(t.TempDir(), "deploy")
+		m := NewManifest("components/b/b.gen.yaml", "components/a/a.gen.yaml", "components/a/a.gen.yaml")
+		assert.Equal(t, []string{"components/a/a.gen.yaml", "components/b/b.gen.yaml"}, m.Files)
+		require.NoError(t, m.Write(writeTo))
+
+		got, err := ReadManifest(writeTo)
+		require.NoError(t, err)
+		assert.Equal(t, m, got)
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		got, err := ReadManifest(t.TempDir())
+		require.NoError(t, err)
+		assert.Empty(t, got.Files)
+	})
+
+	t.Run("NotLocal", func(t *testing.T) {
+		writeTo := t.TempDir()
+		data := []byte(`{"apiVersion":"v1beta1","kind":"RenderManifest","files":["../etc/passwd"]}`)
+		require.NoError(t, os.WriteFile(filepath.Join(writeTo, ManifestName), data, 0o666))
+		_, err := ReadManifest(writeTo)
+		require.ErrorContains(t, err, "path ../etc/passwd is not local to the write-to directory")
+	})
+
+	t.Run("StaleAndMerge", func(t *testing.T) {
+		prev := NewManifest("a.yaml", "b.yaml", "c/d.yaml")
+		next := NewManifest("b.yaml", "e.yaml")
+		assert.Equal(t, []string{"a.yaml", "c/d.yaml"}, prev.Stale(next))
+		assert.Equal(t, []string{"a.yaml", "b.yaml", "c/d.yaml", "e.yaml"}, prev.Merge(next).Files)
+	})
+}
+
+func TestPrune(t *testing.T) {
+	writeTo := t.TempDir()
+	for _, name := range []string{
+		"components/gone/gone.gen.yaml",
+		"components/gone/manifests/a.yaml",
+		"components/kept/kept.gen.yaml",
+		"components/kept/stale.gen.yaml",
+		"untracked.yaml",
+	} {
+		path := filepath.Join(writeTo, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o777))
+		require.NoError(t, os.WriteFile(path, []byte("{}\n"), 0o666))
+	}
+
+	stale := []string{
+		"components/gone/gone.gen.yaml",
+		"components/gone/manifests/a.yaml",
+		"components/kept/stale.gen.yaml",
+		"components/missing/missing.gen.yaml",
+	}
+	require.NoError(t, Prune(t.Context(), writeTo, stale))
+
+	assert.NoFileExists(t, filepath.Join(writeTo, "components", "kept", "stale.gen.yaml"))
+	assert.FileExists(t, filepath.Join(writeTo, "components", "kept", "kept.gen.yaml"))
+	assert.FileExists(t, filepath.Join(writeTo, "untracked.yaml"))
+	assert.NoDirExists(t, filepath.Join(writeTo, "components", "gone"))
+	assert.DirExists(t, writeTo)
+}
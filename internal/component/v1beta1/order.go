@@ -0,0 +1,194 @@
+package v1beta1
+
+import (
+	"bytes"
+	"cmp"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	core "github.com/holos-run/holos/api/core/v1beta1"
+	"github.com/holos-run/holos/internal/errors"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	orderKind  core.Order = "Kind"
+	orderLabel core.Order = "Label"
+	orderInput core.Order = "Input"
+)
+
+// clusterScoped holds the built-in cluster-scoped kinds sorted ahead of
+// namespaced resources.  Kinds prefixed with Cluster, for example
+// ClusterIssuer, are treated as cluster-scoped too.
+//
+// The table is a heuristic: holos renders without access to a cluster, so the
+// scope of a kind is not discovered.  Cluster-scoped custom resources not
+// prefixed with Cluster sort with namespaced resources.
+var clusterScoped = map[string]struct{}{
+	"APIService":                       {},
+	"CSIDriver":                        {},
+	"CSINode":                          {},
+	"CertificateSigningRequest":        {},
+	"ComponentStatus":                  {},
+	"DeviceClass":                      {},
+	"FlowSchema":                       {},
+	"GatewayClass":                     {},
+	"IPAddress":                        {},
+	"IngressClass":                     {},
+	"MutatingAdmissionPolicy":          {},
+	"MutatingAdmissionPolicyBinding":   {},
+	"MutatingWebhookConfiguration":     {},
+	"Node":                             {},
+	"PersistentVolume":                 {},
+	"PriorityClass":                    {},
+	"PriorityLevelConfiguration":       {},
+	"RuntimeClass":                     {},
+	"ServiceCIDR":                      {},
+	"StorageClass":                     {},
+	"ValidatingAdmissionPolicy":        {},
+	"ValidatingAdmissionPolicyBinding": {},
+	"ValidatingWebhookConfiguration":   {},
+	"VolumeAttachment":                 {},
+	"VolumeAttributesClass":            {},
+}
+
+// defaultSeparator represents the separator between the documents of a
+// manifest stream.
+const defaultSeparator = "---\n"
+
+// documentSeparator matches a YAML document marker line.
+var documentSeparator = regexp.MustCompile(`(?m)^---[ \t]*(#.*)?$\n?`)
+
+// orderKey represents the canonical sort key of one resource.
+type orderKey struct {
+	// group is 0 for namespaces, 1 for custom resource definitions, 2 for
+	// cluster-scoped resources, and 3 for all other resources.
+	group      int
+	kind       string
+	namespace  string
+	name       string
+	apiVersion string
+}
+
+// newOrderKey returns the canonical sort key of a resource.
+func newOrderKey(r map[string]any) orderKey {
+	key := orderKey{group: 3}
+	key.kind, _ = r["kind"].(string)
+	key.apiVersion, _ = r["apiVersion"].(string)
+	if metadata, ok := r["metadata"].(map[string]any); ok {
+		key.namespace, _ = metadata["namespace"].(string)
+		key.name, _ = metadata["name"].(string)
+	}
+	switch _, ok := clusterScoped[key.kind]; {
+	case key.kind == "Namespace":
+		key.group = 0
+	case key.kind == "CustomResourceDefinition":
+		key.group = 1
+	case ok, strings.HasPrefix(key.kind, "Cluster"):
+		key.group = 2
+	}
+	return key
+}
+
+func compareOrderKeys(a, b orderKey) int {
+	return cmp.Or(
+		cmp.Compare(a.group, b.group),
+		cmp.Compare(a.kind, b.kind),
+		cmp.Compare(a.namespace, b.namespace),
+		cmp.Compare(a.name, b.name),
+		cmp.Compare(a.apiVersion, b.apiVersion),
+	)
+}
+
+// sortedResources returns the resources of a Resources task in the task order.
+// Resources are first sorted by kind and label key so the order is total even
+// when two resources share a canonical key.
+func sortedResources(task core.Task) []core.Resource {
+	type entry struct {
+		key orderKey
+		r   core.Resource
+	}
+	var entries []entry
+	for _, kind := range slices.Sorted(maps.Keys(task.Resources)) {
+		m := task.Resources[kind]
+		for _, label := range slices.Sorted(maps.Keys(m)) {
+			entries = append(entries, entry{key: newOrderKey(m[label]), r: m[label]})
+		}
+	}
+	if task.Order != orderLabel {
+		slices.SortStableFunc(entries, func(a, b entry) int { return compareOrderKeys(a.key, b.key) })
+	}
+	list := make([]core.Resource, 0, len(entries))
+	for _, e := range entries {
+		list = append(list, e.r)
+	}
+	return list
+}
+
+// document represents one YAML document of a manifest stream.
+type document struct {
+	// resource represents the decoded document.
+	resource map[string]any
+	// data represents the verbatim document terminated by a newline.
+	data []byte
+}
+
+// splitDocuments returns the YAML documents of data.  Each document is copied
+// verbatim, preserving comments and formatting.  Empty documents are dropped.
+func splitDocuments(data []byte) ([]document, error) {
+	var docs []document
+	for _, chunk := range documentSeparator.Split(string(data), -1) {
+		if strings.TrimSpace(chunk) == "" {
+			continue
+		}
+		var r map[string]any
+		if err := yaml.Unmarshal([]byte(chunk), &r); err != nil {
+			return nil, errors.Format("could not parse document: %w", err)
+		}
+		if r == nil {
+			continue
+		}
+		if !strings.HasSuffix(chunk, "\n") {
+			chunk += "\n"
+		}
+		docs = append(docs, document{resource: r, data: []byte(chunk)})
+	}
+	return docs, nil
+}
+
+// joinDocuments returns docs joined into one manifest stream.
+func joinDocuments(docs []document) []byte {
+	return joinDocumentsSeparator(docs, defaultSeparator)
+}
+
+// joinDocumentsSeparator returns docs joined by separator into one manifest
+// stream.
+func joinDocumentsSeparator(docs []document, separator string) []byte {
+	var buf bytes.Buffer
+	for idx, doc := range docs {
+		if idx > 0 {
+			buf.WriteString(separator)
+		}
+		buf.Write(doc.data)
+	}
+	return buf.Bytes()
+}
+
+// sortDocuments returns the YAML documents of data in canonical order joined
+// by separator.  An empty separator joins them with "---\n" so the documents
+// remain distinct.
+func sortDocuments(data []byte, separator string) ([]byte, error) {
+	docs, err := splitDocuments(data)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(docs, func(a, b document) int {
+		return compareOrderKeys(newOrderKey(a.resource), newOrderKey(b.resource))
+	})
+	if separator == "" {
+		separator = defaultSeparator
+	}
+	return joinDocumentsSeparator(docs, separator), nil
+}
@@ -0,0 +1,186 @@
+package v1beta1
+
+import (
+	"strings"
+	"testing"
+
+	core "github.com/holos-run/holos/api/core/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resource(apiVersion, kind, namespace, name string) core.Resource {
+	metadata := map[string]any{"name": name}
+	if namespace != "" {
+		metadata["namespace"] = namespace
+	}
+	return core.Resource{"apiVersion": apiVersion, "kind": kind, "metadata": metadata}
+}
+
+// names returns kind/namespace/name of each resource for compact assertions.
+func names(list []core.Resource) []string {
+	out := make([]string, 0, len(list))
+	for _, r := range list {
+		key := newOrderKey(r)
+		out = append(out, key.kind+"/"+key.namespace+"/"+key.name)
+	}
+	return out
+}
+
+func TestSortedResources(t *testing.T) {
+	task := core.Task{
+		Kind:   "Resources",
+		Output: "a.gen.yaml",
+		Resources: core.Resources{
+			"Service": {
+				"web": resource("v1", "Service", "prod", "web"),
+				"api": resource("v1", "Service", "dev", "web"),
+			},
+			"Deployment": {
+				"web": resource("apps/v1", "Deployment", "prod", "web"),
+			},
+			"ClusterRole": {
+				"view": resource("rbac.authorization.k8s.io/v1", "ClusterRole", "", "view"),
+			},
+			"CustomResourceDefinition": {
+				"crd": resource("apiextensions.k8s.io/v1", "CustomResourceDefinition", "", "widgets.example.com"),
+			},
+			"StorageClass": {
+				"fast": resource("storage.k8s.io/v1", "StorageClass", "", "fast"),
+			},
+			"Node": {
+				"node": resource("v1", "Node", "", "node-1"),
+			},
+			"Namespace": {
+				"prod": resource("v1", "Namespace", "", "prod"),
+				"dev":  resource("v1", "Namespace", "", "dev"),
+			},
+		},
+	}
+
+	t.Run("Kind", func(t *testing.T) {
+		want := []string{
+			"Namespace//dev",
+			"Namespace//prod",
+			"CustomResourceDefinition//widgets.example.com",
+			"ClusterRole//view",
+			"Node//node-1",
+			"StorageClass//fast",
+			"Deployment/prod/web",
+			"Service/dev/web",
+			"Service/prod/web",
+		}
+		for range 10 {
+			assert.Equal(t, want, names(sortedResources(task)))
+		}
+	})
+
+	t.Run("Label", func(t *testing.T) {
+		task := task
+		task.Order = "Label"
+		assert.Equal(t, []string{
+			"ClusterRole//view",
+			"CustomResourceDefinition//widgets.example.com",
+			"Deployment/prod/web",
+			"Namespace//dev",
+			"Namespace//prod",
+			"Node//node-1",
+			"Service/dev/web",
+			"Service/prod/web",
+			"StorageClass//fast",
+		}, names(sortedResources(task)))
+	})
+}
+
+func TestSortDocuments(t *testing.T) {
+	data := `---
+# service comment
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: prod
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: prod
+--- # empty documents are dropped
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod`
+
+	have, err := sortDocuments([]byte(data), "---\n")
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: v1
+kind: Namespace
+metadata:
+  name: prod
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: prod
+---
+# service comment
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: prod
+`, string(have))
+
+	// The documents are joined by the separator of the task.
+	have, err = sortDocuments([]byte(data), "--- # joined\n")
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(have), "--- # joined\n"))
+	assert.NotContains(t, strings.ReplaceAll(string(have), "--- # joined\n", ""), "---")
+
+	_, err = sortDocuments([]byte("kind: [unterminated\n"), "---\n")
+	assert.ErrorContains(t, err, "could not parse document")
+}
+
+func TestBuildJoinOrder(t *testing.T) {
+	join := func(order core.Order) core.Task {
+		return core.Task{
+			Kind:   "Join",
+			Inputs: []core.FileOrDirectoryPath{"b.gen.yaml", "a.gen.yaml"},
+			Output: "joined.gen.yaml",
+			Join:   core.Join{Separator: "---\n"},
+			Order:  order,
+		}
+	}
+	namespace := core.Task{
+		Kind:      "Resources",
+		Output:    "a.gen.yaml",
+		Resources: core.Resources{"Namespace": {"prod": resource("v1", "Namespace", "", "prod")}},
+	}
+	service := core.Task{
+		Kind:      "Resources",
+		Output:    "b.gen.yaml",
+		Resources: core.Resources{"Service": {"web": resource("v1", "Service", "prod", "web")}},
+	}
+
+	for _, tc := range []struct {
+		order core.Order
+		first string
+	}{
+		{order: "", first: "kind: Service"},
+		{order: "Input", first: "kind: Service"},
+		{order: "Kind", first: "kind: Namespace"},
+	} {
+		t.Run(string(tc.order), func(t *testing.T) {
+			b := newTestTaskSet(t, map[string]core.Task{"a": namespace, "b": service, "join": join(tc.order)})
+			require.NoError(t, b.Build(t.Context()))
+			data, ok := b.Opts.Store.Get("joined.gen.yaml")
+			require.True(t, ok)
+			assert.Contains(t, string(data), "---\n")
+			first, _, _ := strings.Cut(string(data), "\n---\n")
+			assert.Contains(t, first, tc.first)
+		})
+	}
+}
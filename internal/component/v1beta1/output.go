@@ -0,0 +1,67 @@
+package v1beta1
+
+import (
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/holos-run/holos/internal/errors"
+)
+
+// TaskOutput returns the store path of the output of the named task, or an
+// error if the task has no output.
+func (b *TaskSet) TaskOutput(name string) (string, error) {
+	task, ok := b.Spec.Tasks[name]
+	if !ok {
+		return "", errors.Format("task %s: no such task", name)
+	}
+	if task.Output == "" {
+		return "", errors.Format("task %s: %s tasks without an output have nothing to write", name, task.Kind)
+	}
+	return string(task.Output), nil
+}
+
+// WriteTaskOutput writes the output of the named task from the artifact store
+// after a build.  If dest is "-" the output is written to stdout, the files of
+// a directory output joined as one YAML stream in path order.  Otherwise the
+// output is saved under the dest directory at its store path.
+func (b *TaskSet) WriteTaskOutput(name string, dest string, stdout io.Writer) error {
+	output, err := b.TaskOutput(name)
+	if err != nil {
+		return err
+	}
+
+	store := b.Opts.Store
+	var keys []string
+	for _, key := range store.Keys() {
+		if key == output || strings.HasPrefix(key, output+"/") {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return errors.Format("task %s: output %s not found in the artifact store", name, output)
+	}
+	slices.Sort(keys)
+
+	if dest != "-" {
+		dir, err := filepath.Abs(dest)
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		return errors.Wrap(store.Save(dir, output))
+	}
+
+	for idx, key := range keys {
+		data, _ := store.Get(key)
+		if idx > 0 && !strings.HasPrefix(string(data), "---") {
+			if _, err := io.WriteString(stdout, "---\n"); err != nil {
+				return errors.Wrap(err)
+			}
+		}
+		if _, err := stdout.Write(data); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,160 @@
+package v1beta1
+
+import (
+	"encoding/json"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	core "github.com/holos-run/holos/api/core/v1beta1"
+	"github.com/holos-run/holos/internal/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+const (
+	patchJSON6902       = "JSON6902"
+	patchMerge          = "Merge"
+	patchStrategicMerge = "StrategicMerge"
+)
+
+// resourcePatch represents a parsed [core.ResourcePatch].
+type resourcePatch struct {
+	typ    string
+	target resourceSelector
+	// data represents the JSON encoded merge patch.
+	data []byte
+	// operations represents the decoded JSON6902 patch.
+	operations jsonpatch.Patch
+}
+
+// newResourcePatch parses p, returning an error if the target or the patch
+// document is malformed.
+func newResourcePatch(p core.ResourcePatch) (resourcePatch, error) {
+	target, err := newResourceSelector(p.Target)
+	if err != nil {
+		return resourcePatch{}, errors.Format("target: %w", err)
+	}
+	rp := resourcePatch{typ: p.Type, target: target}
+	switch p.Type {
+	case patchJSON6902:
+		if len(p.Operations) == 0 {
+			return rp, errors.Format("type %s requires operations", p.Type)
+		}
+		data, err := json.Marshal(p.Operations)
+		if err != nil {
+			return rp, errors.Wrap(err)
+		}
+		if rp.operations, err = jsonpatch.DecodePatch(data); err != nil {
+			return rp, errors.Format("could not decode operations: %w", err)
+		}
+	case patchMerge, patchStrategicMerge:
+		if len(p.Merge) == 0 {
+			return rp, errors.Format("type %s requires merge", p.Type)
+		}
+		if rp.data, err = json.Marshal(p.Merge); err != nil {
+			return rp, errors.Wrap(err)
+		}
+	default:
+		return rp, errors.Format("unsupported patch type %s", p.Type)
+	}
+	return rp, nil
+}
+
+// newResourcePatches parses the patches of p.
+func newResourcePatches(p core.Patch) ([]resourcePatch, error) {
+	patches := make([]resourcePatch, 0, len(p.Patches))
+	for idx, rp := range p.Patches {
+		patch, err := newResourcePatch(rp)
+		if err != nil {
+			return nil, errors.Format("patch %d: %w", idx, err)
+		}
+		patches = append(patches, patch)
+	}
+	return patches, nil
+}
+
+// apply returns the JSON encoded resource data with the patch applied.
+func (p resourcePatch) apply(r map[string]any, data []byte) ([]byte, error) {
+	switch p.typ {
+	case patchJSON6902:
+		return p.operations.Apply(data)
+	case patchStrategicMerge:
+		// Kinds without a registered Go type have no patch merge keys.
+		if obj, err := scheme.Scheme.New(groupVersionKind(r)); err == nil {
+			return strategicpatch.StrategicMergePatch(data, p.data, obj)
+		}
+	}
+	return jsonpatch.MergePatch(data, p.data)
+}
+
+// groupVersionKind returns the group, version, and kind of r.
+func groupVersionKind(r map[string]any) schema.GroupVersionKind {
+	apiVersion, _ := r["apiVersion"].(string)
+	kind, _ := r["kind"].(string)
+	return schema.FromAPIVersionAndKind(apiVersion, kind)
+}
+
+// patch applies the task patches in order to the resources of the task inputs
+// in-process, writing every document to the output.
+func (t *taskRunner) patch() error {
+	patches, err := newResourcePatches(t.task.Patch)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	store := t.opts.Store
+	var docs []document
+	for _, input := range t.task.Inputs {
+		data, ok := store.Get(string(input))
+		if !ok {
+			return errors.Format("missing input %s", input)
+		}
+		inputDocs, err := splitDocuments(data)
+		if err != nil {
+			return errors.Format("could not patch %s: %w", input, err)
+		}
+		docs = append(docs, inputDocs...)
+	}
+
+	for idx, p := range patches {
+		matched := false
+		for i := range docs {
+			if !p.target.matches(docs[i].resource) {
+				continue
+			}
+			matched = true
+			if docs[i], err = applyPatch(p, docs[i]); err != nil {
+				key := newOrderKey(docs[i].resource)
+				return errors.Format("patch %d: could not patch %s %s: %w", idx, key.kind, key.name, err)
+			}
+		}
+		if !matched {
+			return errors.Format("patch %d: target matched no resources", idx)
+		}
+	}
+
+	if err := store.Set(string(t.task.Output), joinDocuments(docs)); err != nil {
+		return errors.Wrap(err)
+	}
+	return nil
+}
+
+// applyPatch returns doc with p applied.  The patched document is re-encoded,
+// dropping comments.
+func applyPatch(p resourcePatch, doc document) (document, error) {
+	data, err := json.Marshal(doc.resource)
+	if err != nil {
+		return doc, errors.Wrap(err)
+	}
+	if data, err = p.apply(doc.resource, data); err != nil {
+		return doc, errors.Wrap(err)
+	}
+	var r map[string]any
+	if err := decodeJSON(data, &r); err != nil {
+		return doc, errors.Wrap(err)
+	}
+	buf, err := marshal([]core.Resource{r})
+	if err != nil {
+		return doc, errors.Wrap(err)
+	}
+	return document{resource: r, data: buf.Bytes()}, nil
+}
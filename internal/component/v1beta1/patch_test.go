@@ -0,0 +1,201 @@
+package v1beta1
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	core "github.com/holos-run/holos/api/core/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const patchInput = `# Source: web/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+        - name: web
+          image: web:1.0.0
+        - name: sidecar
+          image: sidecar:1.0.0
+---
+# Source: web/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`
+
+func TestBuildPatch(t *testing.T) {
+	deployment := core.ResourceSelector{Kind: "Deployment", Name: "web"}
+
+	for _, tc := range []struct {
+		name    string
+		patches []core.ResourcePatch
+		want    string
+	}{
+		{
+			name: "JSON6902",
+			patches: []core.ResourcePatch{{
+				Type:   "JSON6902",
+				Target: deployment,
+				Operations: []core.PatchOperation{
+					{Op: "replace", Path: "/spec/replicas", Value: 3},
+					{Op: "remove", Path: "/spec/template/spec/containers/1"},
+				},
+			}},
+			want: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+    name: web
+spec:
+    replicas: 3
+    template:
+        spec:
+            containers:
+                - image: web:1.0.0
+                  name: web
+`,
+		},
+		{
+			name: "Merge",
+			patches: []core.ResourcePatch{{
+				Type:   "Merge",
+				Target: deployment,
+				Merge: map[string]any{
+					"metadata": map[string]any{"labels": map[string]any{"app": "web"}},
+					"spec":     map[string]any{"replicas": nil},
+				},
+			}},
+			want: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+    labels:
+        app: web
+    name: web
+spec:
+    template:
+        spec:
+            containers:
+                - image: web:1.0.0
+                  name: web
+                - image: sidecar:1.0.0
+                  name: sidecar
+`,
+		},
+		{
+			name: "StrategicMerge",
+			patches: []core.ResourcePatch{{
+				Type:   "StrategicMerge",
+				Target: deployment,
+				Merge: map[string]any{"spec": map[string]any{"template": map[string]any{"spec": map[string]any{
+					"containers": []any{map[string]any{"name": "web", "image": "web:2.0.0"}},
+				}}}},
+			}},
+			want: `apiVersion: apps/v1
+kind: Deployment
+metadata:
+    name: web
+spec:
+    replicas: 1
+    template:
+        spec:
+            containers:
+                - image: web:2.0.0
+                  name: web
+                - image: sidecar:1.0.0
+                  name: sidecar
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := newTestTaskSet(t, map[string]core.Task{
+				"chart": {Kind: "File", File: core.File{Source: "chart.yaml"}, Output: "chart.yaml"},
+				"patch": {
+					Kind:   "Patch",
+					Inputs: []core.FileOrDirectoryPath{"chart.yaml"},
+					Output: "patched.gen.yaml",
+					Patch:  core.Patch{Patches: tc.patches},
+				},
+			})
+			require.NoError(t, os.WriteFile(filepath.Join(b.Opts.AbsLeaf(), "chart.yaml"), []byte(patchInput), 0o666))
+			require.NoError(t, b.Build(t.Context()))
+
+			data, ok := b.Opts.Store.Get("patched.gen.yaml")
+			require.True(t, ok)
+			// The unpatched service is copied verbatim.
+			assert.Equal(t, tc.want+`---
+# Source: web/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`, string(data))
+		})
+	}
+}
+
+func TestBuildPatchErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		patch   core.ResourcePatch
+		errText string
+	}{
+		{
+			name:    "NoMatch",
+			patch:   core.ResourcePatch{Type: "Merge", Target: core.ResourceSelector{Kind: "Ingress"}, Merge: map[string]any{"spec": nil}},
+			errText: "patch 0: target matched no resources",
+		},
+		{
+			name: "FailedTest",
+			patch: core.ResourcePatch{Type: "JSON6902", Operations: []core.PatchOperation{
+				{Op: "test", Path: "/kind", Value: "Pod"},
+			}},
+			errText: "patch 0: could not patch Deployment web",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := newTestTaskSet(t, map[string]core.Task{
+				"chart": {Kind: "File", File: core.File{Source: "chart.yaml"}, Output: "chart.yaml"},
+				"patch": {
+					Kind:   "Patch",
+					Inputs: []core.FileOrDirectoryPath{"chart.yaml"},
+					Output: "patched.gen.yaml",
+					Patch:  core.Patch{Patches: []core.ResourcePatch{tc.patch}},
+				},
+			})
+			require.NoError(t, os.WriteFile(filepath.Join(b.Opts.AbsLeaf(), "chart.yaml"), []byte(patchInput), 0o666))
+			assert.ErrorContains(t, b.Build(t.Context()), tc.errText)
+		})
+	}
+}
+
+func TestStrategicMergeCustomResource(t *testing.T) {
+	p, err := newResourcePatch(core.ResourcePatch{
+		Type:  "StrategicMerge",
+		Merge: map[string]any{"spec": map[string]any{"hosts": []any{"b"}}},
+	})
+	require.NoError(t, err)
+	r := map[string]any{"apiVersion": "example.com/v1", "kind": "Widget"}
+	// Unknown kinds fall back to a JSON merge patch, replacing lists.
+	data, err := p.apply(r, []byte(`{"apiVersion":"example.com/v1","kind":"Widget","spec":{"hosts":["a"]}}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"apiVersion":"example.com/v1","kind":"Widget","spec":{"hosts":["b"]}}`, string(data))
+}
+
+func TestValidatePatchOnlyForPatch(t *testing.T) {
+	// A stray patch block of another kind is not validated as a patch.
+	stray := core.Patch{Patches: []core.ResourcePatch{{Type: "Replace"}}}
+	join := core.Task{Kind: "Join", Inputs: []core.FileOrDirectoryPath{"a.yaml"}, Output: "b.yaml", Patch: stray}
+	assert.NoError(t, validateTask("join", join))
+
+	patch := join
+	patch.Kind = "Patch"
+	assert.ErrorContains(t, validateTask("patch", patch), "unsupported patch type Replace")
+}
@@ -0,0 +1,163 @@
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/holos-run/holos/internal/errors"
+)
+
+// Plan represents the derived task graph without executing any task.  Useful
+// to review which tasks a configuration change adds or rewires and which final
+// artifact paths it writes.
+type Plan struct {
+	// Tasks holds every task in deterministic topological order.
+	Tasks []PlanTask `json:"tasks"`
+}
+
+// PlanTask represents one node of the derived task graph.
+type PlanTask struct {
+	// ID represents the canonical task id, <leaf>:<taskset>/<task>.
+	ID string `json:"id"`
+	// Kind represents the task kind.
+	Kind string `json:"kind"`
+	// Inputs represents the task inputs and the tasks producing each one.
+	Inputs []PlanInput `json:"inputs,omitempty"`
+	// DependsOn represents the ordering edges not derived from inputs.
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// Artifact represents the final artifact path relative to the platform
+	// root.  Only sinks have an artifact path.
+	Artifact string `json:"artifact,omitempty"`
+}
+
+// PlanInput represents one task input.  An input with no producers is read
+// from the component directory.
+type PlanInput struct {
+	Path      string   `json:"path"`
+	Producers []string `json:"producers,omitempty"`
+}
+
+// Plan validates the TaskSet and derives its task graph per schema.md D1
+// without executing any task.
+func (b *TaskSet) Plan(ctx context.Context) (*Plan, error) {
+	msg := fmt.Sprintf("could not plan %s", b.Metadata.Name)
+	g, err := b.graph()
+	if err != nil {
+		return nil, errors.Format("%s: %w", msg, err)
+	}
+	if ref := g.firstExternal(); ref != "" {
+		return nil, errors.Format("%s: %s: canonical task ids are not supported by holos render component, use holos render platform", msg, ref)
+	}
+	p := &Platform{
+		Components:    []PlatformComponent{{TaskSet: b}},
+		TaskSelectors: b.Opts.TaskSelectors,
+	}
+	if task := b.Opts.Task; task != "" {
+		p.task = b.id(task)
+	}
+	return p.Plan(ctx)
+}
+
+// Plan merges every component into one graph per rendering.md step 2 without
+// executing any task.
+func (p *Platform) Plan(ctx context.Context) (*Plan, error) {
+	g, nodes, err := p.graph(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	plan := &Plan{Tasks: make([]PlanTask, 0, len(g.names))}
+	for _, id := range topoOrder(g) {
+		node := nodes[id]
+		task := PlanTask{
+			ID:       id,
+			Kind:     node.kind,
+			Artifact: node.artifact,
+		}
+		producers := make(map[string]struct{})
+		for _, input := range g.inputs[id] {
+			task.Inputs = append(task.Inputs, PlanInput{Path: input.path, Producers: input.producers})
+			for _, producer := range input.producers {
+				producers[producer] = struct{}{}
+			}
+		}
+		for pred := range g.pred[id] {
+			if _, ok := producers[pred]; !ok {
+				task.DependsOn = append(task.DependsOn, pred)
+			}
+		}
+		slices.Sort(task.DependsOn)
+		plan.Tasks = append(plan.Tasks, task)
+	}
+	return plan, nil
+}
+
+// Write writes the plan to w in a human readable format, one task per
+// paragraph in topological order.
+func (p *Plan) Write(w io.Writer) error {
+	var b strings.Builder
+	for idx, task := range p.Tasks {
+		if idx > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "%s (%s)\n", task.ID, task.Kind)
+		for _, input := range task.Inputs {
+			if len(input.Producers) == 0 {
+				fmt.Fprintf(&b, "  input %s <- component directory\n", input.Path)
+				continue
+			}
+			fmt.Fprintf(&b, "  input %s <- %s\n", input.Path, strings.Join(input.Producers, ", "))
+		}
+		for _, dep := range task.DependsOn {
+			fmt.Fprintf(&b, "  dependsOn %s\n", dep)
+		}
+		if task.Artifact != "" {
+			fmt.Fprintf(&b, "  artifact %s\n", task.Artifact)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return errors.Wrap(err)
+}
+
+// artifactPath returns the final artifact path relative to the platform root,
+// for example deploy/components/podinfo/podinfo.gen.yaml.
+func (b *TaskSet) artifactPath(path string) string {
+	writeTo, err := filepath.Rel(b.Opts.Root(), b.Opts.AbsWriteTo())
+	if err != nil {
+		writeTo = b.Opts.AbsWriteTo()
+	}
+	return filepath.ToSlash(filepath.Join(writeTo, path))
+}
+
+// topoOrder returns the graph nodes in topological order.  Ties break by name
+// so the order is deterministic (rendering.md R7).
+func topoOrder(g *graph) []string {
+	indegree := make(map[string]int, len(g.names))
+	ready := make([]string, 0, len(g.names))
+	for _, name := range g.names {
+		indegree[name] = len(g.pred[name])
+		if indegree[name] == 0 {
+			ready = append(ready, name)
+		}
+	}
+	slices.Sort(ready)
+
+	order := make([]string, 0, len(g.names))
+	for len(ready) > 0 {
+		name := ready[0]
+		ready = ready[1:]
+		order = append(order, name)
+		for succ := range g.succ[name] {
+			indegree[succ]--
+			if indegree[succ] == 0 {
+				idx, _ := slices.BinarySearch(ready, succ)
+				ready = slices.Insert(ready, idx, succ)
+			}
+		}
+	}
+	return order
+}
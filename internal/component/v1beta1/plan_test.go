@@ -0,0 +1,129 @@
+package v1beta1
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	core "github.com/holos-run/holos/api/core/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskSetPlan(t *testing.T) {
+	b := newTestTaskSet(t, map[string]core.Task{
+		"gen": resourcesTask("a", "a.gen.yaml"),
+		"combine": {
+			Kind:      "Join",
+			Inputs:    []core.FileOrDirectoryPath{"a.gen.yaml", "local.yaml"},
+			Output:    "combined.gen.yaml",
+			DependsOn: map[string]core.Dependency{"check": {}},
+		},
+		"check": {
+			Kind:    "Command",
+			Inputs:  []core.FileOrDirectoryPath{"a.gen.yaml"},
+			Command: core.Command{Args: []string{"false"}},
+		},
+		"deploy": {
+			Kind:     "Artifact",
+			Inputs:   []core.FileOrDirectoryPath{"combined.gen.yaml"},
+			Artifact: core.Artifact{Path: "components/test/test.gen.yaml"},
+		},
+	})
+	require.NoError(t, os.WriteFile(filepath.Join(b.Opts.AbsLeaf(), "local.yaml"), []byte("{}\n"), 0o666))
+	order := recordOrder(b)
+
+	plan, err := b.Plan(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, *order, "expected no task to execute")
+
+	want := []PlanTask{
+		{ID: "components/test:test/gen", Kind: "Resources"},
+		{
+			ID:     "components/test:test/check",
+			Kind:   "Command",
+			Inputs: []PlanInput{{Path: "a.gen.yaml", Producers: []string{"components/test:test/gen"}}},
+		},
+		{
+			ID:   "components/test:test/combine",
+			Kind: "Join",
+			Inputs: []PlanInput{
+				{Path: "a.gen.yaml", Producers: []string{"components/test:test/gen"}},
+				{Path: "local.yaml", Producers: []string{}},
+			},
+			DependsOn: []string{"components/test:test/check"},
+		},
+		{
+			ID:       "components/test:test/deploy",
+			Kind:     "Artifact",
+			Inputs:   []PlanInput{{Path: "combined.gen.yaml", Producers: []string{"components/test:test/combine"}}},
+			Artifact: "deploy/components/test/test.gen.yaml",
+		},
+	}
+	assert.Equal(t, want, plan.Tasks)
+
+	_, err = os.Stat(b.Opts.AbsWriteTo())
+	assert.True(t, os.IsNotExist(err), "expected nothing written")
+
+	var buf bytes.Buffer
+	require.NoError(t, plan.Write(&buf))
+	assert.Equal(t, `components/test:test/gen (Resources)
+
+components/test:test/check (Command)
+  input a.gen.yaml <- components/test:test/gen
+
+components/test:test/combine (Join)
+  input a.gen.yaml <- components/test:test/gen
+  input local.yaml <- component directory
+  dependsOn components/test:test/check
+
+components/test:test/deploy (Artifact)
+  input combined.gen.yaml <- components/test:test/combine
+  artifact deploy/components/test/test.gen.yaml
+`, buf.String())
+}
+
+func TestTaskSetPlanCanonicalError(t *testing.T) {
+	task := resourcesTask("a", "a.gen.yaml")
+	task.DependsOn = map[string]core.Dependency{"components/other:other/gen": {}}
+	b := newTestTaskSet(t, map[string]core.Task{"gen": task})
+	_, err := b.Plan(t.Context())
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "canonical task ids are not supported by holos render component")
+}
+
+func TestPlatformPlan(t *testing.T) {
+	root := t.TempDir()
+	alpha := newComponentTaskSet(t, root, "components/alpha", "alpha", map[string]core.Task{
+		"gen": resourcesTask("a", "a.gen.yaml"),
+	})
+	beta := newComponentTaskSet(t, root, "components/beta", "beta", map[string]core.Task{
+		"combine": {
+			Kind:   "Join",
+			Inputs: []core.FileOrDirectoryPath{"components/alpha:alpha/a.gen.yaml"},
+			Output: "combined.gen.yaml",
+		},
+	})
+	order := recordPlatformOrder(alpha, beta)
+	p := &Platform{Components: []PlatformComponent{
+		{TaskSet: beta},
+		{TaskSet: alpha},
+		{ID: "components/legacy:legacy", Kind: "BuildPlan"},
+	}}
+
+	plan, err := p.Plan(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, *order, "expected no task to execute")
+
+	want := []PlanTask{
+		{ID: "components/alpha:alpha/gen", Kind: "Resources"},
+		{
+			ID:     "components/beta:beta/combine",
+			Kind:   "Join",
+			Inputs: []PlanInput{{Path: "components/alpha:alpha/a.gen.yaml", Producers: []string{"components/alpha:alpha/gen"}}},
+		},
+		{ID: "components/legacy:legacy", Kind: "BuildPlan"},
+	}
+	assert.Equal(t, want, plan.Tasks)
+}
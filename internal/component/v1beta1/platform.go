@@ -0,0 +1,421 @@
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/holos-run/holos/internal/artifact"
+	"github.com/holos-run/holos/internal/errors"
+	"github.com/holos-run/holos/internal/holos"
+	"github.com/holos-run/holos/internal/logger"
+	"github.com/holos-run/holos/internal/trace"
+)
+
+// Platform executes the TaskSets of every platform component as one DAG per
+// rendering.md step 2.  Tasks are keyed by canonical id,
+// <leaf>:<taskset>/<task>, so a task may depend on, or consume the output of,
+// a task in another component.  One scheduler bounded by Concurrency executes
+// the merged graph.
+type Platform struct {
+	// Components represents the platform components to render.
+	Components []PlatformComponent
+	// Concurrency represents the number of tasks to execute concurrently across
+	// the whole platform.
+	Concurrency int
+	// KeepGoing executes every task not depending on a failed task instead of
+	// failing fast.  Dependents of a failed task are skipped.
+	KeepGoing bool
+	// Stderr receives the [Report] of a keep-going run.
+	Stderr io.Writer
+	// TaskSelectors select the v1beta1 tasks to execute by label.  Opaque
+	// nodes are always executed.
+	TaskSelectors holos.Selectors
+
+	// task represents the canonical id of the one task to execute along with
+	// its transitive predecessors.  Every task executes if empty.
+	task string
+}
+
+// PlatformComponent represents one component in the platform DAG.  A v1beta1
+// component sets TaskSet and joins the graph natively.  A legacy component
+// sets ID and Run and joins the graph as one opaque node with no task
+// visibility per rendering-migration.md.
+type PlatformComponent struct {
+	// TaskSet represents the component TaskSet.
+	TaskSet *TaskSet
+	// ID represents the canonical id of an opaque node.
+	ID string
+	// Run executes an opaque node.
+	Run func(context.Context) error
+	// Kind describes an opaque node in a [Plan], for example BuildPlan.
+	Kind string
+	// Done is called once every node of the component has completed
+	// successfully with the duration since the first node started.
+	Done func(context.Context, time.Duration)
+	// Unchanged marks a component whose inputs have not changed since the
+	// previous render.  If any component is unchanged, only the tasks of
+	// changed components execute, along with the tasks consuming their outputs
+	// and the tasks producing the inputs of both.  Used by watch mode.
+	Unchanged bool
+}
+
+// platformNode represents one node of the merged platform graph.
+type platformNode struct {
+	// component is the index of the component owning the node.
+	component int
+	run       func(context.Context) error
+	// kind represents the task kind, or the component kind of an opaque node.
+	kind string
+	// labels represents the task labels.
+	labels map[string]string
+	// artifact represents the final artifact path written by a sink relative
+	// to the platform root.
+	artifact string
+}
+
+// Build merges every component into one graph then executes the graph in
+// topological order.  Graph errors are reported before any task runs.  The
+// first task failure cancels the run unless KeepGoing is set.
+func (p *Platform) Build(ctx context.Context) error {
+	g, nodes, err := p.graph(ctx)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+
+	// Track completion per component to report when each finishes.
+	var mu sync.Mutex
+	remaining := make([]int, len(p.Components))
+	started := make([]time.Time, len(p.Components))
+	// A component with no nodes, for example a disabled TaskSet, rendered
+	// nothing and is not reported.
+	for _, node := range nodes {
+		remaining[node.component]++
+	}
+
+	run := func(ctx context.Context, id string) error {
+		node := nodes[id]
+		ctx, span := trace.Start(ctx, trace.CategoryTask, id, "kind", node.kind)
+		defer span.End()
+		span.Link(slices.Sorted(maps.Keys(g.pred[id]))...)
+		mu.Lock()
+		if started[node.component].IsZero() {
+			started[node.component] = time.Now()
+		}
+		mu.Unlock()
+
+		if err := node.run(ctx); err != nil {
+			return err
+		}
+
+		mu.Lock()
+		remaining[node.component]--
+		done := remaining[node.component] == 0
+		duration := time.Since(started[node.component])
+		mu.Unlock()
+		if done {
+			if fn := p.Components[node.component].Done; fn != nil {
+				fn(ctx, duration)
+			}
+		}
+		return nil
+	}
+
+	if !p.KeepGoing {
+		return errors.Wrap(execute(ctx, g, p.Concurrency, run))
+	}
+
+	report := executeKeepGoing(ctx, g, p.Concurrency, run)
+	if p.Stderr != nil {
+		if err := report.Write(p.Stderr); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+	return report.Err()
+}
+
+// Artifacts returns the sorted artifact paths relative to the write-to
+// directory written by the Artifact tasks of the last Build.
+func (p *Platform) Artifacts() []string {
+	var paths []string
+	for _, c := range p.Components {
+		if c.TaskSet != nil {
+			paths = append(paths, c.TaskSet.Artifacts()...)
+		}
+	}
+	slices.Sort(paths)
+	return paths
+}
+
+// graph merges the component graphs into one graph keyed by canonical id.
+// Canonical dependsOn targets and canonical input store paths resolve to
+// cross-component edges.  Final artifact paths are platform-global, so two
+// sinks declaring the same or overlapping paths is an error naming both.
+func (p *Platform) graph(ctx context.Context) (*graph, map[string]platformNode, error) {
+	log := logger.FromContext(ctx)
+	g := &graph{
+		succ:      make(map[string]map[string]struct{}),
+		pred:      make(map[string]map[string]struct{}),
+		inputs:    make(map[string][]graphInput),
+		artifacts: make(map[string]string),
+	}
+	nodes := make(map[string]platformNode)
+	addNode := func(id string, node platformNode) error {
+		if _, ok := nodes[id]; ok {
+			return errors.Format("duplicate task id %s", id)
+		}
+		nodes[id] = node
+		g.names = append(g.names, id)
+		g.succ[id] = make(map[string]struct{})
+		g.pred[id] = make(map[string]struct{})
+		return nil
+	}
+
+	// Derive each component graph, then add its nodes and edges.
+	tasksets := make(map[string]*TaskSet, len(p.Components))
+	graphs := make(map[*TaskSet]*graph, len(p.Components))
+	for idx, c := range p.Components {
+		b := c.TaskSet
+		if b == nil {
+			if err := addNode(c.ID, platformNode{component: idx, run: c.Run, kind: c.Kind}); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		name := b.Metadata.Name
+		msg := fmt.Sprintf("could not build %s", name)
+		if b.Spec.Disabled {
+			log.WarnContext(ctx, fmt.Sprintf("%s: disabled", msg), "name", name, "path", b.Opts.Leaf())
+			continue
+		}
+		key := b.Opts.Leaf() + ":" + name
+		if _, ok := tasksets[key]; ok {
+			return nil, nil, errors.Format("duplicate task set %s", key)
+		}
+		tasksets[key] = b
+
+		cg, err := b.prepare()
+		if err != nil {
+			return nil, nil, errors.Format("%s: %w", msg, err)
+		}
+		graphs[b] = cg
+
+		for _, task := range cg.names {
+			id := b.id(task)
+			node := platformNode{
+				component: idx,
+				run:       b.taskFunc(task),
+				kind:      b.Spec.Tasks[task].Kind,
+				labels:    b.Spec.Tasks[task].Metadata.Labels,
+			}
+			for path, sink := range cg.artifacts {
+				if sink == task {
+					node.artifact = b.artifactPath(path)
+				}
+			}
+			if err := addNode(id, node); err != nil {
+				return nil, nil, err
+			}
+			for _, input := range cg.inputs[task] {
+				producers := make([]string, 0, len(input.producers))
+				for _, producer := range input.producers {
+					producers = append(producers, b.id(producer))
+				}
+				g.inputs[id] = append(g.inputs[id], graphInput{path: input.path, producers: producers})
+			}
+		}
+		for _, task := range cg.names {
+			for succ := range cg.succ[task] {
+				g.addEdge(b.id(task), b.id(succ))
+			}
+		}
+		for _, path := range sortedKeys(cg.artifacts) {
+			id := b.id(cg.artifacts[path])
+			if prev, ok := g.artifacts[path]; ok {
+				return nil, nil, errors.Format("duplicate artifact path %s: declared by tasks %s and %s", path, prev, id)
+			}
+			g.artifacts[path] = id
+		}
+	}
+
+	// Resolve canonical references now that every component is known.
+	for _, c := range p.Components {
+		b := c.TaskSet
+		cg, ok := graphs[b]
+		if !ok {
+			continue
+		}
+		for _, task := range cg.names {
+			id := b.id(task)
+			for _, target := range cg.externalDeps[task] {
+				if _, ok := nodes[target]; !ok {
+					return nil, nil, errors.Format("task %s: dependsOn target %s: no such task", id, target)
+				}
+				if target == id {
+					return nil, nil, errors.Format("task %s: dependsOn target %s: task depends on itself", id, target)
+				}
+				g.addEdge(target, id)
+			}
+
+			for _, ref := range cg.externalInputs[task] {
+				key, path, ok := parseCanonical(ref)
+				if !ok {
+					return nil, nil, errors.Format("task %s: input %s: canonical store paths must have the form <leaf>:<taskset>/<path>", id, ref)
+				}
+				src, ok := tasksets[key]
+				if !ok {
+					return nil, nil, errors.Format("task %s: input %s: no such task set %s", id, ref, key)
+				}
+				sg := graphs[src]
+				matches := matchProducers(path, sg.producers, sg.outputs)
+				if len(matches) == 0 {
+					return nil, nil, errors.Format("task %s: input %s matches no task output of %s", id, ref, key)
+				}
+				producers := make([]string, 0, len(matches))
+				for _, producer := range matches {
+					if src == b && producer == task {
+						return nil, nil, errors.Format("task %s: input %s matches its own output", id, ref)
+					}
+					g.addEdge(src.id(producer), id)
+					producers = append(producers, src.id(producer))
+				}
+				for idx, input := range g.inputs[id] {
+					if input.path == ref {
+						g.inputs[id][idx].producers = producers
+					}
+				}
+				b.imports = append(b.imports, storeImport{task: task, ref: ref, src: src, path: path})
+			}
+		}
+	}
+
+	// Final artifact paths must be prefix-free platform-wide.
+	if err := checkPrefixFree("artifact path", g.artifacts, sortedKeys(g.artifacts)); err != nil {
+		return nil, nil, err
+	}
+
+	sort.Strings(g.names)
+	if err := checkCycles(g); err != nil {
+		return nil, nil, err
+	}
+
+	full := g
+	g = g.selectTasks(p.TaskSelectors, func(id string) (map[string]string, string, bool) {
+		node := nodes[id]
+		return node.labels, node.kind, p.Components[node.component].TaskSet != nil
+	})
+	if p.task != "" {
+		if _, ok := g.pred[p.task]; !ok {
+			return nil, nil, errors.Format("task %s: no such task", p.task)
+		}
+		g = g.ancestors(p.task)
+	}
+	if slices.ContainsFunc(p.Components, func(c PlatformComponent) bool { return c.Unchanged }) {
+		var roots []string
+		for _, id := range g.names {
+			if !p.Components[nodes[id].component].Unchanged {
+				roots = append(roots, id)
+			}
+		}
+		g = g.affected(roots)
+	}
+	for _, id := range g.pruned(ctx, full) {
+		delete(nodes, id)
+	}
+	return g, nodes, nil
+}
+
+// storeImport represents a canonical store path consumed by a task from the
+// artifact store of another component.
+type storeImport struct {
+	task string
+	ref  string
+	src  *TaskSet
+	path string
+}
+
+// taskFunc returns a function running the named task after importing the
+// canonical store paths it consumes.
+func (b *TaskSet) taskFunc(name string) func(context.Context) error {
+	return func(ctx context.Context) error {
+		for _, imp := range b.imports {
+			if imp.task != name {
+				continue
+			}
+			if err := b.importInput(imp.ref, imp.src.Opts.Store, imp.path); err != nil {
+				return errors.Format("could not build %s: could not import %s: %w", b.id(name), imp.ref, err)
+			}
+		}
+		return b.runTask(ctx, name)
+	}
+}
+
+// importInput copies a file or directory path from the src store into the
+// TaskSet store under the canonical store path ref, at most once.  Store paths
+// are write-once, so concurrent consumers of the same ref share one copy.
+func (b *TaskSet) importInput(ref string, src artifact.Store, path string) error {
+	b.saveMu.Lock()
+	defer b.saveMu.Unlock()
+	if err, ok := b.imported[ref]; ok {
+		return err
+	}
+	err := copyPath(b.Opts.Store, ref, src, path)
+	b.imported[ref] = err
+	return err
+}
+
+// copyPath copies a file or every file under a directory path from src into
+// dst, replacing the path prefix with ref.
+func copyPath(dst artifact.Store, ref string, src artifact.Store, path string) error {
+	if data, ok := src.Get(path); ok {
+		return errors.Wrap(dst.Set(ref, data))
+	}
+	prefix := path + "/"
+	keys := src.Keys()
+	sort.Strings(keys)
+	found := false
+	for _, key := range keys {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		data, _ := src.Get(key)
+		if err := dst.Set(ref+"/"+strings.TrimPrefix(key, prefix), data); err != nil {
+			return errors.Wrap(err)
+		}
+		found = true
+	}
+	if !found {
+		return errors.Format("missing input %s", path)
+	}
+	return nil
+}
+
+// parseCanonical splits a canonical reference of the form
+// <leaf>:<taskset>/<rest> into the task set key <leaf>:<taskset> and rest.
+func parseCanonical(ref string) (key string, rest string, ok bool) {
+	leaf, rest, ok := strings.Cut(ref, ":")
+	if !ok || leaf == "" {
+		return "", "", false
+	}
+	name, rest, ok := strings.Cut(rest, "/")
+	if !ok || name == "" || rest == "" {
+		return "", "", false
+	}
+	return leaf + ":" + name, rest, true
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
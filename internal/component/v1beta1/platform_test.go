@@ -0,0 +1,318 @@
+package v1beta1
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	core "github.com/holos-run/holos/api/core/v1beta1"
+	"github.com/holos-run/holos/internal/holos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newComponentTaskSet returns a TaskSet named name for the component at leaf
+// within the shared platform root.
+func newComponentTaskSet(t *testing.T, root, leaf, name string, tasks map[string]core.Task) *TaskSet {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, leaf), 0o777))
+	opts := holos.NewBuildOpts(root, leaf, "deploy", t.TempDir())
+	return &TaskSet{
+		TaskSet: core.TaskSet{
+			APIVersion: "v1beta1",
+			Kind:       "TaskSet",
+			Metadata:   core.Metadata{Name: name},
+			Spec:       core.TaskSetSpec{Tasks: tasks},
+		},
+		Opts: opts,
+	}
+}
+
+// recordPlatformOrder wires a runHook into every TaskSet recording the
+// canonical id of each task in the order tasks start.
+func recordPlatformOrder(tasksets ...*TaskSet) *[]string {
+	var mu sync.Mutex
+	order := &[]string{}
+	for _, b := range tasksets {
+		b.runHook = func(ctx context.Context, name string, run func(context.Context) error) error {
+			mu.Lock()
+			*order = append(*order, b.id(name))
+			mu.Unlock()
+			return run(ctx)
+		}
+	}
+	return order
+}
+
+func TestPlatformCrossComponentDependsOn(t *testing.T) {
+	root := t.TempDir()
+	alpha := newComponentTaskSet(t, root, "components/alpha", "alpha", map[string]core.Task{
+		"gen": resourcesTask("a", "a.gen.yaml"),
+	})
+	beta := newComponentTaskSet(t, root, "components/beta", "beta", map[string]core.Task{
+		"gen": func() core.Task {
+			task := resourcesTask("b", "b.gen.yaml")
+			task.DependsOn = map[string]core.Dependency{"components/alpha:alpha/gen": {}}
+			return task
+		}(),
+	})
+	order := recordPlatformOrder(alpha, beta)
+
+	p := &Platform{
+		Components:  []PlatformComponent{{TaskSet: beta}, {TaskSet: alpha}},
+		Concurrency: 4,
+	}
+	require.NoError(t, p.Build(t.Context()))
+	assert.Equal(t, []string{"components/alpha:alpha/gen", "components/beta:beta/gen"}, *order)
+}
+
+func TestPlatformCrossComponentInput(t *testing.T) {
+	root := t.TempDir()
+	alpha := newComponentTaskSet(t, root, "components/alpha", "alpha", map[string]core.Task{
+		"gen": resourcesTask("a", "a.gen.yaml"),
+	})
+	beta := newComponentTaskSet(t, root, "components/beta", "beta", map[string]core.Task{
+		"gen": resourcesTask("b", "b.gen.yaml"),
+		"combine": {
+			Kind:   "Join",
+			Inputs: []core.FileOrDirectoryPath{"components/alpha:alpha/a.gen.yaml", "b.gen.yaml"},
+			Join:   core.Join{Separator: "---\n"},
+			Output: "combined.gen.yaml",
+		},
+		"deploy": {
+			Kind:     "Artifact",
+			Inputs:   []core.FileOrDirectoryPath{"combined.gen.yaml"},
+			Artifact: core.Artifact{Path: "components/beta/beta.gen.yaml"},
+		},
+	})
+	order := recordPlatformOrder(alpha, beta)
+
+	var mu sync.Mutex
+	var rendered []string
+	done := func(name string) func(context.Context, time.Duration) {
+		return func(context.Context, time.Duration) {
+			mu.Lock()
+			defer mu.Unlock()
+			rendered = append(rendered, name)
+		}
+	}
+	p := &Platform{
+		Components: []PlatformComponent{
+			{TaskSet: alpha, Done: done("alpha")},
+			{TaskSet: beta, Done: done("beta")},
+		},
+		Concurrency: 1,
+	}
+	require.NoError(t, p.Build(t.Context()))
+
+	assert.Less(t, indexOf(t, *order, "components/alpha:alpha/gen"), indexOf(t, *order, "components/beta:beta/combine"))
+	assert.ElementsMatch(t, []string{"alpha", "beta"}, rendered)
+
+	data, err := os.ReadFile(filepath.Join(root, "deploy", "components", "beta", "beta.gen.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "name: a")
+	assert.Contains(t, string(data), "name: b")
+	assert.Equal(t, []string{"components/beta/beta.gen.yaml"}, p.Artifacts())
+}
+
+func TestPlatformDisabledNotReported(t *testing.T) {
+	root := t.TempDir()
+	alpha := newComponentTaskSet(t, root, "components/alpha", "alpha", map[string]core.Task{
+		"gen": resourcesTask("a", "a.gen.yaml"),
+	})
+	beta := newComponentTaskSet(t, root, "components/beta", "beta", map[string]core.Task{
+		"gen": resourcesTask("b", "b.gen.yaml"),
+	})
+	beta.Spec.Disabled = true
+
+	var rendered []string
+	done := func(name string) func(context.Context, time.Duration) {
+		return func(context.Context, time.Duration) { rendered = append(rendered, name) }
+	}
+	p := &Platform{
+		Components: []PlatformComponent{
+			{TaskSet: alpha, Done: done("alpha")},
+			{TaskSet: beta, Done: done("beta")},
+		},
+		Concurrency: 1,
+	}
+	require.NoError(t, p.Build(t.Context()))
+	assert.Equal(t, []string{"alpha"}, rendered, "expected a disabled component not to be reported as rendered")
+}
+
+func TestPlatformUnchanged(t *testing.T) {
+	root := t.TempDir()
+	alpha := newComponentTaskSet(t, root, "components/alpha", "alpha", map[string]core.Task{
+		"gen": resourcesTask("a", "a.gen.yaml"),
+	})
+	beta := newComponentTaskSet(t, root, "components/beta", "beta", map[string]core.Task{
+		"combine": {
+			Kind:   "Join",
+			Inputs: []core.FileOrDirectoryPath{"components/alpha:alpha/a.gen.yaml"},
+			Output: "b.gen.yaml",
+		},
+	})
+	gamma := newComponentTaskSet(t, root, "components/gamma", "gamma", map[string]core.Task{
+		"gen": resourcesTask("c", "c.gen.yaml"),
+	})
+	delta := newComponentTaskSet(t, root, "components/delta", "delta", map[string]core.Task{
+		"combine": {
+			Kind:   "Join",
+			Inputs: []core.FileOrDirectoryPath{"components/beta:beta/b.gen.yaml"},
+			Output: "d.gen.yaml",
+		},
+	})
+	order := recordPlatformOrder(alpha, beta, gamma, delta)
+
+	var rendered []string
+	done := func(name string) func(context.Context, time.Duration) {
+		return func(context.Context, time.Duration) { rendered = append(rendered, name) }
+	}
+	p := &Platform{
+		Components: []PlatformComponent{
+			{TaskSet: alpha, Done: done("alpha"), Unchanged: true},
+			{TaskSet: beta, Done: done("beta")},
+			{TaskSet: gamma, Done: done("gamma"), Unchanged: true},
+			{TaskSet: delta, Done: done("delta"), Unchanged: true},
+		},
+		Concurrency: 1,
+	}
+	require.NoError(t, p.Build(t.Context()))
+
+	// The producer of beta and the consumer of beta run, gamma does not.
+	assert.Equal(t, []string{
+		"components/alpha:alpha/gen",
+		"components/beta:beta/combine",
+		"components/delta:delta/combine",
+	}, *order)
+	assert.Equal(t, []string{"alpha", "beta", "delta"}, rendered)
+}
+
+func TestPlatformOpaqueNode(t *testing.T) {
+	root := t.TempDir()
+	alpha := newComponentTaskSet(t, root, "components/alpha", "alpha", map[string]core.Task{
+		"gen": resourcesTask("a", "a.gen.yaml"),
+	})
+	var ran bool
+	p := &Platform{
+		Components: []PlatformComponent{
+			{TaskSet: alpha},
+			{ID: "components/legacy:legacy", Run: func(context.Context) error {
+				ran = true
+				return nil
+			}},
+		},
+	}
+	require.NoError(t, p.Build(t.Context()))
+	assert.True(t, ran)
+}
+
+func TestPlatformGraphErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		alpha   map[string]core.Task
+		beta    map[string]core.Task
+		errText string
+	}{
+		{
+			name: "UnknownCanonicalDependsOn",
+			alpha: map[string]core.Task{
+				"gen": func() core.Task {
+					task := resourcesTask("a", "a.gen.yaml")
+					task.DependsOn = map[string]core.Dependency{"components/beta:beta/missing": {}}
+					return task
+				}(),
+			},
+			beta:    map[string]core.Task{"gen": resourcesTask("b", "b.gen.yaml")},
+			errText: "no such task",
+		},
+		{
+			name: "UnknownCanonicalInput",
+			alpha: map[string]core.Task{
+				"combine": {
+					Kind:   "Join",
+					Inputs: []core.FileOrDirectoryPath{"components/beta:beta/missing.gen.yaml"},
+					Output: "combined.gen.yaml",
+				},
+			},
+			beta:    map[string]core.Task{"gen": resourcesTask("b", "b.gen.yaml")},
+			errText: "matches no task output of components/beta:beta",
+		},
+		{
+			name: "DuplicateArtifactPath",
+			alpha: map[string]core.Task{
+				"gen": resourcesTask("a", "a.gen.yaml"),
+				"deploy": {
+					Kind:     "Artifact",
+					Inputs:   []core.FileOrDirectoryPath{"a.gen.yaml"},
+					Artifact: core.Artifact{Path: "same.gen.yaml"},
+				},
+			},
+			beta: map[string]core.Task{
+				"gen": resourcesTask("b", "b.gen.yaml"),
+				"deploy": {
+					Kind:     "Artifact",
+					Inputs:   []core.FileOrDirectoryPath{"b.gen.yaml"},
+					Artifact: core.Artifact{Path: "same.gen.yaml"},
+				},
+			},
+			errText: "duplicate artifact path same.gen.yaml: declared by tasks components/alpha:alpha/deploy and components/beta:beta/deploy",
+		},
+		{
+			name: "CrossComponentCycle",
+			alpha: map[string]core.Task{
+				"gen": func() core.Task {
+					task := resourcesTask("a", "a.gen.yaml")
+					task.DependsOn = map[string]core.Dependency{"components/beta:beta/gen": {}}
+					return task
+				}(),
+			},
+			beta: map[string]core.Task{
+				"gen": func() core.Task {
+					task := resourcesTask("b", "b.gen.yaml")
+					task.DependsOn = map[string]core.Dependency{"components/alpha:alpha/gen": {}}
+					return task
+				}(),
+			},
+			errText: "cycle detected",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			root := t.TempDir()
+			p := &Platform{
+				Components: []PlatformComponent{
+					{TaskSet: newComponentTaskSet(t, root, "components/alpha", "alpha", tc.alpha)},
+					{TaskSet: newComponentTaskSet(t, root, "components/beta", "beta", tc.beta)},
+				},
+			}
+			err := p.Build(t.Context())
+			require.Error(t, err)
+			assert.ErrorContains(t, err, tc.errText)
+		})
+	}
+}
+
+func TestParseCanonical(t *testing.T) {
+	for _, tc := range []struct {
+		ref  string
+		key  string
+		rest string
+		ok   bool
+	}{
+		{ref: "components/a:a/gen", key: "components/a:a", rest: "gen", ok: true},
+		{ref: "components/a:a/out/file.yaml", key: "components/a:a", rest: "out/file.yaml", ok: true},
+		{ref: "components/a:gen"},
+		{ref: ":a/gen"},
+		{ref: "components/a:a/"},
+	} {
+		t.Run(tc.ref, func(t *testing.T) {
+			key, rest, ok := parseCanonical(tc.ref)
+			assert.Equal(t, tc.ok, ok)
+			assert.Equal(t, tc.key, key)
+			assert.Equal(t, tc.rest, rest)
+		})
+	}
+}
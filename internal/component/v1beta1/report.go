@@ -0,0 +1,172 @@
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/holos-run/holos/internal/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+// Report represents the outcome of every task of a keep-going run.  A keep-going
+// run executes every task not depending on a failed task, so one run surfaces
+// every broken artifact instead of only the first (rendering.md R8 opt-in).
+type Report struct {
+	// Succeeded represents the tasks completing successfully.
+	Succeeded []string
+	// Failed represents the tasks returning an error.
+	Failed []FailedTask
+	// Skipped represents the tasks not executed because a task they depend on,
+	// directly or transitively, failed.
+	Skipped []SkippedTask
+}
+
+// FailedTask represents a task returning an error in a keep-going run.
+type FailedTask struct {
+	ID  string
+	Err error
+}
+
+// SkippedTask represents a task not executed in a keep-going run.
+type SkippedTask struct {
+	ID string
+	// FailedDependencies represents the failed tasks the skipped task depends
+	// on directly or transitively.
+	FailedDependencies []string
+}
+
+// Err returns an error summarizing the report, or nil when no task failed.
+func (r *Report) Err() error {
+	if len(r.Failed) == 0 {
+		return nil
+	}
+	total := len(r.Succeeded) + len(r.Failed) + len(r.Skipped)
+	return errors.Format("%d of %d tasks failed, %d skipped", len(r.Failed), total, len(r.Skipped))
+}
+
+// canonical replaces the task names of a TaskSet report with canonical ids.
+// Order is preserved, every id of one TaskSet shares the same prefix.
+func (r *Report) canonical(id func(string) string) *Report {
+	for idx, name := range r.Succeeded {
+		r.Succeeded[idx] = id(name)
+	}
+	for idx := range r.Failed {
+		r.Failed[idx].ID = id(r.Failed[idx].ID)
+	}
+	for idx := range r.Skipped {
+		r.Skipped[idx].ID = id(r.Skipped[idx].ID)
+		for jdx, name := range r.Skipped[idx].FailedDependencies {
+			r.Skipped[idx].FailedDependencies[jdx] = id(name)
+		}
+	}
+	return r
+}
+
+// Write writes the report to w in a human readable format, failed tasks first.
+func (r *Report) Write(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d succeeded, %d failed, %d skipped\n", len(r.Succeeded), len(r.Failed), len(r.Skipped))
+	for _, task := range r.Failed {
+		fmt.Fprintf(&b, "failed %s: %s\n", task.ID, task.Err)
+	}
+	for _, task := range r.Skipped {
+		fmt.Fprintf(&b, "skipped %s: depends on failed %s\n", task.ID, strings.Join(task.FailedDependencies, ", "))
+	}
+	for _, id := range r.Succeeded {
+		fmt.Fprintf(&b, "succeeded %s\n", id)
+	}
+	_, err := io.WriteString(w, b.String())
+	return errors.Wrap(err)
+}
+
+// executeKeepGoing runs the graph nodes in topological order calling run for
+// each, like execute, except a failed node does not cancel the run.  Every
+// descendant of a failed node is skipped while independent branches keep
+// running.  The report lists each group sorted by name.
+func executeKeepGoing(ctx context.Context, g *graph, concurrency int, run func(context.Context, string) error) *Report {
+	var eg errgroup.Group
+	eg.SetLimit(max(1, concurrency))
+
+	indegree := make(map[string]int, len(g.names))
+	ready := make([]string, 0, len(g.names))
+	for _, name := range g.names {
+		indegree[name] = len(g.pred[name])
+		if indegree[name] == 0 {
+			ready = append(ready, name)
+		}
+	}
+	slices.Sort(ready)
+
+	type result struct {
+		name string
+		err  error
+	}
+	// results is buffered for every task so workers never block sending.
+	results := make(chan result, len(g.names))
+	pending := len(g.names)
+	skipped := make(map[string][]string)
+	report := &Report{}
+
+	for pending > 0 {
+		for len(ready) > 0 {
+			name := ready[0]
+			ready = ready[1:]
+			eg.Go(func() error {
+				if err := ctx.Err(); err != nil {
+					results <- result{name: name, err: err}
+					return nil
+				}
+				results <- result{name: name, err: run(ctx, name)}
+				return nil
+			})
+		}
+
+		res := <-results
+		pending--
+		if res.err == nil {
+			report.Succeeded = append(report.Succeeded, res.name)
+			for succ := range g.succ[res.name] {
+				indegree[succ]--
+				if _, ok := skipped[succ]; !ok && indegree[succ] == 0 {
+					idx, _ := slices.BinarySearch(ready, succ)
+					ready = slices.Insert(ready, idx, succ)
+				}
+			}
+			continue
+		}
+
+		report.Failed = append(report.Failed, FailedTask{ID: res.name, Err: res.err})
+		// Descendants of a failed node have not been dispatched, their
+		// predecessors on the path from the failed node are incomplete.
+		queue := []string{res.name}
+		seen := map[string]struct{}{res.name: {}}
+		for len(queue) > 0 {
+			cur := queue[0]
+			queue = queue[1:]
+			for succ := range g.succ[cur] {
+				if _, ok := seen[succ]; ok {
+					continue
+				}
+				seen[succ] = struct{}{}
+				queue = append(queue, succ)
+				if _, ok := skipped[succ]; !ok {
+					pending--
+				}
+				skipped[succ] = append(skipped[succ], res.name)
+			}
+		}
+	}
+	_ = eg.Wait()
+
+	slices.Sort(report.Succeeded)
+	slices.SortFunc(report.Failed, func(a, b FailedTask) int { return strings.Compare(a.ID, b.ID) })
+	for name, failed := range skipped {
+		slices.Sort(failed)
+		report.Skipped = append(report.Skipped, SkippedTask{ID: name, FailedDependencies: failed})
+	}
+	slices.SortFunc(report.Skipped, func(a, b SkippedTask) int { return strings.Compare(a.ID, b.ID) })
+	return report
+}
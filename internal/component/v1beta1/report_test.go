@@ -0,0 +1,153 @@
+package v1beta1
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	core "github.com/holos-run/holos/api/core/v1beta1"
+	"github.com/holos-run/holos/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// missingFileTask returns a File task failing because its source does not
+// exist.
+func missingFileTask(output string) core.Task {
+	return core.Task{
+		Kind:   "File",
+		File:   core.File{Source: "does-not-exist.yaml"},
+		Output: core.FileOrDirectoryPath(output),
+	}
+}
+
+func TestBuildKeepGoing(t *testing.T) {
+	tasks := map[string]core.Task{
+		"bad": missingFileTask("bad.gen.yaml"),
+		"combine": {
+			Kind:   "Join",
+			Inputs: []core.FileOrDirectoryPath{"bad.gen.yaml", "good.gen.yaml"},
+			Output: "combined.gen.yaml",
+		},
+		"deploy-combined": {
+			Kind:     "Artifact",
+			Inputs:   []core.FileOrDirectoryPath{"combined.gen.yaml"},
+			Artifact: core.Artifact{Path: "combined.gen.yaml"},
+		},
+		"good": resourcesTask("good", "good.gen.yaml"),
+		"deploy-good": {
+			Kind:     "Artifact",
+			Inputs:   []core.FileOrDirectoryPath{"good.gen.yaml"},
+			Artifact: core.Artifact{Path: "good.gen.yaml"},
+		},
+	}
+
+	t.Run("FailFastByDefault", func(t *testing.T) {
+		b := newTestTaskSet(t, tasks)
+		b.Opts.Concurrency = 1
+		order := recordOrder(b)
+		require.Error(t, b.Build(t.Context()))
+		assert.Equal(t, []string{"bad"}, *order, "expected the first failure to stop the build")
+	})
+
+	t.Run("KeepGoing", func(t *testing.T) {
+		b := newTestTaskSet(t, tasks)
+		b.Opts.Concurrency = 1
+		b.Opts.KeepGoing = true
+		var stderr bytes.Buffer
+		b.Opts.Stderr = &stderr
+		order := recordOrder(b)
+
+		err := b.Build(t.Context())
+		require.Error(t, err)
+		assert.ErrorContains(t, err, "1 of 5 tasks failed, 2 skipped")
+		assert.Equal(t, []string{"bad", "good", "deploy-good"}, *order)
+		assert.FileExists(t, filepath.Join(b.Opts.AbsWriteTo(), "good.gen.yaml"))
+		assert.NoFileExists(t, filepath.Join(b.Opts.AbsWriteTo(), "combined.gen.yaml"))
+
+		report := stderr.String()
+		assert.Contains(t, report, "2 succeeded, 1 failed, 2 skipped\n")
+		assert.Contains(t, report, "failed components/test:test/bad: ")
+		assert.Contains(t, report, "skipped components/test:test/combine: depends on failed components/test:test/bad\n")
+		assert.Contains(t, report, "skipped components/test:test/deploy-combined: depends on failed components/test:test/bad\n")
+		assert.Contains(t, report, "succeeded components/test:test/deploy-good\nsucceeded components/test:test/good\n")
+	})
+
+	t.Run("KeepGoingSucceeds", func(t *testing.T) {
+		b := newTestTaskSet(t, map[string]core.Task{"good": tasks["good"], "deploy-good": tasks["deploy-good"]})
+		b.Opts.KeepGoing = true
+		var stderr bytes.Buffer
+		b.Opts.Stderr = &stderr
+		require.NoError(t, b.Build(t.Context()))
+		assert.Contains(t, stderr.String(), "2 succeeded, 0 failed, 0 skipped\n")
+	})
+}
+
+func TestPlatformKeepGoing(t *testing.T) {
+	root := t.TempDir()
+	alpha := newComponentTaskSet(t, root, "components/alpha", "alpha", map[string]core.Task{
+		"gen": missingFileTask("a.gen.yaml"),
+	})
+	beta := newComponentTaskSet(t, root, "components/beta", "beta", map[string]core.Task{
+		"combine": {
+			Kind:   "Join",
+			Inputs: []core.FileOrDirectoryPath{"components/alpha:alpha/a.gen.yaml"},
+			Output: "combined.gen.yaml",
+		},
+	})
+	gamma := newComponentTaskSet(t, root, "components/gamma", "gamma", map[string]core.Task{
+		"gen": resourcesTask("c", "c.gen.yaml"),
+	})
+	var done []string
+	component := func(b *TaskSet) PlatformComponent {
+		return PlatformComponent{TaskSet: b, Done: func(ctx context.Context, _ time.Duration) {
+			done = append(done, b.Metadata.Name)
+		}}
+	}
+	var stderr bytes.Buffer
+	p := &Platform{
+		Components:  []PlatformComponent{component(alpha), component(beta), component(gamma)},
+		Concurrency: 1,
+		KeepGoing:   true,
+		Stderr:      &stderr,
+	}
+
+	err := p.Build(t.Context())
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "1 of 3 tasks failed, 1 skipped")
+	assert.Equal(t, []string{"gamma"}, done, "expected only complete components reported done")
+	assert.Contains(t, stderr.String(), "skipped components/beta:beta/combine: depends on failed components/alpha:alpha/gen\n")
+	assert.Contains(t, stderr.String(), "succeeded components/gamma:gamma/gen\n")
+}
+
+func TestExecuteKeepGoing(t *testing.T) {
+	// a and b fail, c depends on both, d depends on c, e is independent.
+	g := &graph{
+		names: []string{"a", "b", "c", "d", "e"},
+		succ: map[string]map[string]struct{}{
+			"a": {"c": {}}, "b": {"c": {}}, "c": {"d": {}}, "d": {}, "e": {},
+		},
+		pred: map[string]map[string]struct{}{
+			"a": {}, "b": {}, "c": {"a": {}, "b": {}}, "d": {"c": {}}, "e": {},
+		},
+	}
+	run := func(_ context.Context, name string) error {
+		if name == "a" || name == "b" {
+			return errors.New("boom")
+		}
+		return nil
+	}
+
+	report := executeKeepGoing(t.Context(), g, 2, run)
+	assert.Equal(t, []string{"e"}, report.Succeeded)
+	require.Len(t, report.Failed, 2)
+	assert.Equal(t, "a", report.Failed[0].ID)
+	assert.Equal(t, "b", report.Failed[1].ID)
+	assert.Equal(t, []SkippedTask{
+		{ID: "c", FailedDependencies: []string{"a", "b"}},
+		{ID: "d", FailedDependencies: []string{"a", "b"}},
+	}, report.Skipped)
+	assert.ErrorContains(t, report.Err(), "2 of 5 tasks failed, 2 skipped")
+}
@@ -0,0 +1,170 @@
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/holos-run/holos/internal/holos"
+	"github.com/holos-run/holos/internal/logger"
+)
+
+// selectTasks returns the subgraph of g executed under selectors per
+// [core.TaskMetadata], or g itself if no selectors are given.  describe
+// returns the labels and kind of a node, or ok false for a node not subject to
+// selection, which is always kept.
+//
+// A node selected by any selector is kept along with every node producing one
+// of its inputs, transitively.  A sink not selected is kept if it depends on a
+// kept node.  Ordering edges to pruned nodes are dropped.
+func (g *graph) selectTasks(selectors holos.Selectors, describe func(id string) (labels map[string]string, kind string, ok bool)) *graph {
+	if len(selectors) == 0 {
+		return g
+	}
+
+	keep := make(map[string]bool, len(g.names))
+	var queue []string
+	mark := func(id string) {
+		if !keep[id] {
+			keep[id] = true
+			queue = append(queue, id)
+		}
+	}
+	// closure keeps the producers of the inputs of every newly kept node.
+	closure := func() {
+		for len(queue) > 0 {
+			id := queue[0]
+			queue = queue[1:]
+			for _, input := range g.inputs[id] {
+				for _, producer := range input.producers {
+					mark(producer)
+				}
+			}
+		}
+	}
+
+	var sinks []string
+	for _, id := range g.names {
+		labels, kind, ok := describe(id)
+		switch {
+		case !ok || holos.IsSelected(labels, selectors...):
+			mark(id)
+		case kind == "Artifact":
+			sinks = append(sinks, id)
+		}
+	}
+	closure()
+
+	// Keeping a sink keeps its producers, which may keep another sink.
+	for changed := true; changed; {
+		changed = false
+		for _, id := range sinks {
+			if keep[id] {
+				continue
+			}
+			for pred := range g.pred[id] {
+				if keep[pred] {
+					mark(id)
+					changed = true
+					break
+				}
+			}
+		}
+		closure()
+	}
+
+	return g.subgraph(keep)
+}
+
+// ancestors returns the subgraph of g holding node id and its transitive
+// predecessors.
+func (g *graph) ancestors(id string) *graph {
+	return g.subgraph(reach([]string{id}, g.pred))
+}
+
+// affected returns the subgraph of g holding the roots, their transitive
+// successors, which consume their outputs, and the transitive predecessors of
+// both, which produce their inputs.
+func (g *graph) affected(roots []string) *graph {
+	down := reach(roots, g.succ)
+	ids := make([]string, 0, len(down))
+	for id := range down {
+		ids = append(ids, id)
+	}
+	return g.subgraph(reach(ids, g.pred))
+}
+
+// reach returns the nodes reachable from start following edges, including
+// start itself.
+func reach(start []string, edges map[string]map[string]struct{}) map[string]bool {
+	keep := make(map[string]bool, len(start))
+	queue := make([]string, 0, len(start))
+	for _, id := range start {
+		if !keep[id] {
+			keep[id] = true
+			queue = append(queue, id)
+		}
+	}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for next := range edges[node] {
+			if !keep[next] {
+				keep[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+	return keep
+}
+
+// subgraph returns the subgraph of g holding the kept nodes and the edges
+// between them.
+func (g *graph) subgraph(keep map[string]bool) *graph {
+	sub := &graph{
+		names:          make([]string, 0, len(keep)),
+		succ:           make(map[string]map[string]struct{}, len(keep)),
+		pred:           make(map[string]map[string]struct{}, len(keep)),
+		files:          g.files,
+		producers:      g.producers,
+		outputs:        g.outputs,
+		artifacts:      make(map[string]string),
+		externalDeps:   g.externalDeps,
+		externalInputs: g.externalInputs,
+		inputs:         make(map[string][]graphInput, len(keep)),
+	}
+	for _, id := range g.names {
+		if !keep[id] {
+			continue
+		}
+		sub.names = append(sub.names, id)
+		sub.succ[id] = make(map[string]struct{})
+		sub.pred[id] = make(map[string]struct{})
+		sub.inputs[id] = g.inputs[id]
+	}
+	for _, id := range sub.names {
+		for succ := range g.succ[id] {
+			if keep[succ] {
+				sub.addEdge(id, succ)
+			}
+		}
+	}
+	for path, sink := range g.artifacts {
+		if keep[sink] {
+			sub.artifacts[path] = sink
+		}
+	}
+	return sub
+}
+
+// pruned returns the nodes of full missing from g, logging each one.
+func (g *graph) pruned(ctx context.Context, full *graph) []string {
+	log := logger.FromContext(ctx)
+	var ids []string
+	for _, id := range full.names {
+		if _, ok := g.pred[id]; !ok {
+			log.DebugContext(ctx, fmt.Sprintf("pruned task %s", id), "id", id)
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
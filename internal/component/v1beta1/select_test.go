@@ -0,0 +1,208 @@
+package v1beta1
+
+import (
+	"bytes"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	core "github.com/holos-run/holos/api/core/v1beta1"
+	"github.com/holos-run/holos/internal/holos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectTasks(t *testing.T) {
+	labeled := func(task core.Task, labels map[string]string) core.Task {
+		task.Metadata.Labels = labels
+		return task
+	}
+	tasks := func() map[string]core.Task {
+		return map[string]core.Task{
+			"gen": resourcesTask("a", "a.gen.yaml"),
+			"scan": labeled(core.Task{
+				Kind:    "Command",
+				Inputs:  []core.FileOrDirectoryPath{"a.gen.yaml"},
+				Command: core.Command{Args: []string{"true"}},
+			}, map[string]string{"role": "security"}),
+			"validate": labeled(core.Task{
+				Kind:    "Command",
+				Inputs:  []core.FileOrDirectoryPath{"a.gen.yaml"},
+				Command: core.Command{Args: []string{"false"}},
+			}, map[string]string{"cost": "expensive"}),
+			"extra": resourcesTask("b", "b.gen.yaml"),
+			"deploy": {
+				Kind:      "Artifact",
+				Inputs:    []core.FileOrDirectoryPath{"a.gen.yaml"},
+				DependsOn: map[string]core.Dependency{"validate": {}},
+				Artifact:  core.Artifact{Path: "components/test/a.gen.yaml"},
+			},
+			"deploy-extra": {
+				Kind:     "Artifact",
+				Inputs:   []core.FileOrDirectoryPath{"b.gen.yaml"},
+				Artifact: core.Artifact{Path: "components/test/b.gen.yaml"},
+			},
+		}
+	}
+
+	for _, tc := range []struct {
+		name      string
+		selectors []string
+		want      []string
+	}{
+		{
+			name: "NoSelectors",
+			want: []string{"deploy", "deploy-extra", "extra", "gen", "scan", "validate"},
+		},
+		{
+			name:      "SkipExpensive",
+			selectors: []string{"cost!=expensive"},
+			want:      []string{"deploy", "deploy-extra", "extra", "gen", "scan"},
+		},
+		{
+			// Producers of the inputs of selected tasks are kept, as are sinks
+			// depending on a kept task.  Sinks depending only on pruned tasks
+			// are pruned.
+			name:      "OnlySecurity",
+			selectors: []string{"role=security"},
+			want:      []string{"deploy", "gen", "scan"},
+		},
+		{
+			name:      "AnySelector",
+			selectors: []string{"role=security", "cost=expensive"},
+			want:      []string{"deploy", "gen", "scan", "validate"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := newTestTaskSet(t, tasks())
+			for _, value := range tc.selectors {
+				require.NoError(t, b.Opts.TaskSelectors.Set(value))
+			}
+			order := recordOrder(b)
+			err := b.Build(t.Context())
+			if slices.Contains(tc.want, "validate") {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			have := slices.Clone(*order)
+			slices.Sort(have)
+			assert.Equal(t, tc.want, have)
+		})
+	}
+}
+
+func TestSelectTasksPlan(t *testing.T) {
+	b := newTestTaskSet(t, map[string]core.Task{
+		"gen": resourcesTask("a", "a.gen.yaml"),
+		"check": {
+			Kind:     "Command",
+			Metadata: core.TaskMetadata{Labels: map[string]string{"cost": "expensive"}},
+			Inputs:   []core.FileOrDirectoryPath{"a.gen.yaml"},
+			Command:  core.Command{Args: []string{"false"}},
+		},
+		"deploy": {
+			Kind:      "Artifact",
+			Inputs:    []core.FileOrDirectoryPath{"a.gen.yaml"},
+			DependsOn: map[string]core.Dependency{"check": {}},
+		},
+	})
+	b.Opts.TaskSelectors = holos.Selectors{{Negative: map[string]string{"cost": "expensive"}}}
+
+	plan, err := b.Plan(t.Context())
+	require.NoError(t, err)
+	// The ordering edge to the pruned check task is dropped.
+	assert.Equal(t, []PlanTask{
+		{ID: "components/test:test/gen", Kind: "Resources"},
+		{
+			ID:       "components/test:test/deploy",
+			Kind:     "Artifact",
+			Inputs:   []PlanInput{{Path: "a.gen.yaml", Producers: []string{"components/test:test/gen"}}},
+			Artifact: "deploy/a.gen.yaml",
+		},
+	}, plan.Tasks)
+}
+
+func TestBuildTask(t *testing.T) {
+	tasks := func() map[string]core.Task {
+		return map[string]core.Task{
+			"a":     resourcesTask("a", "a.gen.yaml"),
+			"b":     resourcesTask("b", "b.gen.yaml"),
+			"other": resourcesTask("other", "other.gen.yaml"),
+			"setup": {
+				Kind:    "Command",
+				Command: core.Command{Args: []string{"true"}},
+			},
+			"join": {
+				Kind:      "Join",
+				Inputs:    []core.FileOrDirectoryPath{"a.gen.yaml", "b.gen.yaml"},
+				Output:    "manifests/joined.gen.yaml",
+				DependsOn: map[string]core.Dependency{"setup": {}},
+			},
+			"validate": {
+				Kind:    "Command",
+				Inputs:  []core.FileOrDirectoryPath{"manifests"},
+				Command: core.Command{Args: []string{"false"}},
+			},
+			"deploy": {
+				Kind:   "Artifact",
+				Inputs: []core.FileOrDirectoryPath{"manifests"},
+			},
+		}
+	}
+
+	t.Run("Ancestors", func(t *testing.T) {
+		b := newTestTaskSet(t, tasks())
+		b.Opts.Task = "join"
+		order := recordOrder(b)
+		require.NoError(t, b.Build(t.Context()))
+		have := slices.Clone(*order)
+		slices.Sort(have)
+		assert.Equal(t, []string{"a", "b", "join", "setup"}, have)
+		assert.NoDirExists(t, b.Opts.AbsWriteTo(), "expected the sink not to execute")
+	})
+
+	t.Run("WriteTaskOutput", func(t *testing.T) {
+		b := newTestTaskSet(t, tasks())
+		b.Opts.Task = "join"
+		require.NoError(t, b.Build(t.Context()))
+
+		var buf bytes.Buffer
+		require.NoError(t, b.WriteTaskOutput("join", "-", &buf))
+		data, ok := b.Opts.Store.Get("manifests/joined.gen.yaml")
+		require.True(t, ok)
+		assert.Equal(t, string(data), buf.String())
+
+		dir := t.TempDir()
+		require.NoError(t, b.WriteTaskOutput("join", dir, &buf))
+		assert.FileExists(t, filepath.Join(dir, "manifests", "joined.gen.yaml"))
+
+		err := b.WriteTaskOutput("setup", "-", &buf)
+		assert.ErrorContains(t, err, "task setup: Command tasks without an output have nothing to write")
+	})
+
+	t.Run("NoSuchTask", func(t *testing.T) {
+		b := newTestTaskSet(t, tasks())
+		b.Opts.Task = "missing"
+		assert.ErrorContains(t, b.Build(t.Context()), "task missing: no such task")
+	})
+
+	t.Run("Plan", func(t *testing.T) {
+		b := newTestTaskSet(t, tasks())
+		b.Opts.Task = "validate"
+		plan, err := b.Plan(t.Context())
+		require.NoError(t, err)
+		var ids []string
+		for _, task := range plan.Tasks {
+			ids = append(ids, task.ID)
+		}
+		slices.Sort(ids)
+		assert.Equal(t, []string{
+			"components/test:test/a",
+			"components/test:test/b",
+			"components/test:test/join",
+			"components/test:test/setup",
+			"components/test:test/validate",
+		}, ids)
+	})
+}
@@ -0,0 +1,120 @@
+package v1beta1
+
+import (
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	core "github.com/holos-run/holos/api/core/v1beta1"
+	"github.com/holos-run/holos/internal/errors"
+)
+
+// defaultSplitTemplate represents the file name of each resource when a Split
+// task declares no template.
+const defaultSplitTemplate = "{kind}-{name}.yaml"
+
+// splitPlaceholder matches one placeholder of a split file name template.
+var splitPlaceholder = regexp.MustCompile(`\{[^{}]*\}`)
+
+// splitFields returns the placeholder values of resource r.
+func splitFields(r map[string]any) map[string]string {
+	kind, _ := r["kind"].(string)
+	apiVersion, _ := r["apiVersion"].(string)
+	metadata, _ := r["metadata"].(map[string]any)
+	name, _ := metadata["name"].(string)
+	namespace, _ := metadata["namespace"].(string)
+	group, version, ok := strings.Cut(apiVersion, "/")
+	if !ok {
+		// The core group, for example apiVersion v1.
+		group, version = "", apiVersion
+	}
+	return map[string]string{
+		"{kind}":      kind,
+		"{name}":      name,
+		"{namespace}": namespace,
+		"{group}":     group,
+		"{version}":   version,
+	}
+}
+
+// splitter represents a parsed [core.Split].
+type splitter struct {
+	template string
+	filter   resourceFilter
+}
+
+// newSplitter parses s, returning an error if the template names an unknown
+// placeholder or a selector is malformed.
+func newSplitter(s core.Split) (splitter, error) {
+	sp := splitter{template: s.Template}
+	if sp.template == "" {
+		sp.template = defaultSplitTemplate
+	}
+	fields := splitFields(nil)
+	for _, placeholder := range splitPlaceholder.FindAllString(sp.template, -1) {
+		if _, ok := fields[placeholder]; !ok {
+			return sp, errors.Format("template %q: unknown placeholder %s", sp.template, placeholder)
+		}
+	}
+	var err error
+	sp.filter, err = newResourceFilter(core.Filter{Include: s.Include, Exclude: s.Exclude})
+	return sp, errors.Wrap(err)
+}
+
+// name returns the file name of resource r relative to the output directory.
+func (s splitter) name(r map[string]any) (string, error) {
+	fields := splitFields(r)
+	name := splitPlaceholder.ReplaceAllStringFunc(s.template, func(placeholder string) string {
+		return strings.ToLower(fields[placeholder])
+	})
+	// Drop empty path segments, for example the namespace of a cluster-scoped
+	// resource.
+	name = strings.Join(slices.DeleteFunc(strings.Split(name, "/"), func(s string) bool { return s == "" }), "/")
+	if !validLocalPath(name) || path.Clean(name) != name {
+		return "", errors.Format("file name %q of %s %s: path must be relative and must not traverse outside the output directory", name, fields["{kind}"], fields["{name}"])
+	}
+	return name, nil
+}
+
+// split writes each selected resource of the task inputs verbatim to its own
+// file under the output directory.
+func (t *taskRunner) split() error {
+	sp, err := newSplitter(t.task.Split)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	store := t.opts.Store
+	// files holds the documents of each file name in the order first seen.
+	var names []string
+	files := make(map[string][]document)
+	for _, input := range t.task.Inputs {
+		data, ok := store.Get(string(input))
+		if !ok {
+			return errors.Format("missing input %s", input)
+		}
+		docs, err := splitDocuments(data)
+		if err != nil {
+			return errors.Format("could not split %s: %w", input, err)
+		}
+		for _, doc := range docs {
+			if !sp.filter.keep(doc.resource) {
+				continue
+			}
+			name, err := sp.name(doc.resource)
+			if err != nil {
+				return errors.Format("could not split %s: %w", input, err)
+			}
+			if _, ok := files[name]; !ok {
+				names = append(names, name)
+			}
+			files[name] = append(files[name], doc)
+		}
+	}
+	for _, name := range names {
+		if err := store.Set(path.Join(string(t.task.Output), name), joinDocuments(files[name])); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+	return nil
+}
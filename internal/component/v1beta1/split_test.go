@@ -0,0 +1,145 @@
+package v1beta1
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	core "github.com/holos-run/holos/api/core/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitterName(t *testing.T) {
+	deployment := map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]any{"name": "web", "namespace": "prod"},
+	}
+	namespace := map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Namespace",
+		"metadata":   map[string]any{"name": "prod"},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		template string
+		resource map[string]any
+		want     string
+		errText  string
+	}{
+		{name: "Default", resource: deployment, want: "deployment-web.yaml"},
+		{name: "Namespace", template: "{namespace}/{kind}-{name}.yaml", resource: deployment, want: "prod/deployment-web.yaml"},
+		{name: "ClusterScoped", template: "{namespace}/{kind}-{name}.yaml", resource: namespace, want: "namespace-prod.yaml"},
+		{name: "GroupVersion", template: "{group}/{version}/{kind}.yaml", resource: deployment, want: "apps/v1/deployment.yaml"},
+		{name: "CoreGroup", template: "{group}/{version}/{kind}.yaml", resource: namespace, want: "v1/namespace.yaml"},
+		{name: "Traversal", template: "../{name}.yaml", resource: deployment, errText: `file name "../web.yaml" of Deployment web: path must be relative`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			sp, err := newSplitter(core.Split{Template: tc.template})
+			require.NoError(t, err)
+			have, err := sp.name(tc.resource)
+			if tc.errText != "" {
+				assert.ErrorContains(t, err, tc.errText)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, have)
+		})
+	}
+}
+
+func TestNewSplitterInvalid(t *testing.T) {
+	_, err := newSplitter(core.Split{Template: "{Kind}.yaml"})
+	assert.ErrorContains(t, err, `template "{Kind}.yaml": unknown placeholder {Kind}`)
+
+	_, err = newSplitter(core.Split{Include: []core.ResourceSelector{{Name: "[web"}}})
+	assert.ErrorContains(t, err, "include 0: ")
+	assert.ErrorContains(t, err, `invalid name pattern "[web"`)
+}
+
+func TestBuildSplit(t *testing.T) {
+	chart := `# Source: web/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+# Source: web/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web-test-connection
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: other
+`
+	b := newTestTaskSet(t, map[string]core.Task{
+		"chart": {
+			Kind:   "File",
+			File:   core.File{Source: "chart.yaml"},
+			Output: "chart.yaml",
+		},
+		"split": {
+			Kind:   "Split",
+			Inputs: []core.FileOrDirectoryPath{"chart.yaml"},
+			Output: "manifests",
+			Split:  core.Split{Exclude: []core.ResourceSelector{{Kind: "Pod"}}},
+		},
+		"deploy": {
+			Kind:   "Artifact",
+			Inputs: []core.FileOrDirectoryPath{"manifests"},
+		},
+	})
+	require.NoError(t, os.WriteFile(filepath.Join(b.Opts.AbsLeaf(), "chart.yaml"), []byte(chart), 0o666))
+	require.NoError(t, b.Build(t.Context()))
+
+	data, ok := b.Opts.Store.Get("manifests/deployment-web.yaml")
+	require.True(t, ok)
+	assert.Equal(t, `# Source: web/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+`, string(data))
+
+	// Resources sharing a file name are joined in input order.
+	data, ok = b.Opts.Store.Get("manifests/service-web.yaml")
+	require.True(t, ok)
+	assert.Equal(t, `# Source: web/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  namespace: other
+`, string(data))
+
+	_, ok = b.Opts.Store.Get("manifests/pod-web-test-connection.yaml")
+	assert.False(t, ok, "expected excluded resources not to be split")
+	assert.FileExists(t, filepath.Join(b.Opts.AbsWriteTo(), "manifests", "deployment-web.yaml"))
+}
+
+func TestValidateSplitOnlyForSplit(t *testing.T) {
+	// A stray split block of another kind is not validated as a split.
+	stray := core.Split{Template: "{kind}-{uid}.yaml"}
+	join := core.Task{Kind: "Join", Inputs: []core.FileOrDirectoryPath{"a.yaml"}, Output: "b.yaml", Split: stray}
+	assert.NoError(t, validateTask("join", join))
+
+	split := join
+	split.Kind = "Split"
+	assert.ErrorContains(t, validateTask("split", split), "unknown placeholder {uid}")
+}
@@ -0,0 +1,175 @@
+package v1beta1
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	core "github.com/holos-run/holos/api/core/v1beta1"
+	"github.com/holos-run/holos/internal/errors"
+)
+
+// jsonPointer represents the reference tokens of a parsed RFC 6901 JSON
+// pointer.
+type jsonPointer []string
+
+// parseJSONPointer parses s, returning an error if s does not reference a
+// field below the document root.
+func parseJSONPointer(s string) (jsonPointer, error) {
+	if !strings.HasPrefix(s, "/") || s == "/" {
+		return nil, errors.Format("invalid json pointer %q: must start with / and reference a field", s)
+	}
+	tokens := strings.Split(s[1:], "/")
+	for idx, token := range tokens {
+		tokens[idx] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// remove returns v with the referenced field removed and true if the field
+// existed.  Removing a list item shifts the items following it.
+func (p jsonPointer) remove(v any) (any, bool) {
+	token := p[0]
+	switch node := v.(type) {
+	case map[string]any:
+		value, ok := node[token]
+		if !ok {
+			return v, false
+		}
+		if len(p) == 1 {
+			delete(node, token)
+			return node, true
+		}
+		value, ok = p[1:].remove(value)
+		node[token] = value
+		return node, ok
+	case []any:
+		idx, err := strconv.Atoi(token)
+		if err != nil || idx < 0 || idx >= len(node) {
+			return v, false
+		}
+		if len(p) == 1 {
+			return slices.Delete(node, idx, idx+1), true
+		}
+		value, ok := p[1:].remove(node[idx])
+		node[idx] = value
+		return node, ok
+	}
+	return v, false
+}
+
+// transformer represents a parsed [core.Transform].
+type transformer struct {
+	core.Transform
+	removeFields []jsonPointer
+	// clusterScoped holds the ClusterScopedKinds.
+	clusterScoped map[string]bool
+}
+
+// newTransformer parses t, returning an error if a field to remove is not a
+// valid JSON pointer.
+func newTransformer(t core.Transform) (transformer, error) {
+	tf := transformer{Transform: t, clusterScoped: make(map[string]bool, len(t.ClusterScopedKinds))}
+	for _, kind := range t.ClusterScopedKinds {
+		tf.clusterScoped[kind] = true
+	}
+	for _, field := range t.RemoveFields {
+		pointer, err := parseJSONPointer(field)
+		if err != nil {
+			return tf, errors.Format("remove field: %w", err)
+		}
+		tf.removeFields = append(tf.removeFields, pointer)
+	}
+	return tf, nil
+}
+
+// transform applies the operations to r in place, returning true if r changed.
+func (tf transformer) transform(r map[string]any) bool {
+	changed := false
+	for _, pointer := range tf.removeFields {
+		if _, ok := pointer.remove(r); ok {
+			changed = true
+		}
+	}
+
+	key := newOrderKey(r)
+	metadata, ok := r["metadata"].(map[string]any)
+	if !ok {
+		metadata = map[string]any{}
+	}
+	// Groups 0 through 2 hold namespaces, CRDs, and cluster-scoped kinds.
+	if tf.Namespace != "" && key.group > 2 && key.namespace == "" && !tf.clusterScoped[key.kind] {
+		metadata["namespace"] = tf.Namespace
+		changed = true
+	}
+	if setStrings(metadata, "labels", tf.Labels) {
+		changed = true
+	}
+	if setStrings(metadata, "annotations", tf.Annotations) {
+		changed = true
+	}
+	if (tf.NamePrefix != "" || tf.NameSuffix != "") && key.group > 1 {
+		metadata["name"] = tf.NamePrefix + key.name + tf.NameSuffix
+		changed = true
+	}
+	if changed {
+		r["metadata"] = metadata
+	}
+	return changed
+}
+
+// setStrings merges values into the string map field of metadata, returning
+// true if any value changed.
+func setStrings(metadata map[string]any, field string, values map[string]string) bool {
+	if len(values) == 0 {
+		return false
+	}
+	m, ok := metadata[field].(map[string]any)
+	if !ok {
+		m = make(map[string]any, len(values))
+	}
+	changed := false
+	for k, v := range values {
+		if current, ok := m[k].(string); !ok || current != v {
+			m[k] = v
+			changed = true
+		}
+	}
+	metadata[field] = m
+	return changed
+}
+
+// transform applies the metadata operations to every resource of the task
+// inputs in-process.
+func (t *taskRunner) transform() error {
+	tf, err := newTransformer(t.task.Transform)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	store := t.opts.Store
+	var docs []document
+	for _, input := range t.task.Inputs {
+		data, ok := store.Get(string(input))
+		if !ok {
+			return errors.Format("missing input %s", input)
+		}
+		inputDocs, err := splitDocuments(data)
+		if err != nil {
+			return errors.Format("could not transform %s: %w", input, err)
+		}
+		for _, doc := range inputDocs {
+			if tf.transform(doc.resource) {
+				buf, err := marshal([]core.Resource{doc.resource})
+				if err != nil {
+					return errors.Wrap(err)
+				}
+				doc.data = buf.Bytes()
+			}
+			docs = append(docs, doc)
+		}
+	}
+	if err := store.Set(string(t.task.Output), joinDocuments(docs)); err != nil {
+		return errors.Wrap(err)
+	}
+	return nil
+}
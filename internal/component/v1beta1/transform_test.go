@@ -0,0 +1,201 @@
+package v1beta1
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	core "github.com/holos-run/holos/api/core/v1beta1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONPointerRemove(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		pointer string
+		want    map[string]any
+		removed bool
+	}{
+		{
+			name:    "Field",
+			pointer: "/status",
+			want:    map[string]any{"metadata": map[string]any{"labels": map[string]any{"helm.sh/chart": "web-1.0.0"}}, "spec": map[string]any{"ports": []any{"http", "https"}}},
+			removed: true,
+		},
+		{
+			name:    "EscapedKey",
+			pointer: "/metadata/labels/helm.sh~1chart",
+			want:    map[string]any{"metadata": map[string]any{"labels": map[string]any{}}, "spec": map[string]any{"ports": []any{"http", "https"}}, "status": map[string]any{}},
+			removed: true,
+		},
+		{
+			name:    "ListItem",
+			pointer: "/spec/ports/0",
+			want:    map[string]any{"metadata": map[string]any{"labels": map[string]any{"helm.sh/chart": "web-1.0.0"}}, "spec": map[string]any{"ports": []any{"https"}}, "status": map[string]any{}},
+			removed: true,
+		},
+		{
+			name:    "Missing",
+			pointer: "/spec/ports/2",
+			want:    map[string]any{"metadata": map[string]any{"labels": map[string]any{"helm.sh/chart": "web-1.0.0"}}, "spec": map[string]any{"ports": []any{"http", "https"}}, "status": map[string]any{}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := map[string]any{
+				"metadata": map[string]any{"labels": map[string]any{"helm.sh/chart": "web-1.0.0"}},
+				"spec":     map[string]any{"ports": []any{"http", "https"}},
+				"status":   map[string]any{},
+			}
+			pointer, err := parseJSONPointer(tc.pointer)
+			require.NoError(t, err)
+			_, removed := pointer.remove(r)
+			assert.Equal(t, tc.removed, removed)
+			assert.Equal(t, tc.want, r)
+		})
+	}
+
+	for _, invalid := range []string{"", "/", "status"} {
+		_, err := parseJSONPointer(invalid)
+		assert.ErrorContains(t, err, "invalid json pointer")
+	}
+}
+
+func TestBuildTransform(t *testing.T) {
+	chart := `# Source: web/templates/namespace.yaml
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: web
+---
+# Source: web/templates/clusterrole.yaml
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: web
+---
+# Source: web/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+  labels:
+    helm.sh/chart: web-1.0.0
+    app: web
+status:
+  loadBalancer: {}
+---
+# Source: web/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web
+  namespace: other
+`
+	b := newTestTaskSet(t, map[string]core.Task{
+		"chart": {Kind: "File", File: core.File{Source: "chart.yaml"}, Output: "chart.yaml"},
+		"transform": {
+			Kind:   "Transform",
+			Inputs: []core.FileOrDirectoryPath{"chart.yaml"},
+			Output: "transformed.gen.yaml",
+			Transform: core.Transform{
+				RemoveFields: []string{"/status", "/metadata/labels/helm.sh~1chart"},
+				Namespace:    "prod",
+				Labels:       map[string]string{"team": "platform"},
+				Annotations:  map[string]string{"owner": "sre"},
+				NamePrefix:   "prod-",
+				NameSuffix:   "-v1",
+			},
+		},
+	})
+	require.NoError(t, os.WriteFile(filepath.Join(b.Opts.AbsLeaf(), "chart.yaml"), []byte(chart), 0o666))
+	require.NoError(t, b.Build(t.Context()))
+
+	data, ok := b.Opts.Store.Get("transformed.gen.yaml")
+	require.True(t, ok)
+	assert.Equal(t, `apiVersion: v1
+kind: Namespace
+metadata:
+    annotations:
+        owner: sre
+    labels:
+        team: platform
+    name: web
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+    annotations:
+        owner: sre
+    labels:
+        team: platform
+    name: prod-web-v1
+---
+apiVersion: v1
+kind: Service
+metadata:
+    annotations:
+        owner: sre
+    labels:
+        app: web
+        team: platform
+    name: prod-web-v1
+    namespace: prod
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+    annotations:
+        owner: sre
+    labels:
+        team: platform
+    name: prod-web-v1
+    namespace: other
+`, string(data))
+}
+
+func TestBuildTransformUnchanged(t *testing.T) {
+	chart := "# Source: web/templates/service.yaml\napiVersion: v1\nkind: Service\nmetadata:\n  name: web\n"
+	b := newTestTaskSet(t, map[string]core.Task{
+		"chart": {Kind: "File", File: core.File{Source: "chart.yaml"}, Output: "chart.yaml"},
+		"transform": {
+			Kind:      "Transform",
+			Inputs:    []core.FileOrDirectoryPath{"chart.yaml"},
+			Output:    "transformed.gen.yaml",
+			Transform: core.Transform{RemoveFields: []string{"/status"}},
+		},
+	})
+	require.NoError(t, os.WriteFile(filepath.Join(b.Opts.AbsLeaf(), "chart.yaml"), []byte(chart), 0o666))
+	require.NoError(t, b.Build(t.Context()))
+
+	data, ok := b.Opts.Store.Get("transformed.gen.yaml")
+	require.True(t, ok)
+	assert.Equal(t, chart, string(data), "expected untransformed documents copied verbatim")
+}
+
+func TestTransformClusterScopedKinds(t *testing.T) {
+	tf, err := newTransformer(core.Transform{Namespace: "prod", ClusterScopedKinds: []string{"Tenant"}})
+	require.NoError(t, err)
+
+	namespace := func(r core.Resource) any {
+		tf.transform(r)
+		return r["metadata"].(map[string]any)["namespace"]
+	}
+	// Built-in and listed cluster-scoped kinds are left unchanged.
+	assert.Nil(t, namespace(resource("v1", "Node", "", "node-1")))
+	assert.Nil(t, namespace(resource("gateway.networking.k8s.io/v1", "GatewayClass", "", "istio")))
+	assert.Nil(t, namespace(resource("example.com/v1", "Tenant", "", "acme")))
+	// Namespaced custom resources are placed in the namespace.
+	assert.Equal(t, "prod", namespace(resource("cert-manager.io/v1", "Certificate", "", "web")))
+}
+
+func TestValidateTransformOnlyForTransform(t *testing.T) {
+	// A stray transform block of another kind is not validated as a transform.
+	stray := core.Transform{RemoveFields: []string{"status"}}
+	join := core.Task{Kind: "Join", Inputs: []core.FileOrDirectoryPath{"a.yaml"}, Output: "b.yaml", Transform: stray}
+	assert.NoError(t, validateTask("join", join))
+
+	transform := join
+	transform.Kind = "Transform"
+	assert.ErrorContains(t, validateTask("transform", transform), `invalid json pointer "status"`)
+}
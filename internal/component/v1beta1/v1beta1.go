@@ -1,16 +1,19 @@
-// Package v1beta1 executes one component's [core.TaskSet].  The executor
-// derives DAG edges from task inputs/output declarations plus explicit
-// dependsOn edges per doc/design/v1beta1/schema.md D1, then executes tasks in
-// topological order with bounded concurrency.  Scope is intra-component only;
-// the platform-wide DAG belongs to Phase 2 (doc/design/v1beta1/rendering.md).
+// Package v1beta1 executes [core.TaskSet] resources.  The executor derives DAG
+// edges from task inputs/output declarations plus explicit dependsOn edges per
+// doc/design/v1beta1/schema.md D1, then executes tasks in topological order
+// with bounded concurrency.  [TaskSet] executes one component; [Platform]
+// merges every component's TaskSet into one platform-wide DAG keyed by
+// canonical task ID (doc/design/v1beta1/rendering.md).
 package v1beta1
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -27,10 +30,15 @@ import (
 	"github.com/holos-run/holos/internal/helm"
 	"github.com/holos-run/holos/internal/holos"
 	"github.com/holos-run/holos/internal/logger"
+	"github.com/holos-run/holos/internal/redact"
+	"github.com/holos-run/holos/internal/trace"
 	"github.com/holos-run/holos/internal/util"
 	"golang.org/x/sync/errgroup"
 	"gopkg.in/yaml.v3"
 	"helm.sh/helm/v3/pkg/cli"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
 )
 
 var _ holos.BuildPlan = &TaskSet{}
@@ -49,9 +57,24 @@ type TaskSet struct {
 	runHook func(ctx context.Context, name string, run func(context.Context) error) error
 
 	// saveMu guards saved so concurrent tasks materialize each store path into
-	// the shared build temp directory at most once.
-	saveMu sync.Mutex
-	saved  map[string]error
+	// the shared build temp directory at most once.  saveMu also guards
+	// imported so concurrent tasks copy each canonical store path from another
+	// component's store at most once.
+	saveMu   sync.Mutex
+	saved    map[string]error
+	imported map[string]error
+	// written holds the artifact paths relative to the write-to directory
+	// written by Artifact tasks, guarded by saveMu.
+	written []string
+
+	// imports holds the canonical store paths tasks consume from other
+	// components, resolved by the [Platform] merge.
+	imports []storeImport
+}
+
+// id returns the canonical id of the named task, <leaf>:<taskset>/<task>.
+func (b *TaskSet) id(name string) string {
+	return fmt.Sprintf("%s:%s/%s", b.Opts.Leaf(), b.Metadata.Name, name)
 }
 
 // sharedSave materializes a store path into the shared build temp directory at
@@ -70,6 +93,21 @@ func (b *TaskSet) sharedSave(dir, path string) error {
 	return err
 }
 
+// wrote records the artifact path written by an Artifact task.
+func (b *TaskSet) wrote(path string) {
+	b.saveMu.Lock()
+	defer b.saveMu.Unlock()
+	b.written = append(b.written, path)
+}
+
+// Artifacts returns the sorted artifact paths relative to the write-to
+// directory written by the Artifact tasks of the last build.
+func (b *TaskSet) Artifacts() []string {
+	b.saveMu.Lock()
+	defer b.saveMu.Unlock()
+	return slices.Sorted(slices.Values(b.written))
+}
+
 // Load loads the TaskSet from a cue value.
 func (b *TaskSet) Load(v cue.Value) error {
 	// First validate the value to get better error messages
@@ -96,7 +134,8 @@ func (b *TaskSet) Export(idx int, encoder holos.OrderedEncoder) error {
 }
 
 // Build derives the task graph per schema.md D1, then executes tasks in
-// topological order with concurrency bounded by Opts.Concurrency.
+// topological order with concurrency bounded by Opts.Concurrency.  The first
+// task failure cancels the build unless Opts.KeepGoing is set.
 func (b *TaskSet) Build(ctx context.Context) error {
 	name := b.Metadata.Name
 	log := logger.FromContext(ctx).With(
@@ -110,14 +149,79 @@ func (b *TaskSet) Build(ctx context.Context) error {
 		return nil
 	}
 
-	g, err := b.graph()
+	g, err := b.prepare()
 	if err != nil {
 		return errors.Format("%s: %w", msg, err)
 	}
 
+	// Canonical references name tasks and store paths of other components,
+	// which only the platform-wide DAG can resolve.
+	if ref := g.firstExternal(); ref != "" {
+		return errors.Format("%s: %s: canonical task ids are not supported by holos render component, use holos render platform", msg, ref)
+	}
+	full := g
+	g = g.selectTasks(b.Opts.TaskSelectors, b.describeTask)
+	if task := b.Opts.Task; task != "" {
+		if _, ok := g.pred[task]; !ok {
+			return errors.Format("%s: task %s: no such task", msg, task)
+		}
+		g = g.ancestors(task)
+	}
+	g.pruned(ctx, full)
+
+	run := func(ctx context.Context, name string) error {
+		ctx, span := trace.Start(ctx, trace.CategoryTask, b.id(name), "kind", b.Spec.Tasks[name].Kind)
+		defer span.End()
+		for _, pred := range slices.Sorted(maps.Keys(g.pred[name])) {
+			span.Link(b.id(pred))
+		}
+		return b.runTask(ctx, name)
+	}
+
+	if b.Opts.KeepGoing {
+		report := executeKeepGoing(ctx, g, b.Opts.Concurrency, run).canonical(b.id)
+		if err := report.Write(b.Opts.Stderr); err != nil {
+			return errors.Format("%s: %w", msg, err)
+		}
+		if err := report.Err(); err != nil {
+			return errors.Format("%s: %w", msg, err)
+		}
+		return nil
+	}
+
+	if err := execute(ctx, g, b.Opts.Concurrency, run); err != nil {
+		return errors.Format("%s: %w", msg, err)
+	}
+	return nil
+}
+
+// describeTask describes the named task for [graph.selectTasks].
+func (b *TaskSet) describeTask(name string) (map[string]string, string, bool) {
+	task := b.Spec.Tasks[name]
+	return task.Metadata.Labels, task.Kind, true
+}
+
+// prepare derives the task graph, then loads the inputs sourced from the
+// component directory into the artifact store.
+func (b *TaskSet) prepare() (*graph, error) {
+	g, err := b.graph()
+	if err != nil {
+		return nil, err
+	}
+
+	// Register credentials before any task runs so no task logs them.
+	for _, task := range b.Spec.Tasks {
+		if task.Kind == "Helm" {
+			authCredentials(task.Helm.Chart.Repository.Auth)
+		}
+	}
+
 	b.saveMu.Lock()
 	b.saved = make(map[string]error)
+	b.imported = make(map[string]error)
+	b.written = nil
 	b.saveMu.Unlock()
+	b.imports = nil
 
 	// Load inputs sourced from the component directory into the artifact
 	// store so tasks consume them uniformly (schema.md D1: an input matching
@@ -134,15 +238,11 @@ outer:
 			}
 		}
 		if err := b.Opts.Store.Load(b.Opts.AbsLeaf(), path); err != nil {
-			return errors.Format("%s: could not load %s from component directory: %w", msg, path, err)
+			return nil, errors.Format("could not load %s from component directory: %w", path, err)
 		}
 		loaded = append(loaded, path)
 	}
-
-	if err := b.execute(ctx, g); err != nil {
-		return errors.Format("%s: %w", msg, err)
-	}
-	return nil
+	return g, nil
 }
 
 // graph represents the task DAG derived per schema.md D1.
@@ -155,6 +255,40 @@ type graph struct {
 	pred map[string]map[string]struct{}
 	// files holds input paths read from the component directory, sorted.
 	files []string
+	// producers maps each declared output to the task producing it.
+	producers map[string]string
+	// outputs holds every declared output, sorted for deterministic prefix
+	// matching.
+	outputs []string
+	// artifacts maps each final artifact path to the sink task writing it.
+	artifacts map[string]string
+	// externalDeps maps a task to the canonical task ids it depends on.
+	externalDeps map[string][]string
+	// externalInputs maps a task to the canonical store paths it consumes.
+	externalInputs map[string][]string
+	// inputs maps a task to its inputs and the tasks producing each one.
+	inputs map[string][]graphInput
+}
+
+// graphInput represents one task input and the tasks producing it.  An input
+// with no producers is read from the component directory.
+type graphInput struct {
+	path      string
+	producers []string
+}
+
+// firstExternal returns the first canonical reference in the graph, or the
+// empty string if every reference resolves within the TaskSet.
+func (g *graph) firstExternal() string {
+	for _, name := range g.names {
+		if refs := g.externalDeps[name]; len(refs) > 0 {
+			return fmt.Sprintf("task %s: dependsOn target %s", name, refs[0])
+		}
+		if refs := g.externalInputs[name]; len(refs) > 0 {
+			return fmt.Sprintf("task %s: input %s", name, refs[0])
+		}
+	}
+	return ""
 }
 
 // addEdge adds edge from -> to, ignoring duplicates (a duplicate edge is
@@ -174,9 +308,12 @@ func (b *TaskSet) graph() (*graph, error) {
 	tasks := b.Spec.Tasks
 
 	g := &graph{
-		names: make([]string, 0, len(tasks)),
-		succ:  make(map[string]map[string]struct{}, len(tasks)),
-		pred:  make(map[string]map[string]struct{}, len(tasks)),
+		names:          make([]string, 0, len(tasks)),
+		succ:           make(map[string]map[string]struct{}, len(tasks)),
+		pred:           make(map[string]map[string]struct{}, len(tasks)),
+		externalDeps:   make(map[string][]string),
+		externalInputs: make(map[string][]string),
+		inputs:         make(map[string][]graphInput, len(tasks)),
 	}
 	for name := range tasks {
 		g.names = append(g.names, name)
@@ -240,7 +377,15 @@ func (b *TaskSet) graph() (*graph, error) {
 		task := tasks[name]
 		for _, input := range task.Inputs {
 			path := string(input)
+			// A canonical store path consumes the output of a task in another
+			// component.  The platform merge resolves the producer.
+			if isCanonical(path) {
+				g.externalInputs[name] = append(g.externalInputs[name], path)
+				g.inputs[name] = append(g.inputs[name], graphInput{path: path})
+				continue
+			}
 			matches := matchProducers(path, producers, outputs)
+			g.inputs[name] = append(g.inputs[name], graphInput{path: path, producers: matches})
 			if len(matches) == 0 {
 				// The input must exist in the component directory.
 				if _, err := os.Stat(filepath.Join(b.Opts.AbsLeaf(), path)); err != nil {
@@ -265,10 +410,13 @@ func (b *TaskSet) graph() (*graph, error) {
 		}
 		sort.Strings(targets)
 		for _, target := range targets {
+			// A canonical task id references a task in another component.
+			// The platform merge resolves the edge.
+			if isCanonical(target) {
+				g.externalDeps[name] = append(g.externalDeps[name], target)
+				continue
+			}
 			if _, ok := tasks[target]; !ok {
-				if strings.Contains(target, ":") {
-					return nil, errors.Format("task %s: dependsOn target %s: canonical task ids are not supported by intra-component execution", name, target)
-				}
 				return nil, errors.Format("task %s: dependsOn target %s: no such task", name, target)
 			}
 			if target == name {
@@ -283,6 +431,9 @@ func (b *TaskSet) graph() (*graph, error) {
 		g.files = append(g.files, path)
 	}
 	sort.Strings(g.files)
+	g.producers = producers
+	g.outputs = outputs
+	g.artifacts = artifactPaths
 
 	if err := checkCycles(g); err != nil {
 		return nil, err
@@ -305,6 +456,14 @@ func checkPrefixFree(kind string, owners map[string]string, paths []string) erro
 	return nil
 }
 
+// isCanonical returns true if ref is a canonical reference to another
+// component: a canonical task id of the form <leaf>:<taskset>/<task> or a
+// canonical store path of the form <leaf>:<taskset>/<path>.  Task names and
+// component paths never contain a colon (schema.md D3).
+func isCanonical(ref string) bool {
+	return strings.Contains(ref, ":")
+}
+
 // matchProducers finds the tasks producing input path per schema.md D1.
 // Three rules are tried in order; the first rule yielding matches wins.
 func matchProducers(path string, producers map[string]string, outputs []string) []string {
@@ -351,8 +510,23 @@ func validateTask(name string, task core.Task) error {
 			return errors.Format("task %s: input %s: path must be relative, must not traverse outside the build directory, and must not resolve to the build directory", name, input)
 		}
 	}
+	if _, err := newRetryPolicy(task); err != nil {
+		return errors.Format("task %s: %w", name, err)
+	}
+	switch task.Order {
+	case "":
+	case orderKind, orderLabel, orderInput:
+		if task.Kind != "Resources" && task.Kind != "Join" {
+			return errors.Format("task %s: kind %s must not declare an order", name, task.Kind)
+		}
+		if (task.Kind == "Resources" && task.Order == orderInput) || (task.Kind == "Join" && task.Order == orderLabel) {
+			return errors.Format("task %s: order %s is not supported by kind %s", name, task.Order, task.Kind)
+		}
+	default:
+		return errors.Format("task %s: unsupported order %s", name, task.Order)
+	}
 	switch task.Kind {
-	case "Resources", "Helm", "File":
+	case "Resources", "Helm", "File", "Jsonnet":
 		if len(task.Inputs) != 0 {
 			return errors.Format("task %s: kind %s must not declare inputs", name, task.Kind)
 		}
@@ -362,18 +536,63 @@ func validateTask(name string, task core.Task) error {
 		if task.Kind == "File" && !validLocalPath(string(task.File.Source)) {
 			return errors.Format("task %s: file source %s: path must be relative, must not traverse outside the component directory, and must not resolve to the component directory", name, task.File.Source)
 		}
-	case "Kustomize", "Join":
+		if task.Kind == "Jsonnet" {
+			if !validLocalPath(string(task.Jsonnet.Source)) {
+				return errors.Format("task %s: jsonnet source %s: path must be relative, must not traverse outside the component directory, and must not resolve to the component directory", name, task.Jsonnet.Source)
+			}
+			for _, path := range task.Jsonnet.JPath {
+				if !validLocalPath(string(path)) {
+					return errors.Format("task %s: jsonnet jpath %s: path must be relative, must not traverse outside the component directory, and must not resolve to the component directory", name, path)
+				}
+			}
+		}
+	case "Kustomize", "Join", "Filter", "Patch", "Transform", "Split":
 		if len(task.Inputs) < 1 {
 			return errors.Format("task %s: kind %s requires at least one input", name, task.Kind)
 		}
 		if task.Output == "" {
 			return errors.Format("task %s: kind %s requires an output", name, task.Kind)
 		}
-		for path := range task.Kustomize.Files {
-			if !validLocalPath(string(path)) {
-				return errors.Format("task %s: kustomize file %s: path must be relative, must not traverse outside the kustomize directory, and must not resolve to the kustomize directory", name, path)
+		// Each kind validates only its own field, the fields of other kinds
+		// are ignored at run time.
+		switch task.Kind {
+		case "Kustomize":
+			if err := validateKustomize(task.Kustomize); err != nil {
+				return errors.Format("task %s: %w", name, err)
+			}
+		case "Filter":
+			if _, err := newResourceFilter(task.Filter); err != nil {
+				return errors.Format("task %s: filter %w", name, err)
+			}
+		case "Patch":
+			if len(task.Patch.Patches) == 0 {
+				return errors.Format("task %s: kind Patch requires at least one patch", name)
+			}
+			if _, err := newResourcePatches(task.Patch); err != nil {
+				return errors.Format("task %s: %w", name, err)
+			}
+		case "Transform":
+			if _, err := newTransformer(task.Transform); err != nil {
+				return errors.Format("task %s: transform %w", name, err)
+			}
+		case "Split":
+			if _, err := newSplitter(task.Split); err != nil {
+				return errors.Format("task %s: split %w", name, err)
 			}
 		}
+	case "CUE":
+		if len(task.Inputs) < 1 {
+			return errors.Format("task %s: kind CUE requires at least one input", name)
+		}
+		if !validLocalPath(string(task.CUE.Package)) {
+			return errors.Format("task %s: cue package %s: path must be relative, must not traverse outside the platform root, and must not resolve to the platform root", name, task.CUE.Package)
+		}
+		if task.Output != "" && task.CUE.Expression == "" {
+			return errors.Format("task %s: kind CUE requires an expression when declaring an output", name)
+		}
+		if task.Output == "" && task.CUE.Expression != "" {
+			return errors.Format("task %s: kind CUE requires an output when declaring an expression", name)
+		}
 	case "Command":
 		if len(task.Command.Args) < 1 {
 			return errors.Format("task %s: command args length must be at least 1", name)
@@ -386,6 +605,9 @@ func validateTask(name string, task core.Task) error {
 				return errors.Format("task %s: command stdin %s must be one of the task inputs", name, stdin)
 			}
 		}
+		if dir := string(task.Command.WorkDir); dir != "" && !filepath.IsLocal(dir) {
+			return errors.Format("task %s: command work dir %s: path must be relative and must not traverse outside the platform root", name, dir)
+		}
 	case "Artifact":
 		if len(task.Inputs) != 1 {
 			return errors.Format("task %s: kind Artifact requires exactly one input", name)
@@ -402,6 +624,80 @@ func validateTask(name string, task core.Task) error {
 	return nil
 }
 
+// validateKustomize returns an error if the build options of a Kustomize task
+// are invalid.
+func validateKustomize(k core.Kustomize) error {
+	switch restrictor := k.LoadRestrictor; restrictor {
+	case "", types.LoadRestrictionsRootOnly.String(), types.LoadRestrictionsNone.String():
+	default:
+		return errors.Format("unsupported kustomize load restrictor %s", restrictor)
+	}
+	for path := range k.Files {
+		if !validLocalPath(string(path)) {
+			return errors.Format("kustomize file %s: path must be relative, must not traverse outside the kustomize directory, and must not resolve to the kustomize directory", path)
+		}
+	}
+	fn := k.EnableExec || k.Network || k.NetworkName != "" || len(k.Mounts) > 0 || len(k.Env) > 0 || k.AsCurrentUser
+	if fn && !k.EnableAlphaPlugins {
+		return errors.Format("kustomize function options require enableAlphaPlugins")
+	}
+	if k.NetworkName != "" && !k.Network {
+		return errors.Format("kustomize networkName requires network")
+	}
+	return nil
+}
+
+// defaultHelmTimeout bounds Helm tasks declaring no timeout so an unreachable
+// chart repository cannot hang the render.
+const defaultHelmTimeout = 5 * time.Minute
+
+// defaultBackoff represents the wait before the second attempt of a task
+// declaring retry attempts but no backoff.
+const defaultBackoff = time.Second
+
+// retryPolicy represents the parsed timeout and retry fields of a [core.Task].
+type retryPolicy struct {
+	timeout  time.Duration
+	attempts int
+	backoff  time.Duration
+}
+
+// newRetryPolicy parses the timeout and retry fields of task, applying
+// defaults.  A zero timeout means no timeout.
+func newRetryPolicy(task core.Task) (retryPolicy, error) {
+	policy := retryPolicy{
+		attempts: max(1, task.Retry.Attempts),
+		backoff:  defaultBackoff,
+	}
+	if task.Kind == "Helm" {
+		policy.timeout = defaultHelmTimeout
+	}
+	if task.Timeout != "" {
+		timeout, err := time.ParseDuration(task.Timeout)
+		if err != nil {
+			return policy, errors.Format("invalid timeout: %w", err)
+		}
+		if timeout <= 0 {
+			return policy, errors.Format("invalid timeout %s: must be positive", task.Timeout)
+		}
+		policy.timeout = timeout
+	}
+	if task.Retry.Attempts < 0 {
+		return policy, errors.Format("invalid retry attempts %d: must not be negative", task.Retry.Attempts)
+	}
+	if task.Retry.Backoff != "" {
+		backoff, err := time.ParseDuration(task.Retry.Backoff)
+		if err != nil {
+			return policy, errors.Format("invalid retry backoff: %w", err)
+		}
+		if backoff < 0 {
+			return policy, errors.Format("invalid retry backoff %s: must not be negative", task.Retry.Backoff)
+		}
+		policy.backoff = backoff
+	}
+	return policy, nil
+}
+
 func validLocalPath(path string) bool {
 	return filepath.IsLocal(path) && filepath.Clean(path) != "."
 }
@@ -468,12 +764,13 @@ func checkCycles(g *graph) error {
 	}
 }
 
-// execute runs tasks in topological order.  Ready tasks run concurrently on
-// an errgroup bounded by Opts.Concurrency.  The ready queue is kept sorted by
-// task name so dispatch order is a deterministic function of completion order.
-func (b *TaskSet) execute(ctx context.Context, g *graph) error {
+// execute runs the graph nodes in topological order calling run for each.
+// Ready nodes run concurrently on an errgroup bounded by concurrency.  The
+// ready queue is kept sorted by name so dispatch order is a deterministic
+// function of completion order (rendering.md R7).
+func execute(ctx context.Context, g *graph, concurrency int, run func(context.Context, string) error) error {
 	eg, egctx := errgroup.WithContext(ctx)
-	eg.SetLimit(max(1, b.Opts.Concurrency))
+	eg.SetLimit(max(1, concurrency))
 
 	indegree := make(map[string]int, len(g.names))
 	ready := make([]string, 0, len(g.names))
@@ -498,7 +795,7 @@ func (b *TaskSet) execute(ctx context.Context, g *graph) error {
 				if err := egctx.Err(); err != nil {
 					return err
 				}
-				if err := b.runTask(egctx, name); err != nil {
+				if err := run(egctx, name); err != nil {
 					return err
 				}
 				completions <- name
@@ -531,6 +828,7 @@ func (b *TaskSet) runTask(ctx context.Context, name string) error {
 		task:        b.Spec.Tasks[name],
 		opts:        b.Opts,
 		sharedSave:  b.sharedSave,
+		wrote:       b.wrote,
 	}
 	if b.runHook != nil {
 		return b.runHook(ctx, name, t.run)
@@ -547,6 +845,8 @@ type taskRunner struct {
 	// sharedSave materializes a store path into the shared build temp
 	// directory at most once across concurrent tasks.
 	sharedSave func(dir, path string) error
+	// wrote records the artifact path written by an Artifact task.
+	wrote func(path string)
 }
 
 // id uniquely identifies the task for log and error messages.
@@ -562,7 +862,53 @@ func (t *taskRunner) tempDir() (string, error) {
 	}
 }
 
+// run executes the task, retrying failed attempts per the task retry policy.
+// The wait between attempts starts at the retry backoff and doubles after each
+// failed attempt.
 func (t *taskRunner) run(ctx context.Context) error {
+	log := logger.FromContext(ctx)
+	policy, err := newRetryPolicy(t.task)
+	if err != nil {
+		return errors.Format("could not build %s: %w", t.id(), err)
+	}
+	backoff := policy.backoff
+	for attempt := 1; ; attempt++ {
+		err := t.attempt(ctx, policy.timeout)
+		if err == nil {
+			return nil
+		}
+		if attempt >= policy.attempts || ctx.Err() != nil {
+			return err
+		}
+		msg := fmt.Sprintf("task %s attempt %d of %d failed: retrying in %s: %s", t.id(), attempt, policy.attempts, backoff, err)
+		log.WarnContext(ctx, msg, "attempt", attempt, "attempts", policy.attempts, "backoff", backoff, "err", err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+// attempt executes the task once by kind, bounded by timeout if not zero.
+func (t *taskRunner) attempt(parent context.Context, timeout time.Duration) error {
+	ctx := parent
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(parent, timeout)
+		defer cancel()
+	}
+	err := t.execute(ctx)
+	// Report a timeout of this attempt, not cancellation of the build.
+	if err != nil && parent.Err() == nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return errors.Format("%w: timed out after %s", err, timeout)
+	}
+	return err
+}
+
+// execute executes the task once by kind.
+func (t *taskRunner) execute(ctx context.Context) error {
 	log := logger.FromContext(ctx)
 	log.DebugContext(ctx, fmt.Sprintf("task %s starting", t.id()))
 	msg := fmt.Sprintf("could not build %s", t.id())
@@ -572,7 +918,7 @@ func (t *taskRunner) run(ctx context.Context) error {
 			return errors.Format("%s: could not generate resources: %w", msg, err)
 		}
 	case "Helm":
-		if err := t.helm(ctx); err != nil {
+		if err := t.cached(ctx, t.helm); err != nil {
 			return errors.Format("%s: could not generate helm: %w", msg, err)
 		}
 	case "File":
@@ -580,21 +926,48 @@ func (t *taskRunner) run(ctx context.Context) error {
 			return errors.Format("%s: could not generate file: %w", msg, err)
 		}
 	case "Kustomize":
-		if err := t.kustomize(ctx); err != nil {
+		if err := t.cached(ctx, t.kustomize); err != nil {
 			return errors.Format("%s: could not kustomize: %w", msg, err)
 		}
 	case "Join":
 		if err := t.join(); err != nil {
 			return errors.Format("%s: could not join: %w", msg, err)
 		}
+	case "Filter":
+		if err := t.filter(); err != nil {
+			return errors.Format("%s: could not filter: %w", msg, err)
+		}
+	case "Patch":
+		if err := t.patch(); err != nil {
+			return errors.Format("%s: could not patch: %w", msg, err)
+		}
+	case "Transform":
+		if err := t.transform(); err != nil {
+			return errors.Format("%s: could not transform: %w", msg, err)
+		}
+	case "Split":
+		if err := t.split(); err != nil {
+			return errors.Format("%s: could not split: %w", msg, err)
+		}
+	case "Jsonnet":
+		if err := t.jsonnet(); err != nil {
+			return errors.Format("%s: could not evaluate jsonnet: %w", msg, err)
+		}
+	case "CUE":
+		if err := t.cue(); err != nil {
+			return errors.Format("%s: could not evaluate cue: %w", msg, err)
+		}
 	case "Command":
-		if err := t.command(ctx); err != nil {
+		if err := t.cached(ctx, t.command); err != nil {
 			return errors.Format("%s: could not run command: %w", msg, err)
 		}
 	case "Artifact":
 		if err := t.artifact(ctx); err != nil {
 			return errors.Format("%s: could not write artifact: %w", msg, err)
 		}
+		if t.wrote != nil {
+			t.wrote(t.artifactPath())
+		}
 	default:
 		return errors.Format("%s: unsupported kind %s", msg, t.task.Kind)
 	}
@@ -604,21 +977,9 @@ func (t *taskRunner) run(ctx context.Context) error {
 
 // resources marshals kubernetes resources defined in CUE into the output.
 func (t *taskRunner) resources() error {
-	var size int
-	for _, m := range t.task.Resources {
-		size += len(m)
-	}
-	list := make([]core.Resource, 0, size)
-
-	for _, m := range t.task.Resources {
-		for _, r := range m {
-			list = append(list, r)
-		}
-	}
-
 	msg := fmt.Sprintf("could not generate %s for %s", t.task.Output, t.id())
 
-	buf, err := marshal(list)
+	buf, err := marshal(sortedResources(t.task))
 	if err != nil {
 		return errors.Format("%s: %w", msg, err)
 	}
@@ -642,9 +1003,25 @@ func (t *taskRunner) file() error {
 	return nil
 }
 
-// helm renders a helm chart into the output.  The chart is cached per version
-// per component and pulled at most once guarded by a filesystem lock.
-func (t *taskRunner) helm(ctx context.Context) error {
+// authCredentials resolves the username and password of auth, registering both
+// as sensitive values so they are redacted from logs and errors.
+func authCredentials(auth core.Auth) (username, password string) {
+	username = auth.Username.Value
+	if username == "" {
+		username = os.Getenv(auth.Username.FromEnv)
+	}
+	password = auth.Password.Value
+	if password == "" {
+		password = os.Getenv(auth.Password.FromEnv)
+	}
+	redact.Value(username, password)
+	return username, password
+}
+
+// helmChart returns the path to the vendored chart, pulling the chart if
+// necessary.  The chart is cached per version per component and pulled at most
+// once guarded by a filesystem lock.
+func (t *taskRunner) helmChart(ctx context.Context) (string, error) {
 	h := t.task.Helm
 	// Cache the chart by version to pull new versions. (#273)
 	cacheDir := filepath.Join(t.opts.AbsLeaf(), "vendor", h.Chart.Version)
@@ -652,118 +1029,73 @@ func (t *taskRunner) helm(ctx context.Context) error {
 
 	log := logger.FromContext(ctx)
 
-	username := h.Chart.Repository.Auth.Username.Value
-	if username == "" {
-		username = os.Getenv(h.Chart.Repository.Auth.Username.FromEnv)
-	}
-	password := h.Chart.Repository.Auth.Password.Value
-	if password == "" {
-		password = os.Getenv(h.Chart.Repository.Auth.Password.FromEnv)
-	}
+	username, password := authCredentials(h.Chart.Repository.Auth)
 
+	// The pull is bounded by the task timeout.
 	if _, err := os.Stat(cachePath); os.IsNotExist(err) {
-		err := func() error {
-			ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
-			defer cancel()
-			return onceWithLock(log, ctx, cachePath, func() error {
-				return errors.Wrap(helm.PullChart(
-					ctx,
-					cli.New(),
-					h.Chart.Name,
-					h.Chart.Version,
-					h.Chart.Repository.URL,
-					cacheDir,
-					username,
-					password,
-				))
-			})
-		}()
+		err := onceWithLock(log, ctx, cachePath, func() error {
+			ctx, span := trace.Start(ctx, "helm", "helm pull", "chart", h.Chart.Name, "version", h.Chart.Version)
+			defer span.End()
+			return errors.Wrap(helm.PullChart(
+				ctx,
+				cli.New(),
+				h.Chart.Name,
+				h.Chart.Version,
+				h.Chart.Repository.URL,
+				cacheDir,
+				username,
+				password,
+			))
+		})
 		if err != nil {
-			return errors.Format("could not cache chart: %w", err)
+			return "", errors.Format("could not cache chart: %w", err)
 		}
 	}
+	return cachePath, nil
+}
 
-	// Write value files
-	tempDir, err := os.MkdirTemp("", "holos.helm")
+// helm renders a helm chart into the output in-process with the helm sdk.  No
+// helm executable is required.
+func (t *taskRunner) helm(ctx context.Context) error {
+	h := t.task.Helm
+	log := logger.FromContext(ctx)
+
+	cachePath, err := t.helmChart(ctx)
 	if err != nil {
-		return errors.Format("could not make temp dir: %w", err)
+		return errors.Wrap(err)
 	}
-	defer util.Remove(ctx, tempDir)
 
-	// valueFiles represents the ordered list of value files to pass to helm
-	// template -f
-	var valueFiles []string
+	// values represents the ordered list of values to merge, equivalent to
+	// helm template --values.
+	var values []map[string]any
 
 	// valueFiles for the use case of migration from helm value hierarchies.
 	for _, valueFile := range h.ValueFiles {
-		var data []byte
 		switch valueFile.Kind {
 		case "Values":
-			if data, err = yaml.Marshal(valueFile.Values); err != nil {
-				return errors.Format("could not marshal value file %s: %w", valueFile.Name, err)
-			}
+			values = append(values, valueFile.Values)
 		default:
-			return errors.Format("could not marshal value file %s: unknown kind %s", valueFile.Name, valueFile.Kind)
+			return errors.Format("could not merge value file %s: unknown kind %s", valueFile.Name, valueFile.Kind)
 		}
-
-		valuesPath := filepath.Join(tempDir, valueFile.Name)
-		if err := os.WriteFile(valuesPath, data, 0666); err != nil {
-			return errors.Wrap(fmt.Errorf("could not write value file %s: %w", valueFile.Name, err))
-		}
-		log.DebugContext(ctx, fmt.Sprintf("wrote: %s", valuesPath))
-		valueFiles = append(valueFiles, valuesPath)
-	}
-
-	// The final values files
-	data, err := yaml.Marshal(h.Values)
-	if err != nil {
-		return errors.Format("could not marshal values: %w", err)
-	}
-
-	valuesPath := filepath.Join(tempDir, "values.yaml")
-	if err := os.WriteFile(valuesPath, data, 0666); err != nil {
-		return errors.Wrap(fmt.Errorf("could not write values: %w", err))
-	}
-	log.DebugContext(ctx, fmt.Sprintf("wrote: %s", valuesPath))
-	valueFiles = append(valueFiles, valuesPath)
-
-	// Run charts
-	args := []string{"template"}
-	if !h.EnableHooks {
-		args = append(args, "--no-hooks")
-	}
-	for _, apiVersion := range h.APIVersions {
-		args = append(args, "--api-versions", apiVersion)
 	}
-	if kubeVersion := h.KubeVersion; kubeVersion != "" {
-		args = append(args, "--kube-version", kubeVersion)
-	}
-	args = append(args, "--include-crds")
-	for _, valueFilePath := range valueFiles {
-		args = append(args, "--values", valueFilePath)
-	}
-	args = append(args,
-		"--namespace", h.Namespace,
-		"--kubeconfig", "/dev/null",
-		"--version", h.Chart.Version,
-		h.Chart.Release,
-		cachePath,
-	)
-	helmOut, err := util.RunCmd(ctx, "helm", args...)
+	// The final values take precedence.
+	values = append(values, h.Values)
+
+	out, err := helm.Template(ctx, cachePath, helm.TemplateOptions{
+		ReleaseName: h.Chart.Release,
+		Namespace:   h.Namespace,
+		Values:      values,
+		EnableHooks: h.EnableHooks,
+		APIVersions: h.APIVersions,
+		KubeVersion: h.KubeVersion,
+		IncludeCRDs: true,
+	})
 	if err != nil {
-		stderr := helmOut.Stderr.String()
-		lines := strings.Split(stderr, "\n")
-		for _, line := range lines {
-			log.DebugContext(ctx, line)
-			if strings.HasPrefix(line, "Error:") {
-				err = fmt.Errorf("%s: %w", line, err)
-			}
-		}
-		return errors.Format("could not run helm template: %w", err)
+		return errors.Format("could not render helm chart %s: %w", h.Chart.Name, err)
 	}
 
 	// Set the artifact
-	if err := t.opts.Store.Set(string(t.task.Output), helmOut.Stdout.Bytes()); err != nil {
+	if err := t.opts.Store.Set(string(t.task.Output), out); err != nil {
 		return errors.Format("could not store helm output: %w", err)
 	}
 	log.Debug("set artifact: " + string(t.task.Output))
@@ -771,61 +1103,114 @@ func (t *taskRunner) helm(ctx context.Context) error {
 	return nil
 }
 
-// kustomize executes the kustomize command in an isolated temporary directory
-// and captures standard output.
+// kustomize builds the kustomization in-process with the kustomize API.  The
+// kustomization, files, and inputs are written to an in-memory filesystem, or
+// to an isolated temporary directory if helm or alpha plugins are enabled.
+// Without load restrictions the temporary directory is within the component
+// directory so references outside of the kustomization root, for example
+// ../base, resolve against the component directory.
 func (t *taskRunner) kustomize(ctx context.Context) error {
 	store := t.opts.Store
 	msg := fmt.Sprintf("could not transform %s for %s", t.task.Output, t.id())
-
-	// Unlike other tasks, kustomize operates in a dedicated temporary directory.
-	tempDir, err := os.MkdirTemp("", "holos.kustomize")
-	if err != nil {
-		return errors.Wrap(err)
+	k := t.task.Kustomize
+
+	fSys, root := filesys.MakeFsInMemory(), "/"
+	if k.EnableHelm || k.EnableAlphaPlugins || k.LoadRestrictor == types.LoadRestrictionsNone.String() {
+		// Helm and plugins execute outside of holos and read files from disk.
+		var dir string
+		if k.LoadRestrictor == types.LoadRestrictionsNone.String() {
+			dir = t.opts.AbsLeaf()
+		}
+		tempDir, err := os.MkdirTemp(dir, ".holos.kustomize")
+		if err != nil {
+			return errors.Wrap(err)
+		}
+		defer util.Remove(ctx, tempDir)
+		fSys, root = filesys.MakeFsOnDisk(), tempDir
+	}
+	write := func(name string, data []byte) error {
+		path := filepath.Join(root, name)
+		if err := fSys.MkdirAll(filepath.Dir(path)); err != nil {
+			return errors.Wrap(err)
+		}
+		return errors.Wrap(fSys.WriteFile(path, data))
 	}
-	defer util.Remove(ctx, tempDir)
 
 	// Write the kustomization
-	data, err := yaml.Marshal(t.task.Kustomize.Kustomization)
+	data, err := yaml.Marshal(k.Kustomization)
 	if err != nil {
 		return errors.Format("%s: %w", msg, err)
 	}
-	path := filepath.Join(tempDir, "kustomization.yaml")
-	if err := os.WriteFile(path, data, 0666); err != nil {
+	if err := write("kustomization.yaml", data); err != nil {
 		return errors.Format("%s: %w", msg, err)
 	}
 
 	// Write additional files, e.g. patch files.
-	for name, content := range t.task.Kustomize.Files {
-		path := filepath.Join(tempDir, string(name))
-		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
-			return errors.Format("%s: %w", msg, err)
-		}
-		if err := os.WriteFile(path, []byte(content), 0666); err != nil {
+	for name, content := range k.Files {
+		if err := write(string(name), []byte(content)); err != nil {
 			return errors.Format("%s: %w", msg, err)
 		}
 	}
 
-	// Write the inputs
+	// Write the inputs, including every file of a directory input.
+	keys := store.Keys()
+	sort.Strings(keys)
 	for _, input := range t.task.Inputs {
-		if err := store.Save(tempDir, string(input)); err != nil {
-			return errors.Format("%s: %w", msg, err)
+		path := string(input)
+		for _, key := range keys {
+			if key != path && !strings.HasPrefix(key, path+"/") {
+				continue
+			}
+			data, _ := store.Get(key)
+			if err := write(key, data); err != nil {
+				return errors.Format("%s: %w", msg, err)
+			}
 		}
 	}
 
-	// Execute kustomize
-	r, err := util.RunCmdW(ctx, t.opts.Stderr, "kubectl", "kustomize", tempDir)
+	// Build the kustomization
+	resMap, err := krusty.MakeKustomizer(kustomizeOptions(k)).Run(fSys, root)
 	if err != nil {
 		return errors.Format("%s: could not run kustomize: %w", msg, err)
 	}
+	out, err := resMap.AsYaml()
+	if err != nil {
+		return errors.Format("%s: %w", msg, err)
+	}
 
 	// Store the artifact
-	if err := store.Set(string(t.task.Output), r.Stdout.Bytes()); err != nil {
+	if err := store.Set(string(t.task.Output), out); err != nil {
 		return errors.Format("%s: %w", msg, err)
 	}
 
 	return nil
 }
 
+// kustomizeOptions returns the krusty options equivalent to the kustomize
+// build flags of k.  The resource order matches kubectl kustomize, honoring
+// the sortOptions field of the kustomization.
+func kustomizeOptions(k core.Kustomize) *krusty.Options {
+	opts := krusty.MakeDefaultOptions()
+	opts.Reorder = krusty.ReorderOptionUnspecified
+	if k.LoadRestrictor == types.LoadRestrictionsNone.String() {
+		opts.LoadRestrictions = types.LoadRestrictionsNone
+	}
+	if k.EnableAlphaPlugins {
+		opts.PluginConfig = types.EnabledPluginConfig(types.BploUseStaticallyLinked)
+		opts.PluginConfig.FnpLoadingOptions = types.FnPluginLoadingOptions{
+			EnableExec:    k.EnableExec,
+			Network:       k.Network,
+			NetworkName:   cmp.Or(k.NetworkName, "bridge"),
+			Mounts:        k.Mounts,
+			Env:           k.Env,
+			AsCurrentUser: k.AsCurrentUser,
+		}
+	}
+	opts.PluginConfig.HelmConfig.Enabled = k.EnableHelm
+	opts.PluginConfig.HelmConfig.Command = cmp.Or(k.HelmCommand, "helm")
+	return opts
+}
+
 // join concatenates the inputs into the output with a separator.
 func (t *taskRunner) join() error {
 	store := t.opts.Store
@@ -843,6 +1228,11 @@ func (t *taskRunner) join() error {
 	}
 	// Join the inputs
 	data := bytes.Join(s, []byte(t.task.Join.Separator))
+	if t.task.Order == orderKind {
+		if data, err = sortDocuments(data, t.task.Join.Separator); err != nil {
+			return errors.Format("could not order %s: %w", t.task.Output, err)
+		}
+	}
 	// Save the output to the filesystem.
 	outPath := filepath.Join(tempDir, string(t.task.Output))
 	if err := os.MkdirAll(filepath.Dir(outPath), 0o777); err != nil {
@@ -859,9 +1249,10 @@ func (t *taskRunner) join() error {
 }
 
 // command executes a user defined command with the working directory set to
-// the platform root.  Declared inputs are materialized in the build temp dir
-// before the command runs.  A command with an output generates or transforms;
-// a command with only inputs validates.
+// the platform root, or to WorkDir relative to the platform root.  Declared
+// inputs are materialized in the build temp dir before the command runs and
+// their paths are exported as HOLOS_INPUT_<n>.  A command with an output
+// generates or transforms; a command with only inputs validates.
 func (t *taskRunner) command(ctx context.Context) error {
 	store := t.opts.Store
 
@@ -884,10 +1275,21 @@ func (t *taskRunner) command(ctx context.Context) error {
 		}
 	}
 
-	// Set the command working directory to the platform root and wire stdin
-	// to the named input.
+	// The command writes the output to HOLOS_OUTPUT unless holos captures
+	// stdout.
+	output := string(t.task.Output)
+	outPath := filepath.Join(tempDir, output)
+	if output != "" {
+		if err := os.MkdirAll(filepath.Dir(outPath), 0o777); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+
+	// Set the command working directory and environment and wire stdin to
+	// the named input.
 	preRun := func(c *exec.Cmd) error {
-		c.Dir = t.opts.Root()
+		c.Dir = filepath.Join(t.opts.Root(), string(t.task.Command.WorkDir))
+		c.Env = t.commandEnv(tempDir, outPath)
 		if stdin := string(t.task.Command.Stdin); stdin != "" {
 			data, ok := store.Get(stdin)
 			if !ok {
@@ -903,17 +1305,12 @@ func (t *taskRunner) command(ctx context.Context) error {
 	}
 
 	// A command with no output validates; there is nothing to store.
-	output := string(t.task.Output)
 	if output == "" {
 		return nil
 	}
 
 	// Save the output.
-	outPath := filepath.Join(tempDir, output)
 	if t.task.Command.IsStdoutOutput {
-		if err := os.MkdirAll(filepath.Dir(outPath), 0o777); err != nil {
-			return errors.Wrap(err)
-		}
 		if err := os.WriteFile(outPath, r.Stdout.Bytes(), 0o666); err != nil {
 			return errors.Wrap(err)
 		}
@@ -925,18 +1322,48 @@ func (t *taskRunner) command(ctx context.Context) error {
 	return nil
 }
 
+// commandEnv returns the environment of a command task.  Later values take
+// precedence: the inherited environment unless cleared, then Env, then the
+// HOLOS_INPUT_<n> and HOLOS_OUTPUT variables.
+func (t *taskRunner) commandEnv(tempDir, outPath string) []string {
+	c := t.task.Command
+	// A nil env inherits the holos environment, so clear with an empty slice.
+	env := []string{}
+	if !c.ClearEnv {
+		env = os.Environ()
+	}
+	for _, key := range slices.Sorted(maps.Keys(c.Env)) {
+		env = append(env, key+"="+c.Env[key])
+	}
+	for idx, input := range t.task.Inputs {
+		env = append(env, fmt.Sprintf("HOLOS_INPUT_%d=%s", idx, filepath.Join(tempDir, string(input))))
+	}
+	if t.task.Output != "" && !c.IsStdoutOutput {
+		env = append(env, "HOLOS_OUTPUT="+outPath)
+	}
+	return env
+}
+
+// artifactPath returns the artifact path relative to the write-to directory.
+// The path defaults to the input store path.
+func (t *taskRunner) artifactPath() string {
+	if path := string(t.task.Artifact.Path); path != "" {
+		return path
+	}
+	return string(t.task.Inputs[0])
+}
+
 // artifact writes the single input from the artifact store to the final
 // artifact path relative to the write-to directory (schema.md D2).
 func (t *taskRunner) artifact(ctx context.Context) error {
 	store := t.opts.Store
 	input := string(t.task.Inputs[0])
-	path := string(t.task.Artifact.Path)
-	if path == "" {
-		path = input
-	}
+	path := t.artifactPath()
 
 	log := logger.FromContext(ctx)
 	fullPath := filepath.Join(t.opts.AbsWriteTo(), path)
+	_, span := trace.Start(ctx, "artifact", "artifact write", "path", path)
+	defer span.End()
 
 	// Replace the destination so a re-render never leaves stale files from a
 	// previous render under a directory artifact.
@@ -987,6 +1414,13 @@ func (t *taskRunner) artifact(ctx context.Context) error {
 	return nil
 }
 
+// decodeJSON decodes the json data into v.  The json is decoded as yaml, a
+// superset of json, so integers remain integers instead of becoming float64
+// values rendered in exponent notation.
+func decodeJSON(data []byte, v any) error {
+	return yaml.Unmarshal(data, v)
+}
+
 func marshal(list []core.Resource) (buf bytes.Buffer, err error) {
 	encoder := yaml.NewEncoder(&buf)
 	defer encoder.Close()
@@ -467,6 +467,52 @@ func TestBuildSharedCommandInputs(t *testing.T) {
 	}
 }
 
+func TestBuildCommandEnv(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test depends on the sh command")
+	}
+	t.Setenv("HOLOS_TEST_INHERITED", "inherited")
+	script := `printf '%s %s %s\n' "$GREETING" "${HOLOS_TEST_INHERITED-cleared}" "$(pwd)" > "$HOLOS_OUTPUT"
+cat "$HOLOS_INPUT_0" "$HOLOS_INPUT_1" >> "$HOLOS_OUTPUT"`
+	command := func(clearEnv bool) core.Task {
+		return core.Task{
+			Kind:   "Command",
+			Inputs: []core.FileOrDirectoryPath{"a.gen.yaml", "b.gen.yaml"},
+			Output: "out/command.gen.yaml",
+			Command: core.Command{
+				Args:     []string{"sh", "-c", script},
+				Env:      map[string]string{"GREETING": "hello"},
+				ClearEnv: clearEnv,
+				WorkDir:  "components/test",
+			},
+		}
+	}
+
+	for _, tc := range []struct {
+		name     string
+		clearEnv bool
+		want     string
+	}{
+		{name: "Inherited", want: "hello inherited"},
+		{name: "ClearEnv", clearEnv: true, want: "hello cleared"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := newTestTaskSet(t, map[string]core.Task{
+				"a":       resourcesTask("a", "a.gen.yaml"),
+				"b":       resourcesTask("b", "b.gen.yaml"),
+				"command": command(tc.clearEnv),
+			})
+			require.NoError(t, b.Build(t.Context()))
+
+			a, _ := b.Opts.Store.Get("a.gen.yaml")
+			bdata, _ := b.Opts.Store.Get("b.gen.yaml")
+			data, ok := b.Opts.Store.Get("out/command.gen.yaml")
+			require.True(t, ok)
+			assert.Equal(t, tc.want+" "+b.Opts.AbsLeaf()+"\n"+string(a)+string(bdata), string(data))
+		})
+	}
+}
+
 func TestBuildOverlappingArtifactPathError(t *testing.T) {
 	b := newTestTaskSet(t, map[string]core.Task{
 		"gen-a": resourcesTask("a", "a.gen.yaml"),
@@ -702,6 +748,88 @@ func TestBuildTaskFailureStopsBuild(t *testing.T) {
 	assert.NotContains(t, *order, "deploy", "expected downstream task to be skipped on failure")
 }
 
+func TestBuildTaskTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test depends on the sleep command")
+	}
+	b := newTestTaskSet(t, map[string]core.Task{
+		"slow": {
+			Kind:    "Command",
+			Timeout: "100ms",
+			Command: core.Command{Args: []string{"sleep", "10"}},
+		},
+	})
+
+	start := time.Now()
+	err := b.Build(t.Context())
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "timed out after 100ms")
+	assert.Less(t, time.Since(start), 5*time.Second, "expected the timeout to stop the command")
+}
+
+func TestBuildTaskRetry(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test depends on the sh command")
+	}
+	// flaky fails until it has been attempted n times.
+	flaky := func(n int, retry core.Retry) core.Task {
+		script := fmt.Sprintf("echo run >> attempts.log && test $(wc -l < attempts.log) -ge %d", n)
+		return core.Task{
+			Kind:    "Command",
+			Retry:   retry,
+			Command: core.Command{Args: []string{"sh", "-c", script}},
+		}
+	}
+	attempts := func(t *testing.T, b *TaskSet) int {
+		data, err := os.ReadFile(filepath.Join(b.Opts.Root(), "attempts.log"))
+		require.NoError(t, err)
+		return bytes.Count(data, []byte("\n"))
+	}
+
+	t.Run("Recovers", func(t *testing.T) {
+		b := newTestTaskSet(t, map[string]core.Task{
+			"flaky": flaky(2, core.Retry{Attempts: 3, Backoff: "1ms"}),
+		})
+		require.NoError(t, b.Build(t.Context()))
+		assert.Equal(t, 2, attempts(t, b))
+	})
+
+	t.Run("Exhausted", func(t *testing.T) {
+		b := newTestTaskSet(t, map[string]core.Task{
+			"flaky": flaky(10, core.Retry{Attempts: 3, Backoff: "1ms"}),
+		})
+		require.Error(t, b.Build(t.Context()))
+		assert.Equal(t, 3, attempts(t, b))
+	})
+
+	t.Run("NoRetryByDefault", func(t *testing.T) {
+		b := newTestTaskSet(t, map[string]core.Task{
+			"flaky": flaky(2, core.Retry{}),
+		})
+		require.Error(t, b.Build(t.Context()))
+		assert.Equal(t, 1, attempts(t, b))
+	})
+}
+
+func TestNewRetryPolicy(t *testing.T) {
+	policy, err := newRetryPolicy(core.Task{Kind: "Helm"})
+	require.NoError(t, err)
+	assert.Equal(t, retryPolicy{timeout: 5 * time.Minute, attempts: 1, backoff: time.Second}, policy)
+
+	policy, err = newRetryPolicy(core.Task{Kind: "Helm", Timeout: "30s", Retry: core.Retry{Attempts: 4, Backoff: "2s"}})
+	require.NoError(t, err)
+	assert.Equal(t, retryPolicy{timeout: 30 * time.Second, attempts: 4, backoff: 2 * time.Second}, policy)
+
+	policy, err = newRetryPolicy(core.Task{Kind: "Command"})
+	require.NoError(t, err)
+	assert.Zero(t, policy.timeout, "expected no timeout by default")
+
+	// Zero attempts is the unset value and executes the task once.
+	policy, err = newRetryPolicy(core.Task{Kind: "Command", Retry: core.Retry{Attempts: 0}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, policy.attempts)
+}
+
 func TestBuildCommandStdin(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("test depends on the cat command")
@@ -855,6 +983,151 @@ func TestValidateTask(t *testing.T) {
 			},
 			errText: "must be one of the task inputs",
 		},
+		{
+			name:    "FilterWithoutInputs",
+			task:    core.Task{Kind: "Filter", Output: "a.yaml"},
+			errText: "kind Filter requires at least one input",
+		},
+		{
+			name: "FilterInvalidLabelSelector",
+			task: core.Task{
+				Kind:   "Filter",
+				Inputs: []core.FileOrDirectoryPath{"a.yaml"},
+				Output: "b.yaml",
+				Filter: core.Filter{Include: []core.ResourceSelector{{LabelSelector: "app"}}},
+			},
+			errText: `filter include 0: invalid label selector "app"`,
+		},
+		{
+			name:    "PatchWithoutPatches",
+			task:    core.Task{Kind: "Patch", Inputs: []core.FileOrDirectoryPath{"a.yaml"}, Output: "b.yaml"},
+			errText: "kind Patch requires at least one patch",
+		},
+		{
+			name: "PatchWithoutOperations",
+			task: core.Task{
+				Kind:   "Patch",
+				Inputs: []core.FileOrDirectoryPath{"a.yaml"},
+				Output: "b.yaml",
+				Patch:  core.Patch{Patches: []core.ResourcePatch{{Type: "JSON6902"}}},
+			},
+			errText: "type JSON6902 requires operations",
+		},
+		{
+			name: "PatchUnsupportedType",
+			task: core.Task{
+				Kind:   "Patch",
+				Inputs: []core.FileOrDirectoryPath{"a.yaml"},
+				Output: "b.yaml",
+				Patch:  core.Patch{Patches: []core.ResourcePatch{{Type: "Replace"}}},
+			},
+			errText: "unsupported patch type Replace",
+		},
+		{
+			name: "TransformInvalidRemoveField",
+			task: core.Task{
+				Kind:      "Transform",
+				Inputs:    []core.FileOrDirectoryPath{"a.yaml"},
+				Output:    "b.yaml",
+				Transform: core.Transform{RemoveFields: []string{"status"}},
+			},
+			errText: `invalid json pointer "status"`,
+		},
+		{
+			name:    "JsonnetTraversal",
+			task:    core.Task{Kind: "Jsonnet", Output: "a.yaml", Jsonnet: core.Jsonnet{Source: "../main.jsonnet"}},
+			errText: "jsonnet source ../main.jsonnet: path must be relative",
+		},
+		{
+			name:    "JsonnetJPathTraversal",
+			task:    core.Task{Kind: "Jsonnet", Output: "a.yaml", Jsonnet: core.Jsonnet{Source: "main.jsonnet", JPath: []core.FilePath{"vendor", "../../.."}}},
+			errText: "jsonnet jpath ../../..: path must be relative",
+		},
+		{
+			name:    "CommandWorkDirTraversal",
+			task:    core.Task{Kind: "Command", Command: core.Command{Args: []string{"true"}, WorkDir: "../other"}},
+			errText: "command work dir ../other: path must be relative",
+		},
+		{
+			name:    "CUEWithoutInputs",
+			task:    core.Task{Kind: "CUE", CUE: core.CUE{Package: "policy"}},
+			errText: "kind CUE requires at least one input",
+		},
+		{
+			name:    "CUEOutputWithoutExpression",
+			task:    core.Task{Kind: "CUE", Inputs: []core.FileOrDirectoryPath{"a.yaml"}, Output: "b.yaml", CUE: core.CUE{Package: "policy"}},
+			errText: "kind CUE requires an expression when declaring an output",
+		},
+		{
+			name:    "CUEExpressionWithoutOutput",
+			task:    core.Task{Kind: "CUE", Inputs: []core.FileOrDirectoryPath{"a.yaml"}, CUE: core.CUE{Package: "policy", Expression: "out"}},
+			errText: "kind CUE requires an output when declaring an expression",
+		},
+		{
+			name:    "SplitWithoutInputs",
+			task:    core.Task{Kind: "Split", Output: "manifests"},
+			errText: "kind Split requires at least one input",
+		},
+		{
+			name: "SplitUnknownPlaceholder",
+			task: core.Task{
+				Kind:   "Split",
+				Inputs: []core.FileOrDirectoryPath{"a.yaml"},
+				Output: "manifests",
+				Split:  core.Split{Template: "{kind}-{uid}.yaml"},
+			},
+			errText: "unknown placeholder {uid}",
+		},
+		{
+			name: "KustomizeUnsupportedLoadRestrictor",
+			task: core.Task{
+				Kind:      "Kustomize",
+				Inputs:    []core.FileOrDirectoryPath{"a.yaml"},
+				Output:    "b.yaml",
+				Kustomize: core.Kustomize{LoadRestrictor: "LoadRestrictionsAny"},
+			},
+			errText: "unsupported kustomize load restrictor LoadRestrictionsAny",
+		},
+		{
+			name: "KustomizeExecWithoutAlphaPlugins",
+			task: core.Task{
+				Kind:      "Kustomize",
+				Inputs:    []core.FileOrDirectoryPath{"a.yaml"},
+				Output:    "b.yaml",
+				Kustomize: core.Kustomize{EnableExec: true},
+			},
+			errText: "kustomize function options require enableAlphaPlugins",
+		},
+		{
+			name:    "OrderOnHelm",
+			task:    core.Task{Kind: "Helm", Output: "a.yaml", Order: "Kind"},
+			errText: "kind Helm must not declare an order",
+		},
+		{
+			name:    "InputOrderOnResources",
+			task:    core.Task{Kind: "Resources", Output: "a.yaml", Order: "Input"},
+			errText: "order Input is not supported by kind Resources",
+		},
+		{
+			name:    "UnsupportedOrder",
+			task:    core.Task{Kind: "Join", Inputs: []core.FileOrDirectoryPath{"a.yaml"}, Output: "b.yaml", Order: "Random"},
+			errText: "unsupported order Random",
+		},
+		{
+			name:    "InvalidTimeout",
+			task:    core.Task{Kind: "Resources", Output: "a.yaml", Timeout: "soon"},
+			errText: "invalid timeout",
+		},
+		{
+			name:    "NegativeRetryAttempts",
+			task:    core.Task{Kind: "Resources", Output: "a.yaml", Retry: core.Retry{Attempts: -1}},
+			errText: "invalid retry attempts -1: must not be negative",
+		},
+		{
+			name:    "InvalidRetryBackoff",
+			task:    core.Task{Kind: "Resources", Output: "a.yaml", Retry: core.Retry{Attempts: 2, Backoff: "1"}},
+			errText: "invalid retry backoff",
+		},
 		{
 			name:    "ArtifactTwoInputs",
 			task:    core.Task{Kind: "Artifact", Inputs: []core.FileOrDirectoryPath{"a.yaml", "b.yaml"}},
@@ -38,6 +38,7 @@ func RunOneScript(t *testing.T, dir string, file string) {
 			env.Setenv("HOLOS_UPDATE_SCRIPTS", os.Getenv("HOLOS_UPDATE_SCRIPTS"))
 			// Just like cmd/cue/cmd.TestScript, set up separate cache and config dirs per test.
 			env.Setenv("CUE_CACHE_DIR", filepath.Join(env.WorkDir, "tmp/cachedir"))
+			env.Setenv("HOLOS_CACHE_DIR", filepath.Join(env.WorkDir, "tmp/holos-cache"))
 			configDir := filepath.Join(env.WorkDir, "tmp/configdir")
 			env.Setenv("CUE_CONFIG_DIR", configDir)
 			return nil
@@ -8,6 +8,8 @@ import (
 	"log/slog"
 	"path/filepath"
 	"runtime"
+
+	"github.com/holos-run/holos/internal/redact"
 )
 
 // ErrUnsupported is errors.ErrUnsupported
@@ -18,9 +20,24 @@ func NotImplemented() error {
 }
 
 // Format calls fmt.Errorf(format, a...) then wraps the error with the source
-// location of the caller.
+// location of the caller.  Sensitive values registered with package redact are
+// masked in the error message.
 func Format(format string, a ...any) error {
-	return wrap(fmt.Errorf(format, a...), 2)
+	return wrap(&redactedError{err: fmt.Errorf(format, a...)}, 2)
+}
+
+// redactedError masks sensitive values in the message of the wrapped error.
+type redactedError struct {
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return redact.String(e.err.Error())
+}
+
+// Unwrap implements error wrapping.
+func (e *redactedError) Unwrap() error {
+	return e.err
 }
 
 // As calls errors.As
@@ -72,9 +89,10 @@ func (e *ErrorAt) Unwrap() error {
 	return e.Err
 }
 
-// Error returns the error string with Source location.
+// Error returns the error string with Source location.  Sensitive values
+// registered with package redact are masked.
 func (e *ErrorAt) Error() string {
-	return e.Source.Loc() + ": " + e.Err.Error()
+	return redact.String(e.Source.Loc() + ": " + e.Err.Error())
 }
 
 func wrap(err error, skip int) error {
@@ -117,7 +135,7 @@ func Log(log *slog.Logger, ctx context.Context, level slog.Level, err error, msg
 	var errAt *ErrorAt
 	if ok := errors.As(err, &errAt); ok {
 		args = append(args,
-			slog.String("err", errAt.Unwrap().Error()),
+			slog.String("err", redact.String(errAt.Unwrap().Error())),
 			slog.String("loc", errAt.Source.Loc()),
 		)
 	} else {
@@ -125,7 +143,7 @@ func Log(log *slog.Logger, ctx context.Context, level slog.Level, err error, msg
 			source := Source{file, line}
 			args = append(args, slog.String("loc", source.Loc()))
 		}
-		args = append(args, slog.String("err", err.Error()))
+		args = append(args, slog.String("err", redact.String(err.Error())))
 	}
 	log.Log(ctx, level, msg, args...)
 }
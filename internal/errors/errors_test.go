@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/holos-run/holos/internal/errors"
+	"github.com/holos-run/holos/internal/redact"
 )
 
 const prefix = "internal/errors/errors_test.go:"
@@ -89,3 +90,15 @@ func TestUnwrap(t *testing.T) {
 		t.Fatalf("missing suffix:\n\thave: (%v)\n\twant: (%v)", have, want)
 	}
 }
+
+func TestFormatRedacts(t *testing.T) {
+	t.Cleanup(redact.Reset)
+	redact.Value("hunter2")
+
+	inner := errors.Format("could not pull: password %s", "hunter2")
+	err := errors.Format("could not render: %w", inner)
+	have, want := err.Error(), "could not pull: password [REDACTED]"
+	if !strings.HasSuffix(have, want) {
+		t.Fatalf("missing suffix:\n\thave: (%v)\n\twant: (%v)", have, want)
+	}
+}
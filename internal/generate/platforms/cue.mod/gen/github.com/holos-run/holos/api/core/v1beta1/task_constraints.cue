@@ -8,15 +8,28 @@
 // task kinds table in doc/design/v1beta1/schema.md#task-kinds.
 package core
 
+import "time"
+
 #Task: {
 	kind: string
 
+	// Durations use Go time.ParseDuration syntax, for example "30s" or "5m".
+	timeout?: time.Duration
+	retry?: backoff?: time.Duration
+
 	if kind == "Resources" {
 		resources!: #Resources
+		order?:     "Kind" | "Label"
 		helm?:      _|_
 		file?:      _|_
 		kustomize?: _|_
 		join?:      _|_
+		filter?:    _|_
+		patch?:     _|_
+		transform?: _|_
+		split?:     _|_
+		jsonnet?:   _|_
+		cue?:       _|_
 		command?:   _|_
 		artifact?:  _|_
 		inputs?:    _|_
@@ -25,10 +38,17 @@ package core
 
 	if kind == "Helm" {
 		helm!:      #Helm
+		order?:     _|_
 		resources?: _|_
 		file?:      _|_
 		kustomize?: _|_
 		join?:      _|_
+		filter?:    _|_
+		patch?:     _|_
+		transform?: _|_
+		split?:     _|_
+		jsonnet?:   _|_
+		cue?:       _|_
 		command?:   _|_
 		artifact?:  _|_
 		inputs?:    _|_
@@ -37,10 +57,17 @@ package core
 
 	if kind == "File" {
 		file!:      #File
+		order?:     _|_
 		resources?: _|_
 		helm?:      _|_
 		kustomize?: _|_
 		join?:      _|_
+		filter?:    _|_
+		patch?:     _|_
+		transform?: _|_
+		split?:     _|_
+		jsonnet?:   _|_
+		cue?:       _|_
 		command?:   _|_
 		artifact?:  _|_
 		inputs?:    _|_
@@ -49,10 +76,17 @@ package core
 
 	if kind == "Kustomize" {
 		kustomize!: #Kustomize
+		order?:     _|_
 		resources?: _|_
 		helm?:      _|_
 		file?:      _|_
 		join?:      _|_
+		filter?:    _|_
+		patch?:     _|_
+		transform?: _|_
+		split?:     _|_
+		jsonnet?:   _|_
+		cue?:       _|_
 		command?:   _|_
 		artifact?:  _|_
 		inputs!: [#FileOrDirectoryPath, ...#FileOrDirectoryPath]
@@ -61,16 +95,145 @@ package core
 
 	if kind == "Join" {
 		join!:      #Join
+		order?:     "Input" | "Kind"
+		resources?: _|_
+		helm?:      _|_
+		file?:      _|_
+		kustomize?: _|_
+		filter?:    _|_
+		patch?:     _|_
+		transform?: _|_
+		split?:     _|_
+		jsonnet?:   _|_
+		cue?:       _|_
+		command?:   _|_
+		artifact?:  _|_
+		inputs!: [#FileOrDirectoryPath, ...#FileOrDirectoryPath]
+		output!: #FileOrDirectoryPath
+	}
+
+	if kind == "Filter" {
+		filter!:    #Filter
+		order?:     _|_
+		resources?: _|_
+		helm?:      _|_
+		file?:      _|_
+		kustomize?: _|_
+		join?:      _|_
+		patch?:     _|_
+		transform?: _|_
+		split?:     _|_
+		jsonnet?:   _|_
+		cue?:       _|_
+		command?:   _|_
+		artifact?:  _|_
+		inputs!: [#FileOrDirectoryPath, ...#FileOrDirectoryPath]
+		output!: #FileOrDirectoryPath
+	}
+
+	if kind == "Patch" {
+		patch!:     #Patch
+		order?:     _|_
+		resources?: _|_
+		helm?:      _|_
+		file?:      _|_
+		kustomize?: _|_
+		join?:      _|_
+		filter?:    _|_
+		transform?: _|_
+		split?:     _|_
+		jsonnet?:   _|_
+		cue?:       _|_
+		command?:   _|_
+		artifact?:  _|_
+		inputs!: [#FileOrDirectoryPath, ...#FileOrDirectoryPath]
+		output!: #FileOrDirectoryPath
+	}
+
+	if kind == "Transform" {
+		transform!: #Transform
+		order?:     _|_
+		resources?: _|_
+		helm?:      _|_
+		file?:      _|_
+		kustomize?: _|_
+		join?:      _|_
+		filter?:    _|_
+		patch?:     _|_
+		split?:     _|_
+		jsonnet?:   _|_
+		cue?:       _|_
+		command?:   _|_
+		artifact?:  _|_
+		inputs!: [#FileOrDirectoryPath, ...#FileOrDirectoryPath]
+		output!: #FileOrDirectoryPath
+	}
+
+	if kind == "Split" {
+		split!:     #Split
+		order?:     _|_
 		resources?: _|_
 		helm?:      _|_
 		file?:      _|_
 		kustomize?: _|_
+		join?:      _|_
+		filter?:    _|_
+		patch?:     _|_
+		transform?: _|_
+		jsonnet?:   _|_
+		cue?:       _|_
 		command?:   _|_
 		artifact?:  _|_
 		inputs!: [#FileOrDirectoryPath, ...#FileOrDirectoryPath]
 		output!: #FileOrDirectoryPath
 	}
 
+	if kind == "Jsonnet" {
+		jsonnet!:   #Jsonnet
+		order?:     _|_
+		resources?: _|_
+		helm?:      _|_
+		file?:      _|_
+		kustomize?: _|_
+		join?:      _|_
+		filter?:    _|_
+		patch?:     _|_
+		transform?: _|_
+		split?:     _|_
+		cue?:       _|_
+		command?:   _|_
+		artifact?:  _|_
+		inputs?:    _|_
+		output!:    #FileOrDirectoryPath
+	}
+
+	if kind == "CUE" {
+		cue!:       #CUE
+		order?:     _|_
+		resources?: _|_
+		helm?:      _|_
+		file?:      _|_
+		kustomize?: _|_
+		join?:      _|_
+		filter?:    _|_
+		patch?:     _|_
+		transform?: _|_
+		split?:     _|_
+		jsonnet?:   _|_
+		command?:   _|_
+		artifact?:  _|_
+		inputs!: [#FileOrDirectoryPath, ...#FileOrDirectoryPath]
+
+		// A CUE task with an output exports the expression, otherwise it
+		// validates.
+		if cue.expression != _|_ {
+			output!: #FileOrDirectoryPath
+		}
+		if cue.expression == _|_ {
+			output?: _|_
+		}
+	}
+
 	if kind == "Command" {
 		command!: #Command & {
 			// A command without an argument vector cannot execute.
@@ -82,7 +245,14 @@ package core
 		file?:      _|_
 		kustomize?: _|_
 		join?:      _|_
+		filter?:    _|_
+		patch?:     _|_
+		transform?: _|_
+		split?:     _|_
+		jsonnet?:   _|_
+		cue?:       _|_
 		artifact?:  _|_
+		order?:     _|_
 
 		// Commands may declare zero or more inputs.  Regular with an empty
 		// default, rather than optional, so the stdin guards below may
@@ -106,13 +276,53 @@ package core
 
 	if kind == "Artifact" {
 		artifact!:  #Artifact
+		order?:     _|_
 		resources?: _|_
 		helm?:      _|_
 		file?:      _|_
 		kustomize?: _|_
 		join?:      _|_
+		filter?:    _|_
+		patch?:     _|_
+		transform?: _|_
+		split?:     _|_
+		jsonnet?:   _|_
+		cue?:       _|_
 		command?:   _|_
 		inputs!: [#FileOrDirectoryPath]
 		output?: _|_
 	}
 }
+
+// Each patch type requires its own patch document and forbids the other.
+#ResourcePatch: {
+	type: string
+
+	if type == "JSON6902" {
+		operations!: [#PatchOperation, ...#PatchOperation]
+		merge?: _|_
+	}
+
+	if type == "Merge" || type == "StrategicMerge" {
+		merge!: {...}
+		operations?: _|_
+	}
+}
+
+// Move and copy operations read from, the others write a value.
+#PatchOperation: {
+	op: string
+
+	if op == "move" || op == "copy" {
+		from!: string
+	}
+	if op != "move" && op != "copy" {
+		from?: _|_
+	}
+	if op == "add" || op == "replace" || op == "test" {
+		value!: _
+	}
+}
+
+// Fields to remove are JSON pointers below the document root.
+#Transform: removeFields?: [...=~"^/."]
@@ -127,15 +127,25 @@ package core
 //  3. [File] - Read a file from the component directory.
 //  4. [Kustomize] - Patch and transform prior outputs.
 //  5. [Join] - Concatenate prior outputs.
-//  6. [Command] - Execute a user-defined command.
-//  7. [Artifact] - Write the final artifact (sink).
+//  6. [Filter] - Keep or drop resources of prior outputs.
+//  7. [Patch] - Patch resources of prior outputs.
+//  8. [Transform] - Transform the metadata of resources of prior outputs.
+//  9. [Split] - Split resources of prior outputs into one file each.
+//  10. [Jsonnet] - Evaluate a Jsonnet file from the component directory.
+//  11. [CUE] - Validate or transform resources of prior outputs with CUE.
+//  12. [Command] - Execute a user-defined command.
+//  13. [Artifact] - Write the final artifact (sink).
 //
 // The Go type does not enforce the constraint; holos enforces it with
 // per-kind guards in the published CUE schema and revalidates it at execution
 // time, along with the per-kind Inputs and Output cardinality rules.
 #Task: {
 	// Kind discriminates the task behavior.
-	kind: string & ("Resources" | "Helm" | "File" | "Kustomize" | "Join" | "Command" | "Artifact") @go(Kind)
+	kind: string & ("Resources" | "Helm" | "File" | "Kustomize" | "Join" | "Filter" | "Patch" | "Transform" | "Split" | "Jsonnet" | "CUE" | "Command" | "Artifact") @go(Kind)
+
+	// Metadata represents data about the task.  Labels select the tasks to
+	// execute with the --task-selector flag of holos render.
+	metadata?: #TaskMetadata @go(Metadata)
 
 	// DependsOn declares tasks that must complete before this task runs, keyed
 	// by task name or canonical ID — a struct, not a list, so mixins compose
@@ -152,6 +162,20 @@ package core
 	// extending the rule platform-wide.
 	output?: #FileOrDirectoryPath @go(Output)
 
+	// Timeout bounds the duration of one attempt of the task in Go
+	// time.ParseDuration syntax, for example "30s" or "10m".  An attempt
+	// exceeding the timeout fails.  Helm tasks default to "5m", bounding the
+	// chart pull.  Other kinds have no timeout by default.
+	timeout?: string @go(Timeout)
+
+	// Retry re-executes a failed task, including an attempt exceeding Timeout.
+	retry?: #Retry @go(Retry)
+
+	// Order represents the order of the Kubernetes resources written by a
+	// Resources or Join task.  Resources tasks default to Kind, Join tasks
+	// default to Input.  Must not be set for other kinds.
+	order?: #Order & ("Kind" | "Label" | "Input") @go(Order)
+
 	// Resources task config.  Ignored unless kind is Resources.
 	resources?: #Resources @go(Resources)
 
@@ -167,6 +191,24 @@ package core
 	// Join task config.  Ignored unless kind is Join.
 	join?: #Join @go(Join)
 
+	// Filter task config.  Ignored unless kind is Filter.
+	filter?: #Filter @go(Filter)
+
+	// Patch task config.  Ignored unless kind is Patch.
+	patch?: #Patch @go(Patch)
+
+	// Transform task config.  Ignored unless kind is Transform.
+	transform?: #Transform @go(Transform)
+
+	// Split task config.  Ignored unless kind is Split.
+	split?: #Split @go(Split)
+
+	// Jsonnet task config.  Ignored unless kind is Jsonnet.
+	jsonnet?: #Jsonnet @go(Jsonnet)
+
+	// CUE task config.  Ignored unless kind is CUE.
+	cue?: #CUE @go(CUE)
+
 	// Command task config.  Ignored unless kind is Command.
 	command?: #Command @go(Command)
 
@@ -174,18 +216,68 @@ package core
 	artifact?: #Artifact @go(Artifact)
 }
 
+// TaskMetadata represents data about a [Task].
+//
+// Labels select tasks at render time.  Given one or more --task-selector flags,
+// holos executes only the tasks selected by at least one selector, for example
+// to skip expensive validators locally or run only security scanners in a
+// dedicated CI job.  Holos additionally executes every task producing an input
+// of a selected task.  Artifact tasks not selected by a selector execute unless
+// every task they depend on is pruned.
+#TaskMetadata: {
+	// Labels represents task labels matched by task selectors.
+	labels?: {[string]: string} @go(Labels,map[string]string)
+}
+
 // Dependency represents one explicit ordering edge declared in
 // [Task.DependsOn].  It is deliberately empty — the edge is the struct key —
 // so future fields (for example an optional edge) may be added without
 // breaking composition.
 #Dependency: {}
 
+// Retry represents the retry policy of a [Task].  Useful for commands and chart
+// pulls subject to transient network errors.  The zero value executes the task
+// once.
+#Retry: {
+	// Attempts represents the maximum number of attempts including the first.
+	// Defaults to 1, no retry.
+	attempts?: int & >=1 @go(Attempts)
+
+	// Backoff represents the duration to wait before the second attempt in Go
+	// time.ParseDuration syntax.  The wait doubles before each subsequent
+	// attempt.  Defaults to "1s".
+	backoff?: string @go(Backoff)
+}
+
+// Order represents the order of the Kubernetes resources written by a [Task].
+// Stable ordering keeps git diffs of the rendered manifests quiet.
+//
+//  1. Kind - Namespaces first, then CustomResourceDefinitions, then
+//     cluster-scoped resources, then all other resources.  Each group is
+//     sorted by kind, namespace, and name.  Cluster-scoped resources are
+//     recognized heuristically by a table of built-in Kubernetes kinds and by
+//     the Cluster kind prefix.  Valid for [Resources] and [Join] tasks, a Join
+//     task separating the sorted documents with its Separator.
+//  2. Label - Sorted by the kind and [InternalLabel] keys of [Resources].
+//     Valid for Resources tasks.
+//  3. Input - The [Join] inputs concatenated in declaration order.  Valid for
+//     Join tasks.
+#Order: string
+
 // Command represents a [Task] implemented by executing a user-defined system
 // command.  Command is a first-class Task kind in v1beta1.  Commands execute
-// with the working directory set to the platform root.
+// with the working directory set to the platform root unless WorkDir is set.
 //
 // A command with an output generates or transforms; a command with only inputs
 // validates, gating downstream tasks through [Task.DependsOn] edges.
+//
+// Holos materializes each declared input in the build temp directory and
+// exports its absolute path to the command as HOLOS_INPUT_<n>, where n is the
+// zero based index of the input, for example HOLOS_INPUT_0.  If the task
+// declares an output, HOLOS_OUTPUT holds the absolute path the command writes
+// the output to, unless IsStdoutOutput is true.  Holos creates the parent
+// directory of HOLOS_OUTPUT.  Use these variables instead of paths built from
+// [BuildContext].
 #Command: {
 	// DisplayName of the command.  The basename of args[0] is used if empty.
 	displayName?: string @go(DisplayName)
@@ -200,6 +292,21 @@ package core
 
 	// IsStdoutOutput captures the command stdout as the task Output if true.
 	isStdoutOutput?: bool @go(IsStdoutOutput)
+
+	// Env represents environment variables of the command, keyed by name.  Env
+	// takes precedence over the inherited environment.  The HOLOS_INPUT_<n> and
+	// HOLOS_OUTPUT variables take precedence over Env.
+	env?: {[string]: string} @go(Env,map[string]string)
+
+	// ClearEnv executes the command without the environment inherited from
+	// holos if true, so the command receives only Env and the HOLOS_INPUT_<n>
+	// and HOLOS_OUTPUT variables.  Useful for reproducible output.  Holos still
+	// resolves args[0] using its own PATH.
+	clearEnv?: bool @go(ClearEnv)
+
+	// WorkDir represents the working directory of the command relative to the
+	// platform root.  Defaults to the platform root.
+	workDir?: #FilePath @go(WorkDir)
 }
 
 // Artifact represents the sink [Task] kind.  It writes its single input from
@@ -231,7 +338,8 @@ package core
 	source: #FilePath @go(Source)
 }
 
-// Helm represents a [Task] that renders a helm [Chart].
+// Helm represents a [Task] that renders a helm [Chart] in-process with the helm
+// sdk, equivalent to helm template.  No helm executable is required.
 #Helm: {
 	// Chart represents a helm chart to manage.
 	chart: #Chart @go(Chart)
@@ -301,6 +409,9 @@ package core
 }
 
 // Auth represents environment variable names containing auth credentials.
+// Holos redacts the resolved username and password from logs and error
+// messages, except values shorter than 4 characters which would mask common
+// text.
 #Auth: {
 	username: #AuthSource @go(Username)
 	password: #AuthSource @go(Password)
@@ -322,14 +433,293 @@ package core
 	separator?: string @go(Separator)
 }
 
+// Filter represents a [Task] keeping or dropping the Kubernetes resources of
+// its inputs in-process.  Useful to drop the test Pods of a [Helm] chart
+// without a [Kustomize] or [Command] task.
+//
+// Filter concatenates the YAML documents of its inputs in declaration order,
+// then keeps each resource matching any Include selector, or every resource if
+// Include is empty, unless the resource matches any Exclude selector.  Kept
+// documents are copied verbatim, preserving comments and formatting.
+#Filter: {
+	// Include selects the resources to keep.  Keeps every resource if empty.
+	include?: [...#ResourceSelector] @go(Include,[]ResourceSelector)
+
+	// Exclude selects the resources to drop.  Takes precedence over Include.
+	exclude?: [...#ResourceSelector] @go(Exclude,[]ResourceSelector)
+}
+
+// ResourceSelector selects Kubernetes resources for a [Filter] task.  A
+// resource matches when every non-empty field matches.
+#ResourceSelector: {
+	// APIVersion matches the resource apiVersion exactly, for example "apps/v1".
+	apiVersion?: string @go(APIVersion)
+
+	// Kind matches the resource kind exactly, for example "Pod".
+	kind?: string @go(Kind)
+
+	// Name matches the resource metadata.name with [path.Match] glob syntax,
+	// for example "*-test-*".
+	//
+	// [path.Match]: https://pkg.go.dev/path#Match
+	name?: string @go(Name)
+
+	// Namespace matches the resource metadata.namespace with [path.Match] glob
+	// syntax.  Cluster-scoped resources have an empty namespace.
+	//
+	// [path.Match]: https://pkg.go.dev/path#Match
+	namespace?: string @go(Namespace)
+
+	// LabelSelector matches the resource metadata.labels using the syntax of
+	// the holos --selector flag, for example "app=web,tier!=test".
+	labelSelector?: string @go(LabelSelector)
+}
+
+// Patch represents a [Task] patching the Kubernetes resources of its inputs
+// in-process.  Useful to patch a [Helm] chart without a [Kustomize] task.
+//
+// Patch concatenates the YAML documents of its inputs in declaration order,
+// then applies each [ResourcePatch] in order to every resource it targets.
+// Unpatched documents are copied verbatim.  It is an error for a patch to
+// target no resources.
+#Patch: {
+	// Patches represents the patches to apply in order.
+	patches: [...#ResourcePatch] @go(Patches,[]ResourcePatch)
+}
+
+// ResourcePatch represents one patch of a [Patch] task.  Type discriminates
+// the patch format:
+//
+//  1. JSON6902 - Apply the RFC 6902 JSON patch Operations.
+//  2. Merge - Apply the RFC 7386 JSON merge patch Merge.  A null value
+//     removes the field.
+//  3. StrategicMerge - Apply the Kubernetes strategic merge patch Merge.
+//     Lists of built-in kinds merge by their patch merge key, for example the
+//     name of a container.  Kinds holos does not know, for example custom
+//     resources, fall back to a JSON merge patch.
+#ResourcePatch: {
+	// Type represents the patch format.
+	type: string & ("JSON6902" | "Merge" | "StrategicMerge") @go(Type)
+
+	// Target selects the resources to patch.  Targets every resource if empty.
+	target?: #ResourceSelector @go(Target)
+
+	// Operations represents the JSON patch operations.  Ignored unless type is
+	// JSON6902.
+	operations?: [...#PatchOperation] @go(Operations,[]PatchOperation)
+
+	// Merge represents the partial resource merged into each target.  Ignored
+	// unless type is Merge or StrategicMerge.
+	merge?: {...} @go(Merge,map[string]any)
+}
+
+// PatchOperation represents one RFC 6902 JSON patch operation of a
+// [ResourcePatch].
+#PatchOperation: {
+	// Op represents the operation.
+	op: string & ("add" | "remove" | "replace" | "move" | "copy" | "test") @go(Op)
+
+	// Path represents the JSON pointer to the target location, for example
+	// "/spec/replicas".
+	path: string @go(Path)
+
+	// From represents the JSON pointer to the source location of a move or
+	// copy operation.
+	from?: string @go(From)
+
+	// Value represents the value of an add, replace, or test operation.
+	value?: _ @go(Value,any)
+}
+
+// Transform represents a [Task] transforming the metadata of every Kubernetes
+// resource of its inputs in-process.  Useful to normalize [Helm] output
+// without a [Kustomize] task.
+//
+// Transform concatenates the YAML documents of its inputs in declaration
+// order, then applies the operations to each resource in field order:
+// RemoveFields first, so Labels may replace a removed label.  Untransformed
+// documents are copied verbatim.  Transform does not update references
+// between resources, for example a prefixed ConfigMap name referenced by a
+// Deployment.
+#Transform: {
+	// RemoveFields represents RFC 6901 JSON pointers to fields to remove.  A
+	// "/" within a key is escaped as "~1", for example
+	// "/metadata/labels/helm.sh~1chart".  Missing fields are ignored.
+	removeFields?: [...string] @go(RemoveFields,[]string)
+
+	// Namespace sets the namespace of namespaced resources lacking one.
+	// Namespaces, CustomResourceDefinitions, and built-in cluster-scoped kinds
+	// are left unchanged, as are kinds prefixed with Cluster and kinds listed
+	// in ClusterScopedKinds.
+	namespace?: string @go(Namespace)
+
+	// ClusterScopedKinds represents additional cluster-scoped kinds Namespace
+	// leaves unchanged, for example cluster-scoped custom resources.  holos
+	// renders without access to a cluster, so the scope of a custom resource
+	// is not discovered.
+	clusterScopedKinds?: [...string] @go(ClusterScopedKinds,[]string)
+
+	// Labels represents labels to add to every resource, replacing existing
+	// values.
+	labels?: {[string]: string} @go(Labels,map[string]string)
+
+	// Annotations represents annotations to add to every resource, replacing
+	// existing values.
+	annotations?: {[string]: string} @go(Annotations,map[string]string)
+
+	// NamePrefix represents a prefix to add to the name of every resource
+	// except Namespaces and CustomResourceDefinitions.
+	namePrefix?: string @go(NamePrefix)
+
+	// NameSuffix represents a suffix to add to the name of every resource
+	// except Namespaces and CustomResourceDefinitions.
+	nameSuffix?: string @go(NameSuffix)
+}
+
+// Split represents a [Task] splitting the Kubernetes resources of its inputs
+// in-process into one file per resource under the output directory.  Useful to
+// commit each resource to its own file, equivalent to holos kubectl-slice.
+//
+// Split reads the YAML documents of its inputs in declaration order and copies
+// each selected document verbatim to the file named by Template.  Documents
+// sharing a file name are joined into one file in input order.
+#Split: {
+	// Template represents the file name of each resource relative to the
+	// output directory.  The placeholders {kind}, {name}, {namespace},
+	// {group}, and {version} are replaced with the lower case value of the
+	// resource field, for example "{namespace}/{kind}-{name}.yaml".  Empty path
+	// segments are dropped, so cluster-scoped resources of the example are
+	// written to the output directory itself.  Defaults to "{kind}-{name}.yaml".
+	template?: string @go(Template)
+
+	// Include selects the resources to split.  Splits every resource if empty.
+	include?: [...#ResourceSelector] @go(Include,[]ResourceSelector)
+
+	// Exclude selects the resources to drop.  Takes precedence over Include.
+	exclude?: [...#ResourceSelector] @go(Exclude,[]ResourceSelector)
+}
+
+// Jsonnet represents a [Task] evaluating a Jsonnet file from the component
+// directory in-process with go-jsonnet.  Useful to render upstream projects
+// shipping only Jsonnet, for example kube-prometheus, without a jsonnet
+// executable.
+//
+// The evaluated value is flattened into a YAML stream of Kubernetes resources.
+// An object with both apiVersion and kind is one resource.  Arrays are
+// flattened in order and other objects are flattened by sorted key, so both a
+// list of resources and the kube-prometheus object of resources are supported.
+// Null values are dropped.
+#Jsonnet: {
+	// Source represents the Jsonnet file sub-path relative to the component
+	// path.
+	source: #FilePath @go(Source)
+
+	// ExtVars represents external variables available to std.extVar, keyed by
+	// name.  Each value is passed as Jsonnet code encoded as JSON, equivalent to
+	// the jsonnet --ext-code flag, so strings and structured values defined in
+	// CUE both work.
+	extVars?: {...} @go(ExtVars,map[string]any)
+
+	// TLAs represents top-level arguments of a top-level function, keyed by
+	// parameter name.  Each value is passed as Jsonnet code encoded as JSON,
+	// equivalent to the jsonnet --tla-code flag.
+	tlas?: {...} @go(TLAs,map[string]any)
+
+	// JPath represents library search paths relative to the component path,
+	// equivalent to the jsonnet --jpath flag, for example "vendor".
+	jpath?: [...#FilePath] @go(JPath,[]FilePath)
+}
+
+// CUE represents a [Task] unifying the Kubernetes resources of its inputs with
+// a CUE package of the platform module in-process.  Useful to validate
+// rendered manifests against a policy, or to transform them, without a
+// [Command] task executing holos cue vet once per artifact.
+//
+// Holos loads the input resources into the resources field of the package
+// keyed by input path, GVK, and namespaced name, for example
+// resources: "chart.yaml": "apps/v1/Deployment": "web/web": {...}.  The GVK is
+// the apiVersion, a "/", and the kind.  The namespaced name is the namespace,
+// a "/", and the name, or the bare name when the namespace is empty.  Two
+// resources with the same key are an error.  Holos then validates the package
+// value, failing the task with the CUE error positions of any conflict.
+//
+// A CUE task without an output is a validator, gating downstream tasks through
+// [Task.DependsOn] edges.  A CUE task with an output exports Expression.
+#CUE: {
+	// Package represents the CUE package directory relative to the platform
+	// root, for example "policy/secrets".
+	package: #FilePath @go(Package)
+
+	// Expression represents a CUE expression evaluated in the scope of the
+	// package value, equivalent to the cue export --expression flag.  A list
+	// exports one YAML document per element, any other value one document.
+	// Required if the task declares an output, otherwise must be empty.
+	expression?: string @go(Expression)
+}
+
 // Kustomize represents a kustomization [Task] to patch and transform prior
-// task outputs.
+// task outputs.  Holos builds the kustomization in-process with the kustomize
+// API against an in-memory filesystem holding the kustomization, Files, and
+// the task inputs, so kubectl is not required.
+//
+// The in-memory filesystem has no access to the local disk.  Helm charts and
+// alpha plugins run outside of holos and need files on disk, so holos builds
+// on disk in a temporary directory if EnableHelm or EnableAlphaPlugins is set.
+// Holos also builds on disk if LoadRestrictor is LoadRestrictionsNone, so the
+// kustomization may load files from the local disk outside of its root.  The
+// root is then a temporary directory within the component directory, so a
+// relative reference such as ../base resolves against the component
+// directory.
 #Kustomize: {
 	// Kustomization represents the decoded kustomization.yaml file
 	kustomization: #Kustomization @go(Kustomization)
 
 	// Files holds file contents for kustomize, e.g. patch files.
 	files?: #FileContentMap @go(Files)
+
+	// LoadRestrictor represents the kustomize build --load-restrictor flag.
+	// Defaults to LoadRestrictionsRootOnly.  LoadRestrictionsNone builds on
+	// disk within the component directory.
+	loadRestrictor?: string & ("LoadRestrictionsRootOnly" | "LoadRestrictionsNone") @go(LoadRestrictor)
+
+	// EnableHelm represents the kustomize build --enable-helm flag, enabling
+	// the helmCharts field of the kustomization.
+	enableHelm?: bool @go(EnableHelm)
+
+	// HelmCommand represents the kustomize build --helm-command flag.
+	// Defaults to helm.
+	helmCommand?: string @go(HelmCommand)
+
+	// EnableAlphaPlugins represents the kustomize build --enable-alpha-plugins
+	// flag.
+	enableAlphaPlugins?: bool @go(EnableAlphaPlugins)
+
+	// EnableExec represents the kustomize build --enable-exec flag, enabling
+	// exec KRM functions.  Requires EnableAlphaPlugins.
+	enableExec?: bool @go(EnableExec)
+
+	// Network represents the kustomize build --network flag, enabling network
+	// access for container KRM functions.  Requires EnableAlphaPlugins.
+	network?: bool @go(Network)
+
+	// NetworkName represents the kustomize build --network-name flag.
+	// Defaults to bridge.  Requires Network.
+	networkName?: string @go(NetworkName)
+
+	// Mounts represents the kustomize build --mount flags mounting storage
+	// into container KRM functions, for example
+	// "type=bind,src=/tmp/data,dst=/data".  Requires EnableAlphaPlugins.
+	mounts?: [...string] @go(Mounts,[]string)
+
+	// Env represents the kustomize build --env flags passing environment
+	// variables to KRM functions, for example "KEY=value" or "KEY" to pass the
+	// value of the holos process.  Requires EnableAlphaPlugins.
+	env?: [...string] @go(Env,[]string)
+
+	// AsCurrentUser represents the kustomize build --as-current-user flag
+	// running container KRM functions as the current user.  Requires
+	// EnableAlphaPlugins.
+	asCurrentUser?: bool @go(AsCurrentUser)
 }
 
 // Kustomization represents a kustomization.yaml file for use with the
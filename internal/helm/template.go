@@ -0,0 +1,141 @@
+package helm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/holos-run/holos/internal/errors"
+	"github.com/holos-run/holos/internal/logger"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// TemplateOptions represents the helm template flags holos supports.
+type TemplateOptions struct {
+	// ReleaseName represents the release name.
+	ReleaseName string
+	// Namespace represents the --namespace flag.  Defaults to "default".
+	Namespace string
+	// Values represents values merged in order, each taking precedence over
+	// the previous, equivalent to repeated --values flags.
+	Values []map[string]any
+	// EnableHooks renders hooks, the inverse of the --no-hooks flag.
+	EnableHooks bool
+	// APIVersions represents the --api-versions flag.
+	APIVersions []string
+	// KubeVersion represents the --kube-version flag.
+	KubeVersion string
+	// IncludeCRDs represents the --include-crds flag.
+	IncludeCRDs bool
+}
+
+// Template renders the chart at chartPath with the client-only install action
+// of the Helm SDK, equivalent to the helm template command.  No helm
+// executable or Kubernetes cluster is required.  Returns the rendered
+// manifests.
+func Template(ctx context.Context, chartPath string, opts TemplateOptions) ([]byte, error) {
+	log := logger.FromContext(ctx)
+
+	chart, err := loader.Load(chartPath)
+	if err != nil {
+		return nil, errors.Format("could not load chart: %w", err)
+	}
+	if req := chart.Metadata.Dependencies; req != nil {
+		if err := action.CheckDependencies(chart, req); err != nil {
+			return nil, errors.Format("could not check chart dependencies: %w", err)
+		}
+	}
+
+	vals, err := mergeValues(opts.Values)
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+
+	cfg := &action.Configuration{
+		Log: func(format string, a ...any) {
+			log.DebugContext(ctx, fmt.Sprintf(format, a...))
+		},
+	}
+	client := action.NewInstall(cfg)
+	client.DryRun = true
+	client.DryRunOption = "client"
+	client.ClientOnly = true
+	client.Replace = true
+	client.ReleaseName = opts.ReleaseName
+	client.Namespace = opts.Namespace
+	if client.Namespace == "" {
+		client.Namespace = "default"
+	}
+	client.DisableHooks = !opts.EnableHooks
+	client.IncludeCRDs = opts.IncludeCRDs
+	client.APIVersions = chartutil.VersionSet(opts.APIVersions)
+	if opts.KubeVersion != "" {
+		if client.KubeVersion, err = chartutil.ParseKubeVersion(opts.KubeVersion); err != nil {
+			return nil, errors.Format("invalid kube version %q: %w", opts.KubeVersion, err)
+		}
+	}
+
+	rel, err := client.RunWithContext(ctx, chart, vals)
+	if err != nil {
+		return nil, errors.Format("could not render chart: %w", err)
+	}
+
+	return manifests(rel, client.DisableHooks), nil
+}
+
+// manifests returns the release manifests formatted like the output of the
+// helm template command.
+func manifests(rel *release.Release, disableHooks bool) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, strings.TrimSpace(rel.Manifest))
+	if !disableHooks {
+		for _, hook := range rel.Hooks {
+			fmt.Fprintf(&buf, "---\n# Source: %s\n%s\n", hook.Path, hook.Manifest)
+		}
+	}
+	return buf.Bytes()
+}
+
+// mergeValues merges values in order like repeated helm --values flags.  Each
+// map is round tripped through json so values have the types helm decodes from
+// values files, for example float64 numbers.
+func mergeValues(values []map[string]any) (map[string]any, error) {
+	base := map[string]any{}
+	for idx, v := range values {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, errors.Format("could not encode values %d: %w", idx, err)
+		}
+		var current map[string]any
+		if err := json.Unmarshal(data, &current); err != nil {
+			return nil, errors.Format("could not decode values %d: %w", idx, err)
+		}
+		base = mergeMaps(base, current)
+	}
+	return base, nil
+}
+
+// mergeMaps returns b deep merged into a.  Attribution: Helm values.Options.
+func mergeMaps(a, b map[string]any) map[string]any {
+	out := make(map[string]any, len(a))
+	for k, v := range a {
+		out[k] = v
+	}
+	for k, v := range b {
+		if v, ok := v.(map[string]any); ok {
+			if bv, ok := out[k]; ok {
+				if bv, ok := bv.(map[string]any); ok {
+					out[k] = mergeMaps(bv, v)
+					continue
+				}
+			}
+		}
+		out[k] = v
+	}
+	return out
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"path/filepath"
 
 	"github.com/holos-run/holos/internal/logger"
 )
@@ -221,3 +222,18 @@ func getenv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// DefaultCacheDir returns the default value of the --cache-dir flag.  The
+// value of HOLOS_CACHE_DIR takes precedence, including the empty string which
+// disables the task result cache.  Otherwise the holos sub directory of the
+// user cache directory, or the empty string if there is none.
+func DefaultCacheDir() string {
+	if value, exists := os.LookupEnv(CacheDirEnvVar); exists {
+		return value
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "holos")
+}
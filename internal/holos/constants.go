@@ -9,6 +9,10 @@ const WriteToDefault string = "deploy"
 // value of the --write-to flag.
 const WriteToEnvVar string = "HOLOS_WRITE_TO"
 
+// CacheDirEnvVar represents the environment variable used to look up the
+// default value of the --cache-dir flag.
+const CacheDirEnvVar string = "HOLOS_CACHE_DIR"
+
 // TypeMetaFile represents the file holos uses to discriminate the api version
 // of a component BuildPlan.
 const TypeMetaFile string = "typemeta.yaml"
@@ -51,6 +51,10 @@ type TagMap map[string]*string
 
 const TagMapHelp = "set the value of a cue @tag field in the form key=value or simply key"
 
+// TaskSelectorHelp describes the --task-selector flag.  Repeat the flag to
+// execute the tasks selected by any one selector.
+const TaskSelectorHelp = "task label selector, may be repeated (e.g. label==string,label!=string)"
+
 func (t TagMap) Tags() []string {
 	parts := make([]string, 0, len(t))
 	for tag, val := range t {
@@ -331,6 +335,18 @@ type BuildOpts struct {
 	// Tags represents user managed tags including a component name, labels, and
 	// annotations.
 	Tags []string
+	// CacheDir represents the directory holding cached task results keyed by
+	// content.  An empty value disables the task result cache.
+	CacheDir string
+	// KeepGoing executes every v1beta1 task not depending on a failed task
+	// instead of failing fast, then writes a report to Stderr.
+	KeepGoing bool
+	// TaskSelectors select the v1beta1 tasks to execute by label.  Every task
+	// executes if empty.
+	TaskSelectors Selectors
+	// Task represents the name of the one v1beta1 task to execute along with
+	// its transitive predecessors.  Every task executes if empty.
+	Task string
 
 	root    string
 	leaf    string
@@ -372,8 +388,12 @@ func (b *BuildOpts) AbsLeaf() string {
 }
 
 // AbsWriteTo returns the absolute path to the write to directory, usually the
-// deploy sub directory of the platform module root.
+// deploy sub directory of the platform module root.  An absolute write to
+// directory, for example a private stage, is returned cleaned.
 func (b *BuildOpts) AbsWriteTo() string {
+	if filepath.IsAbs(b.writeTo) {
+		return filepath.Clean(b.writeTo)
+	}
 	return filepath.Join(b.root, b.writeTo)
 }
 
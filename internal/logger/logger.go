@@ -15,12 +15,19 @@ import (
 
 	"github.com/holos-run/holos/internal/errors"
 	"github.com/holos-run/holos/internal/logger/tint"
+	"github.com/holos-run/holos/internal/redact"
 	"github.com/holos-run/holos/version"
 	"github.com/mattn/go-isatty"
 )
 
 const ErrKey = "err"
 
+// Environment variables configuring redaction, also exported to sub processes.
+const (
+	redactEnvEnv     = "HOLOS_REDACT_ENV"
+	redactPatternEnv = "HOLOS_REDACT_PATTERN"
+)
+
 var validLogLevels = []string{"debug", "info", "warn", "error"}
 var validLogFormats = []string{"text", "json", "console"}
 
@@ -31,8 +38,21 @@ func (s stringSlice) String() string {
 	return strings.Join((s)[:], ",")
 }
 
-func (s stringSlice) Set(value string) error {
-	_ = append(s, strings.Split(value, ",")...)
+func (s *stringSlice) Set(value string) error {
+	*s = append(*s, strings.Split(value, ",")...)
+	return nil
+}
+
+// patternList is a list of regular expressions, one per flag value, so
+// patterns may contain commas.
+type patternList []string
+
+func (p patternList) String() string {
+	return strings.Join(p, " ")
+}
+
+func (p *patternList) Set(value string) error {
+	*p = append(*p, value)
 	return nil
 }
 
@@ -72,7 +92,11 @@ type Config struct {
 	level     string
 	format    string
 	dropAttrs stringSlice
-	flagSet   *flag.FlagSet
+	// redactEnv holds the names of environment variables with sensitive values.
+	redactEnv stringSlice
+	// redactPatterns holds regular expressions matching sensitive values.
+	redactPatterns patternList
+	flagSet        *flag.FlagSet
 }
 
 func (c *Config) Level() string {
@@ -146,7 +170,7 @@ func (c *Config) handler(w io.Writer) (h slog.Handler) {
 		})
 	}
 
-	return
+	return &redactHandler{next: h}
 }
 
 // NewLogger returns a *slog.Logs configured by c *Config which writes to w
@@ -161,6 +185,17 @@ func NewConfig() *Config {
 	f.StringVar(&c.level, "log-level", getenv("HOLOS_LOG_LEVEL", "info"), fmt.Sprintf("log level (%s)", strings.Join(validLogLevels, "|")))
 	f.StringVar(&c.format, "log-format", getenv("HOLOS_LOG_FORMAT", "console"), fmt.Sprintf("log format (%s)", strings.Join(validLogFormats, "|")))
 	f.Var(&c.dropAttrs, "log-drop", "log attributes to drop (example \"user-agent,version\")")
+	if value := os.Getenv(redactEnvEnv); value != "" {
+		_ = c.redactEnv.Set(value)
+	}
+	f.Var(&c.redactEnv, "redact-env", "environment variables with sensitive values to redact from logs and errors (example \"GITHUB_TOKEN,NPM_TOKEN\")")
+	// Patterns are newline separated so a pattern may contain a comma.
+	for _, value := range strings.Split(os.Getenv(redactPatternEnv), "\n") {
+		if value != "" {
+			_ = c.redactPatterns.Set(value)
+		}
+	}
+	f.Var(&c.redactPatterns, "redact-pattern", "regular expression matching sensitive values to redact from logs and errors, masking only capture groups if present (repeatable)")
 	return c
 }
 
@@ -177,6 +212,35 @@ func (c *Config) Vet() error {
 	if err := c.vetFormat(); err != nil {
 		return err
 	}
+	if err := c.vetRedact(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// vetRedact registers the sensitive values and patterns configured by the
+// user with package redact and exports them to the environment so holos sub
+// processes, for example holos render component and holos compile, redact the
+// same values.
+func (c *Config) vetRedact() error {
+	for _, name := range c.redactEnv {
+		redact.Value(os.Getenv(strings.TrimSpace(name)))
+	}
+	for _, expr := range c.redactPatterns {
+		if err := redact.Pattern(expr); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+	if len(c.redactEnv) > 0 {
+		if err := os.Setenv(redactEnvEnv, c.redactEnv.String()); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+	if len(c.redactPatterns) > 0 {
+		if err := os.Setenv(redactPatternEnv, strings.Join(c.redactPatterns, "\n")); err != nil {
+			return errors.Wrap(err)
+		}
+	}
 	return nil
 }
 
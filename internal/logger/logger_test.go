@@ -1,8 +1,13 @@
 package logger
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"strings"
 	"testing"
+
+	"github.com/holos-run/holos/internal/redact"
 )
 
 func TestLoggerFromContext(t *testing.T) {
@@ -11,3 +16,116 @@ func TestLoggerFromContext(t *testing.T) {
 		t.Fatalf("want slog.Default() got nil")
 	}
 }
+
+func TestLogDrop(t *testing.T) {
+	c := NewConfig()
+	// Set appends each comma separated value, across repeated flags.
+	args := []string{"--log-format=json", "--log-drop=version,pid", "--log-drop=source"}
+	if err := c.FlagSet().Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "version,pid,source", c.dropAttrs.String(); want != have {
+		t.Fatalf("want %s have %s", want, have)
+	}
+
+	var b bytes.Buffer
+	c.NewLogger(&b).Info("hello")
+	have := b.String()
+	for _, key := range []string{`"version"`, `"pid"`, `"source"`} {
+		if strings.Contains(have, key) {
+			t.Fatalf("want %s dropped have: %s", key, have)
+		}
+	}
+	if !strings.Contains(have, `"msg":"hello"`) {
+		t.Fatalf("want msg have: %s", have)
+	}
+}
+
+func TestLoggerRedacts(t *testing.T) {
+	t.Cleanup(redact.Reset)
+	t.Setenv(redactEnvEnv, "")
+	t.Setenv(redactPatternEnv, "")
+	t.Setenv("TEST_REDACT_TOKEN", "tok-123")
+
+	for _, format := range validLogFormats {
+		t.Run(format, func(t *testing.T) {
+			c := NewConfig()
+			args := []string{
+				"--log-level=debug",
+				"--log-format=" + format,
+				"--redact-env=TEST_REDACT_TOKEN",
+				"--redact-pattern=password=(\\S+)",
+			}
+			if err := c.FlagSet().Parse(args); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.Vet(); err != nil {
+				t.Fatal(err)
+			}
+
+			var b bytes.Buffer
+			log := c.NewLogger(&b).With("token", "tok-123")
+			log.Debug("running command: curl -H tok-123 password=hunter2",
+				"args", []string{"-H", "tok-123"},
+				"err", errors.New("exit status 1: tok-123"),
+			)
+
+			have := b.String()
+			for _, secret := range []string{"tok-123", "hunter2"} {
+				if strings.Contains(have, secret) {
+					t.Fatalf("want %s redacted have: %s", secret, have)
+				}
+			}
+			if !strings.Contains(have, redact.Mask) {
+				t.Fatalf("want %s have: %s", redact.Mask, have)
+			}
+		})
+	}
+}
+
+func TestVetInvalidPattern(t *testing.T) {
+	t.Cleanup(redact.Reset)
+	c := NewConfig()
+	if err := c.FlagSet().Parse([]string{"--redact-pattern=("}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Vet(); err == nil {
+		t.Fatalf("want error have nil")
+	}
+}
+
+func TestVetExportsRedact(t *testing.T) {
+	t.Cleanup(redact.Reset)
+	// Restore the environment Vet exports to sub processes.
+	t.Setenv(redactEnvEnv, "")
+	t.Setenv(redactPatternEnv, "")
+	t.Setenv("TEST_REDACT_TOKEN", "tok-123")
+
+	c := NewConfig()
+	args := []string{
+		"--redact-env=TEST_REDACT_TOKEN",
+		"--redact-pattern=password=(\\S+)",
+		"--redact-pattern=token=(\\w+),",
+	}
+	if err := c.FlagSet().Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Vet(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A sub process configures the same values from the environment.
+	redact.Reset()
+	child := NewConfig()
+	if err := child.FlagSet().Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := child.Vet(); err != nil {
+		t.Fatal(err)
+	}
+	have := redact.String("tok-123 password=hunter2 token=abc,")
+	want := "[REDACTED] password=[REDACTED] token=[REDACTED],"
+	if have != want {
+		t.Fatalf("want %q have %q", want, have)
+	}
+}
@@ -0,0 +1,88 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/holos-run/holos/internal/redact"
+)
+
+// redactHandler masks sensitive values registered with package redact in the
+// message and attributes of each record before passing it to the next handler.
+type redactHandler struct {
+	next slog.Handler
+}
+
+func (h *redactHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	rec := slog.NewRecord(r.Time, r.Level, redact.String(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		rec.AddAttrs(redactAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, rec)
+}
+
+func (h *redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		redacted = append(redacted, redactAttr(a))
+	}
+	return &redactHandler{next: h.next.WithAttrs(redacted)}
+}
+
+func (h *redactHandler) WithGroup(name string) slog.Handler {
+	return &redactHandler{next: h.next.WithGroup(name)}
+}
+
+// redactAttr masks sensitive values in a.  Values other than strings, string
+// slices, errors, and groups are replaced by their masked string form only if
+// the string form contains a sensitive value.
+func redactAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, redact.String(v.String()))
+	case slog.KindGroup:
+		group := v.Group()
+		attrs := make([]slog.Attr, 0, len(group))
+		for _, ga := range group {
+			attrs = append(attrs, redactAttr(ga))
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(attrs...)}
+	case slog.KindAny:
+		switch x := v.Any().(type) {
+		case error:
+			// Keep the error type so ReplaceAttr formats it as an error.
+			if msg := redact.String(x.Error()); msg != x.Error() {
+				return slog.Any(a.Key, &redactedError{msg: msg, err: x})
+			}
+			return slog.Any(a.Key, x)
+		case []string:
+			return slog.Any(a.Key, redact.Strings(x))
+		default:
+			if s := fmt.Sprint(x); redact.String(s) != s {
+				return slog.String(a.Key, redact.String(s))
+			}
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
+
+// redactedError is an error with sensitive values masked from the message.
+type redactedError struct {
+	msg string
+	err error
+}
+
+func (e *redactedError) Error() string {
+	return e.msg
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
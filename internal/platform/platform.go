@@ -16,6 +16,7 @@ import (
 	"github.com/holos-run/holos/internal/logger"
 	"github.com/holos-run/holos/internal/platform/v1alpha5"
 	"github.com/holos-run/holos/internal/platform/v1alpha6"
+	"github.com/holos-run/holos/internal/trace"
 	"github.com/holos-run/holos/internal/util"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -124,6 +125,11 @@ func (p *Platform) Root() string {
 	return p.root
 }
 
+// Leaf returns the platform directory relative to the root.
+func (p *Platform) Leaf() string {
+	return p.leaf
+}
+
 // Load discriminates the api version then loads the platform configuration by
 // building a cue instance.
 func (p *Platform) Load(ctx context.Context) error {
@@ -144,7 +150,9 @@ func (p *Platform) Load(ctx context.Context) error {
 		p.Platform = &v1alpha5.Platform{}
 	}
 
+	_, span := trace.Start(ctx, "cue", "BuildInstance", "path", p.leaf)
 	inst, err := cue.BuildInstance(p.root, p.leaf, tags)
+	span.End()
 	if err != nil {
 		return errors.Format("could not build cue instance: %w", err)
 	}
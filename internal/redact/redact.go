@@ -0,0 +1,122 @@
+// Package redact masks credentials and other sensitive values in log records
+// and error messages.
+//
+// Values are registered in a process wide registry as they are discovered, for
+// example when a Helm task resolves repository credentials, so debug logs and
+// error messages remain safe to share.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Mask replaces sensitive values.
+const Mask = "[REDACTED]"
+
+// MinLength represents the length of the shortest value [Value] registers.  A
+// shorter value, for example the username "ci", would mask every occurrence of
+// common text and make logs unreadable.
+const MinLength = 4
+
+var (
+	mu       sync.RWMutex
+	values   []string
+	patterns []*regexp.Regexp
+	replacer *strings.Replacer
+)
+
+// Value registers sensitive values to mask.  Values shorter than [MinLength],
+// including empty values, are ignored.
+func Value(vals ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+	for _, v := range vals {
+		if len(v) < MinLength || slices.Contains(values, v) {
+			continue
+		}
+		values = append(values, v)
+	}
+	// Replace longer values first so a value containing another value is
+	// masked completely.
+	slices.SortStableFunc(values, func(a, b string) int { return len(b) - len(a) })
+	oldnew := make([]string, 0, 2*len(values))
+	for _, v := range values {
+		oldnew = append(oldnew, v, Mask)
+	}
+	replacer = strings.NewReplacer(oldnew...)
+}
+
+// Pattern registers a regular expression matching sensitive values.  If the
+// expression has capture groups only the text of the groups is masked,
+// otherwise the whole match is masked.
+func Pattern(expr string) error {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return fmt.Errorf("invalid redact pattern: %w", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if slices.ContainsFunc(patterns, func(p *regexp.Regexp) bool { return p.String() == expr }) {
+		return nil
+	}
+	patterns = append(patterns, re)
+	return nil
+}
+
+// String returns s with registered values and pattern matches masked.
+func String(s string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	if replacer != nil {
+		s = replacer.Replace(s)
+	}
+	for _, re := range patterns {
+		s = mask(re, s)
+	}
+	return s
+}
+
+// Strings returns a copy of list with each element masked by [String].
+func Strings(list []string) []string {
+	out := make([]string, len(list))
+	for idx, s := range list {
+		out[idx] = String(s)
+	}
+	return out
+}
+
+// Reset clears the registry.  Useful for tests.
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	values = nil
+	patterns = nil
+	replacer = nil
+}
+
+// mask replaces the matches of re in s.
+func mask(re *regexp.Regexp, s string) string {
+	if re.NumSubexp() == 0 {
+		return re.ReplaceAllLiteralString(s, Mask)
+	}
+	var b strings.Builder
+	last := 0
+	for _, loc := range re.FindAllStringSubmatchIndex(s, -1) {
+		for group := 1; group <= re.NumSubexp(); group++ {
+			start, end := loc[2*group], loc[2*group+1]
+			// Skip empty groups and groups overlapping a previous group.
+			if start < 0 || start == end || start < last {
+				continue
+			}
+			b.WriteString(s[last:start])
+			b.WriteString(Mask)
+			last = end
+		}
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
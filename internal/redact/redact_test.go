@@ -0,0 +1,40 @@
+package redact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestString(t *testing.T) {
+	t.Cleanup(Reset)
+
+	Value("", "ci", "s3cr3t", "s3cr3t-and-more")
+	require.NoError(t, Pattern(`ghp_[A-Za-z0-9]+`))
+	require.NoError(t, Pattern(`(?i)authorization: bearer (\S+)`))
+	require.NoError(t, Pattern(`--token=(\S*)`))
+
+	for _, tc := range []struct {
+		name string
+		have string
+		want string
+	}{
+		{name: "Value", have: "helm pull --password s3cr3t", want: "helm pull --password [REDACTED]"},
+		{name: "LongestValue", have: "key=s3cr3t-and-more", want: "key=[REDACTED]"},
+		{name: "Pattern", have: "git clone https://ghp_abc123@github.com", want: "git clone https://[REDACTED]@github.com"},
+		{name: "CaptureGroup", have: "Authorization: Bearer xyz.abc", want: "Authorization: Bearer [REDACTED]"},
+		{name: "EmptyGroup", have: "cmd --token= --token=abc", want: "cmd --token= --token=[REDACTED]"},
+		{name: "Clean", have: "nothing to see", want: "nothing to see"},
+		{name: "ShortValue", have: "ci pipeline for circle", want: "ci pipeline for circle"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, String(tc.have))
+		})
+	}
+}
+
+func TestPatternInvalid(t *testing.T) {
+	t.Cleanup(Reset)
+	assert.ErrorContains(t, Pattern(`(`), "invalid redact pattern")
+}
@@ -0,0 +1,346 @@
+// Package stage holds the files of a render in a temporary tree beside the
+// write-to directory until every task succeeds, then promotes the tree into
+// the write-to directory.  A failed or interrupted render leaves the write-to
+// directory as the last successful render left it.  Each render has a uniquely
+// named stage, so concurrent renders into one write-to directory never share a
+// stage.
+//
+// Promotion is change-aware: a file whose content is byte-identical to the
+// file in the write-to directory is left untouched, so its modification time
+// is preserved and rsync or git based tooling sees only real changes.  Each
+// changed file is written to a uniquely named file beside its destination then
+// renamed into place, so a reader never observes a partially written file.
+package stage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/holos-run/holos/internal/errors"
+	"github.com/holos-run/holos/internal/logger"
+	"github.com/holos-run/holos/internal/util"
+)
+
+// Prefix represents the name prefix of the stage directory beside the write-to
+// directory and of the temporary files promotion writes.  The stage directory
+// is on the same filesystem as the write-to directory, so promoting a file is a
+// rename, never a copy.
+const Prefix = ".holos-new"
+
+// Stage represents the temporary tree holding the files of one render.
+type Stage struct {
+	// root represents the platform root directory.
+	root string
+	// writeTo represents the write-to directory relative to root.
+	writeTo string
+	// path represents the absolute path of the stage directory.
+	path string
+	// private is true if the stage is never promoted.
+	private bool
+}
+
+// IsStage returns true if writeTo is a stage directory, or a file promotion
+// left behind.  A render into a stage, for example a legacy component rendered
+// by holos render platform, writes directly to avoid nesting one stage within
+// another.
+func IsStage(writeTo string) bool {
+	return strings.HasPrefix(filepath.Base(filepath.Clean(writeTo)), Prefix)
+}
+
+// New returns a new, empty stage for the writeTo directory relative to root.
+// The stage is a sibling of the write-to directory, never within it, so a
+// stage left behind by an interrupted render is never committed to or synced
+// from the write-to directory.
+func New(root, writeTo string) (*Stage, error) {
+	dest, err := filepath.Abs(filepath.Join(root, writeTo))
+	if err != nil {
+		return nil, errors.Wrap(err)
+	}
+	if err := os.MkdirAll(dest, 0o777); err != nil {
+		return nil, errors.Format("could not make stage: %w", err)
+	}
+	dir, err := os.MkdirTemp(filepath.Dir(dest), Prefix+"-"+filepath.Base(dest)+"-*")
+	if err != nil {
+		return nil, errors.Format("could not make stage: %w", err)
+	}
+	return &Stage{root: root, writeTo: writeTo, path: dir}, nil
+}
+
+// NewPrivate returns a new, empty stage in the temporary directory of the
+// system for a render never promoted, for example holos diff comparing a render
+// with the write-to directory.  The write-to directory is left untouched,
+// including when the process is killed.  The stage is addressed by absolute
+// path, so the temporary directory may be on another volume than root.
+func NewPrivate(root string) (*Stage, error) {
+	dir, err := os.MkdirTemp("", Prefix+"-*")
+	if err != nil {
+		return nil, errors.Format("could not make stage: %w", err)
+	}
+	return &Stage{root: root, path: dir, private: true}, nil
+}
+
+// WriteTo returns the stage directory relative to the platform root, used in
+// place of the write-to directory for the render.  The directory of a private
+// stage is absolute.
+func (s *Stage) WriteTo() string {
+	if s.private {
+		return s.path
+	}
+	root, err := filepath.Abs(s.root)
+	if err != nil {
+		return s.path
+	}
+	rel, err := filepath.Rel(root, s.path)
+	if err != nil {
+		return s.path
+	}
+	return rel
+}
+
+// Remove removes the stage directory.
+func (s *Stage) Remove(ctx context.Context) {
+	util.Remove(ctx, s.Dir())
+}
+
+// Files returns the sorted, slash separated paths of the staged files
+// relative to the stage directory.
+func (s *Stage) Files() ([]string, error) {
+	files, err := listFiles(s.Dir(), s.Dir())
+	if err != nil {
+		return nil, errors.Format("could not list stage: %w", err)
+	}
+	return files, nil
+}
+
+// Changes represents the files promoting a stage adds, changes, or removes as
+// sorted, slash separated paths relative to the write-to directory.
+type Changes struct {
+	Added   []string
+	Changed []string
+	Removed []string
+}
+
+// Empty returns true if there are no changes.
+func (c *Changes) Empty() bool {
+	return c.Len() == 0
+}
+
+// Len returns the number of changed files.
+func (c *Changes) Len() int {
+	return len(c.Added) + len(c.Changed) + len(c.Removed)
+}
+
+// Write writes one line per file to w sorted by path, the change then the
+// path joined to dir, for example "changed deploy/components/a/a.gen.yaml".
+func (c *Changes) Write(w io.Writer, dir string) error {
+	type line struct{ change, path string }
+	lines := make([]line, 0, c.Len())
+	for change, files := range map[string][]string{"added": c.Added, "changed": c.Changed, "removed": c.Removed} {
+		for _, file := range files {
+			lines = append(lines, line{change, filepath.ToSlash(filepath.Join(dir, filepath.FromSlash(file)))})
+		}
+	}
+	slices.SortFunc(lines, func(a, b line) int { return strings.Compare(a.path, b.path) })
+	for _, l := range lines {
+		if _, err := fmt.Fprintln(w, l.change, l.path); err != nil {
+			return errors.Wrap(err)
+		}
+	}
+	return nil
+}
+
+// Check writes the changes to w then returns an error if there are any.  Used
+// to detect drift between a render and the dir write-to directory.
+func (c *Changes) Check(w io.Writer, dir string) error {
+	if err := c.Write(w, dir); err != nil {
+		return err
+	}
+	if n := c.Len(); n > 0 {
+		return errors.Format("%d %s from %s", n, util.Plural(n, "file differs", "files differ"), dir)
+	}
+	return nil
+}
+
+// Diff returns the changes promoting the stage makes to the write-to
+// directory.  replace represents the artifact paths relative to the write-to
+// directory replaced as a whole, so a file under a replaced path and missing
+// from the stage is removed.  Other files are never removed.
+func (s *Stage) Diff(replace []string) (*Changes, error) {
+	if s.private {
+		return nil, errors.Format("could not diff %s: private stage has no write-to directory", s.WriteTo())
+	}
+	staged, err := s.Files()
+	if err != nil {
+		return nil, err
+	}
+
+	var c Changes
+	for _, file := range staged {
+		want, err := os.ReadFile(filepath.Join(s.Dir(), filepath.FromSlash(file)))
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		have, err := os.ReadFile(filepath.Join(s.dest(), filepath.FromSlash(file)))
+		switch {
+		case err == nil && bytes.Equal(have, want):
+		case err == nil:
+			c.Changed = append(c.Changed, file)
+		default:
+			c.Added = append(c.Added, file)
+		}
+	}
+
+	for _, path := range replace {
+		existing, err := listFiles(s.dest(), filepath.Join(s.dest(), filepath.FromSlash(path)))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, errors.Format("could not list %s: %w", path, err)
+		}
+		for _, file := range existing {
+			if _, found := slices.BinarySearch(staged, file); !found {
+				c.Removed = append(c.Removed, file)
+			}
+		}
+	}
+	slices.Sort(c.Removed)
+	c.Removed = slices.Compact(c.Removed)
+
+	return &c, nil
+}
+
+// Promote applies the changes of [Stage.Diff] to the write-to directory and
+// returns them.  Removed files go first so an artifact may change between a
+// file and a directory.
+func (s *Stage) Promote(ctx context.Context, replace []string) (*Changes, error) {
+	c, err := s.Diff(replace)
+	if err != nil {
+		return nil, err
+	}
+	log := logger.FromContext(ctx)
+
+	if err := RemoveFiles(s.dest(), c.Removed); err != nil {
+		return nil, err
+	}
+	for _, file := range c.Removed {
+		log.DebugContext(ctx, fmt.Sprintf("removed %s", file), "path", file)
+	}
+
+	for _, file := range slices.Concat(c.Added, c.Changed) {
+		staged := filepath.Join(s.Dir(), filepath.FromSlash(file))
+		data, err := os.ReadFile(staged)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		// Keep the permissions of the staged file, os.CreateTemp creates 0600.
+		info, err := os.Stat(staged)
+		if err != nil {
+			return nil, errors.Wrap(err)
+		}
+		if err := writeFile(filepath.Join(s.dest(), filepath.FromSlash(file)), data, info.Mode().Perm()); err != nil {
+			return nil, errors.Format("could not write %s: %w", file, err)
+		}
+		log.DebugContext(ctx, fmt.Sprintf("wrote %s", file), "path", file)
+	}
+	return c, nil
+}
+
+// RemoveFiles removes the slash separated files relative to dir along with the
+// parent directories left empty, stopping at dir.  Files already removed are
+// ignored.
+func RemoveFiles(dir string, files []string) error {
+	dir = filepath.Clean(dir)
+	for _, file := range files {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return errors.Format("could not remove %s: %w", file, err)
+		}
+		for parent := filepath.Dir(path); parent != dir && within(parent, dir); parent = filepath.Dir(parent) {
+			if err := os.Remove(parent); err != nil {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+// Dir returns the absolute path of the stage directory.
+func (s *Stage) Dir() string {
+	return s.path
+}
+
+// dest returns the write-to directory.
+func (s *Stage) dest() string {
+	return filepath.Join(s.root, s.writeTo)
+}
+
+// writeFile writes data to a uniquely named file beside path then renames it
+// into place with the permissions perm.
+func writeFile(path string, data []byte, perm fs.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o777); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), Prefix+"-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_, err = f.Write(data)
+	if err == nil {
+		err = f.Chmod(perm)
+	}
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// within returns true if path is under dir.
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && filepath.IsLocal(rel)
+}
+
+// listFiles returns the sorted, slash separated paths of the files under
+// root, or root itself if root is a file, relative to base.  Stage directories
+// and files promotion left behind are not listed.
+func listFiles(base, root string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != root && IsStage(d.Name()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(base, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	slices.Sort(files)
+	return files, err
+}
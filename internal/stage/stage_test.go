@@ -0,0 +1,204 @@
+package stage
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o777))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o666))
+	}
+}
+
+func TestIsStage(t *testing.T) {
+	assert.True(t, IsStage("deploy/.holos-new-123"))
+	assert.True(t, IsStage("deploy/.holos-new-123/"))
+	assert.False(t, IsStage("deploy"))
+	assert.False(t, IsStage(".holos-new-123/deploy"))
+}
+
+func TestNewUniqueStage(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"deploy/components/a/.holos-new-2": "{}\n",
+		"deploy/components/a/a.gen.yaml":   "{}\n",
+	})
+
+	a, err := New(root, "deploy")
+	require.NoError(t, err)
+	b, err := New(root, "deploy")
+	require.NoError(t, err)
+	assert.NotEqual(t, a.WriteTo(), b.WriteTo(), "concurrent renders must not share a stage")
+	assert.True(t, IsStage(a.WriteTo()))
+
+	files, err := a.Files()
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	// Files promotion left behind are never removed.
+	diff, err := a.Diff([]string{"."})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"components/a/a.gen.yaml"}, diff.Removed)
+}
+
+func TestInterruptedRender(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"deploy/components/a/a.gen.yaml": "old\n"})
+
+	// A render killed before promotion never removes its stage.
+	s, err := New(root, "deploy")
+	require.NoError(t, err)
+	assert.Equal(t, ".", filepath.Dir(s.WriteTo()), "the stage is a sibling of the write-to directory")
+	writeFiles(t, filepath.Join(root, s.WriteTo()), map[string]string{
+		"components/a/a.gen.yaml": "new\n",
+		"components/b/b.gen.yaml": "new\n",
+	})
+
+	// The write-to directory holds only the previous render.
+	var have []string
+	require.NoError(t, filepath.WalkDir(filepath.Join(root, "deploy"), func(path string, d os.DirEntry, err error) error {
+		require.NoError(t, err)
+		if !d.IsDir() {
+			rel, err := filepath.Rel(root, path)
+			require.NoError(t, err)
+			have = append(have, filepath.ToSlash(rel))
+		}
+		return nil
+	}))
+	assert.Equal(t, []string{"deploy/components/a/a.gen.yaml"}, have)
+
+	// The next render is not affected by the stage left behind.
+	next, err := New(root, "deploy")
+	require.NoError(t, err)
+	writeFiles(t, filepath.Join(root, next.WriteTo()), map[string]string{"components/a/a.gen.yaml": "next\n"})
+	changes, err := next.Promote(t.Context(), []string{"components"})
+	require.NoError(t, err)
+	assert.Equal(t, &Changes{Changed: []string{"components/a/a.gen.yaml"}}, changes)
+}
+
+func TestNewPrivate(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("TMPDIR", tmp)
+	root := t.TempDir()
+
+	s, err := NewPrivate(root)
+	require.NoError(t, err)
+	dir := s.WriteTo()
+	assert.True(t, filepath.IsAbs(dir), "expected a private stage addressed by absolute path")
+	assert.Equal(t, tmp, filepath.Dir(dir))
+	assert.Equal(t, dir, s.Dir())
+	assert.True(t, IsStage(s.WriteTo()))
+	writeFiles(t, dir, map[string]string{"components/a/a.gen.yaml": "{}\n"})
+
+	files, err := s.Files()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"components/a/a.gen.yaml"}, files)
+	_, err = s.Diff(nil)
+	assert.ErrorContains(t, err, "private stage has no write-to directory")
+
+	s.Remove(t.Context())
+	assert.NoDirExists(t, dir)
+}
+
+func TestPromote(t *testing.T) {
+	root := t.TempDir()
+	writeTo := filepath.Join(root, "deploy")
+	writeFiles(t, writeTo, map[string]string{
+		"components/a/a.gen.yaml":        "same\n",
+		"components/b/b.gen.yaml":        "old\n",
+		"components/c/manifests/x.yaml":  "same\n",
+		"components/c/manifests/y.yaml":  "stale\n",
+		"components/d/d.gen.yaml":        "kept\n",
+		"components/e/e.gen.yaml/z.yaml": "was a directory\n",
+	})
+	// Backdate every file to observe which files promotion writes.
+	past := time.Now().Add(-time.Hour).Truncate(time.Second)
+	require.NoError(t, filepath.WalkDir(writeTo, func(path string, d os.DirEntry, err error) error {
+		require.NoError(t, err)
+		return os.Chtimes(path, past, past)
+	}))
+
+	s, err := New(root, "deploy")
+	require.NoError(t, err)
+	writeFiles(t, filepath.Join(root, s.WriteTo()), map[string]string{
+		"components/a/a.gen.yaml":       "same\n",
+		"components/b/b.gen.yaml":       "new\n",
+		"components/c/manifests/x.yaml": "same\n",
+		"components/e/e.gen.yaml":       "now a file\n",
+		"components/f/f.gen.yaml":       "added\n",
+	})
+	replace := []string{"components/c/manifests", "components/e/e.gen.yaml"}
+
+	want := &Changes{
+		Added:   []string{"components/e/e.gen.yaml", "components/f/f.gen.yaml"},
+		Changed: []string{"components/b/b.gen.yaml"},
+		Removed: []string{"components/c/manifests/y.yaml", "components/e/e.gen.yaml/z.yaml"},
+	}
+	diff, err := s.Diff(replace)
+	require.NoError(t, err)
+	assert.Equal(t, want, diff)
+
+	changes, err := s.Promote(t.Context(), replace)
+	require.NoError(t, err)
+	assert.Equal(t, want, changes)
+
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(writeTo, filepath.FromSlash(name)))
+		require.NoError(t, err)
+		return string(data)
+	}
+	assert.Equal(t, "new\n", read("components/b/b.gen.yaml"))
+	assert.Equal(t, "now a file\n", read("components/e/e.gen.yaml"))
+	assert.Equal(t, "added\n", read("components/f/f.gen.yaml"))
+	assert.Equal(t, "kept\n", read("components/d/d.gen.yaml"), "files outside replaced paths are never removed")
+	assert.NoFileExists(t, filepath.Join(writeTo, "components", "c", "manifests", "y.yaml"))
+
+	// Promoted files keep the permissions of the staged file.
+	info, err := os.Stat(filepath.Join(writeTo, "components", "f", "f.gen.yaml"))
+	require.NoError(t, err)
+	staged, err := os.Stat(filepath.Join(root, s.WriteTo(), "components", "f", "f.gen.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, staged.Mode(), info.Mode())
+
+	// Byte-identical files are untouched.
+	for _, name := range []string{"components/a/a.gen.yaml", "components/c/manifests/x.yaml"} {
+		info, err := os.Stat(filepath.Join(writeTo, filepath.FromSlash(name)))
+		require.NoError(t, err)
+		assert.True(t, info.ModTime().Equal(past), "%s was rewritten", name)
+	}
+
+	// Promoting again changes nothing.
+	changes, err = s.Promote(t.Context(), replace)
+	require.NoError(t, err)
+	assert.True(t, changes.Empty())
+}
+
+func TestChangesCheck(t *testing.T) {
+	var buf bytes.Buffer
+	c := &Changes{
+		Added:   []string{"components/b/b.gen.yaml"},
+		Changed: []string{"components/a/a.gen.yaml", "components/c/c.gen.yaml"},
+		Removed: []string{"components/bb/bb.gen.yaml"},
+	}
+	err := c.Check(&buf, "deploy")
+	require.ErrorContains(t, err, "4 files differ from deploy")
+	assert.Equal(t, `changed deploy/components/a/a.gen.yaml
+added deploy/components/b/b.gen.yaml
+removed deploy/components/bb/bb.gen.yaml
+changed deploy/components/c/c.gen.yaml
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, (&Changes{}).Check(&buf, "deploy"))
+	assert.Empty(t, buf.String())
+}
@@ -0,0 +1,422 @@
+// Package trace records render spans in memory then writes them as Chrome
+// trace-event JSON or OTLP-JSON, along with a critical path summary of the
+// task graph.  Spans are recorded only when a [Recorder] is stored in the
+// context with [NewContext], so call sites trace unconditionally.
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/holos-run/holos/internal/errors"
+	"github.com/spf13/pflag"
+)
+
+const (
+	// FormatChrome represents the Chrome trace-event JSON format, viewable in
+	// chrome://tracing or https://ui.perfetto.dev.
+	FormatChrome = "chrome"
+	// FormatOTLP represents the OpenTelemetry OTLP-JSON file format.
+	FormatOTLP = "otlp"
+	// CategoryTask represents the spans of task graph nodes.  Task spans are
+	// named by canonical task id and linked to the tasks they depend on.
+	CategoryTask = "task"
+)
+
+type key int
+
+const (
+	recorderKey key = iota
+	spanKey
+)
+
+// Recorder records spans.  A Recorder is safe for concurrent use.
+type Recorder struct {
+	mu      sync.Mutex
+	traceID [16]byte
+	nextID  uint64
+	spans   []*Span
+}
+
+// New returns a new Recorder.
+func New() *Recorder {
+	r := &Recorder{}
+	_, _ = rand.Read(r.traceID[:])
+	return r
+}
+
+// NewContext returns a new Context carrying recorder r.
+func NewContext(ctx context.Context, r *Recorder) context.Context {
+	return context.WithValue(ctx, recorderKey, r)
+}
+
+// FromContext returns the Recorder stored in ctx by NewContext, or nil.
+func FromContext(ctx context.Context) *Recorder {
+	r, _ := ctx.Value(recorderKey).(*Recorder)
+	return r
+}
+
+// Span represents one timed operation.
+type Span struct {
+	// Name represents the operation, or the canonical task id of a task span.
+	Name string
+	// Category groups spans, for example task, cue, helm, or artifact.
+	Category string
+	// Args represents span attributes.
+	Args map[string]string
+	// Deps represents the names of the task spans a task span waited on.
+	Deps []string
+	// StartTime represents the start time.
+	StartTime time.Time
+	// EndTime represents the end time.
+	EndTime time.Time
+
+	rec    *Recorder
+	id     uint64
+	parent uint64
+	// lane represents the id of the root span, nested spans share the lane of
+	// their root so concurrent tasks render on separate tracks.
+	lane uint64
+}
+
+// Start starts a span named name as a child of the span in ctx, if any.  args
+// are attribute key value pairs.  Start returns a nil span recording nothing
+// when ctx carries no Recorder.  Call End to record the span.
+func Start(ctx context.Context, category, name string, args ...string) (context.Context, *Span) {
+	r := FromContext(ctx)
+	if r == nil {
+		return ctx, nil
+	}
+	s := &Span{Name: name, Category: category, StartTime: time.Now(), rec: r}
+	if len(args) > 0 {
+		s.Args = make(map[string]string, len(args)/2)
+		for idx := 0; idx+1 < len(args); idx += 2 {
+			s.Args[args[idx]] = args[idx+1]
+		}
+	}
+	r.mu.Lock()
+	r.nextID++
+	s.id = r.nextID
+	r.mu.Unlock()
+	s.lane = s.id
+	if parent, ok := ctx.Value(spanKey).(*Span); ok && parent.rec == r {
+		s.parent = parent.id
+		s.lane = parent.lane
+	}
+	return context.WithValue(ctx, spanKey, s), s
+}
+
+// Link records the names of the task spans s waited on.
+func (s *Span) Link(deps ...string) {
+	if s == nil {
+		return
+	}
+	s.Deps = append(s.Deps, deps...)
+}
+
+// End records the span.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.EndTime = time.Now()
+	s.rec.mu.Lock()
+	s.rec.spans = append(s.rec.spans, s)
+	s.rec.mu.Unlock()
+}
+
+// Spans returns the recorded spans ordered by start time.
+func (r *Recorder) Spans() []*Span {
+	r.mu.Lock()
+	spans := slices.Clone(r.spans)
+	r.mu.Unlock()
+	slices.SortFunc(spans, func(a, b *Span) int {
+		if c := a.StartTime.Compare(b.StartTime); c != 0 {
+			return c
+		}
+		return int(a.id) - int(b.id)
+	})
+	return spans
+}
+
+// CriticalPath returns the chain of task spans determining the duration of
+// the task graph.  The path ends with the task span ending last and walks back
+// through the dependency ending last at each step.
+func (r *Recorder) CriticalPath() []*Span {
+	tasks := make(map[string]*Span)
+	var last *Span
+	for _, s := range r.Spans() {
+		if s.Category != CategoryTask {
+			continue
+		}
+		tasks[s.Name] = s
+		if last == nil || s.EndTime.After(last.EndTime) {
+			last = s
+		}
+	}
+	var path []*Span
+	for cur := last; cur != nil; {
+		path = append(path, cur)
+		var next *Span
+		for _, dep := range cur.Deps {
+			if s, ok := tasks[dep]; ok && (next == nil || s.EndTime.After(next.EndTime)) {
+				next = s
+			}
+		}
+		cur = next
+	}
+	slices.Reverse(path)
+	return path
+}
+
+// WriteSummary writes the critical path of the task graph to w.
+func (r *Recorder) WriteSummary(w io.Writer) error {
+	spans := r.Spans()
+	var b strings.Builder
+	if len(spans) > 0 {
+		var first, last time.Time
+		for _, s := range spans {
+			if first.IsZero() || s.StartTime.Before(first) {
+				first = s.StartTime
+			}
+			if s.EndTime.After(last) {
+				last = s.EndTime
+			}
+		}
+		path := r.CriticalPath()
+		var critical time.Duration
+		if len(path) > 0 {
+			critical = path[len(path)-1].EndTime.Sub(path[0].StartTime)
+		}
+		fmt.Fprintf(&b, "critical path %s of %s total\n", round(critical), round(last.Sub(first)))
+		for _, s := range path {
+			fmt.Fprintf(&b, "  %10s  %s", round(s.EndTime.Sub(s.StartTime)), s.Name)
+			if kind := s.Args["kind"]; kind != "" {
+				fmt.Fprintf(&b, " (%s)", kind)
+			}
+			b.WriteString("\n")
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return errors.Wrap(err)
+}
+
+func round(d time.Duration) time.Duration {
+	return d.Round(time.Microsecond)
+}
+
+// Write writes the recorded spans to w in format.
+func (r *Recorder) Write(w io.Writer, format string) error {
+	switch format {
+	case FormatChrome:
+		return r.WriteChrome(w)
+	case FormatOTLP:
+		return r.WriteOTLP(w)
+	default:
+		return errors.Format("unsupported trace format %q: must be %s or %s", format, FormatChrome, FormatOTLP)
+	}
+}
+
+type chromeEvent struct {
+	Name  string            `json:"name"`
+	Cat   string            `json:"cat,omitempty"`
+	Phase string            `json:"ph"`
+	TS    float64           `json:"ts"`
+	Dur   float64           `json:"dur,omitempty"`
+	PID   int               `json:"pid"`
+	TID   uint64            `json:"tid"`
+	Args  map[string]string `json:"args,omitempty"`
+}
+
+// WriteChrome writes the spans as Chrome trace-event JSON.  Each root span
+// and its descendants render on their own track named by the root span.
+func (r *Recorder) WriteChrome(w io.Writer) error {
+	spans := r.Spans()
+	events := make([]chromeEvent, 0, len(spans)*2)
+	var epoch time.Time
+	if len(spans) > 0 {
+		epoch = spans[0].StartTime
+	}
+	for _, s := range spans {
+		if s.id == s.lane {
+			events = append(events, chromeEvent{
+				Name:  "thread_name",
+				Phase: "M",
+				PID:   1,
+				TID:   s.lane,
+				Args:  map[string]string{"name": s.Name},
+			})
+		}
+		args := maps.Clone(s.Args)
+		if len(s.Deps) > 0 {
+			if args == nil {
+				args = make(map[string]string, 1)
+			}
+			args["deps"] = strings.Join(s.Deps, ", ")
+		}
+		events = append(events, chromeEvent{
+			Name:  s.Name,
+			Cat:   s.Category,
+			Phase: "X",
+			TS:    micros(s.StartTime.Sub(epoch)),
+			Dur:   micros(s.EndTime.Sub(s.StartTime)),
+			PID:   1,
+			TID:   s.lane,
+			Args:  args,
+		})
+	}
+	doc := struct {
+		TraceEvents     []chromeEvent `json:"traceEvents"`
+		DisplayTimeUnit string        `json:"displayTimeUnit"`
+	}{TraceEvents: events, DisplayTimeUnit: "ms"}
+	return errors.Wrap(json.NewEncoder(w).Encode(doc))
+}
+
+func micros(d time.Duration) float64 {
+	return float64(d.Nanoseconds()) / 1e3
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpLink struct {
+	TraceID string `json:"traceId"`
+	SpanID  string `json:"spanId"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Links             []otlpLink      `json:"links,omitempty"`
+}
+
+// WriteOTLP writes the spans as one OTLP-JSON ExportTraceServiceRequest, the
+// format of the OpenTelemetry collector file exporter.  Task dependencies are
+// span links.
+func (r *Recorder) WriteOTLP(w io.Writer) error {
+	spans := r.Spans()
+	traceID := hex.EncodeToString(r.traceID[:])
+	ids := make(map[string]string)
+	for _, s := range spans {
+		if s.Category == CategoryTask {
+			ids[s.Name] = spanID(s.id)
+		}
+	}
+
+	out := make([]otlpSpan, 0, len(spans))
+	for _, s := range spans {
+		span := otlpSpan{
+			TraceID:           traceID,
+			SpanID:            spanID(s.id),
+			Name:              s.Name,
+			Kind:              1, // SPAN_KIND_INTERNAL
+			StartTimeUnixNano: strconv.FormatInt(s.StartTime.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.EndTime.UnixNano(), 10),
+			Attributes:        []otlpAttribute{{Key: "holos.category", Value: otlpValue{s.Category}}},
+		}
+		if s.parent != 0 {
+			span.ParentSpanID = spanID(s.parent)
+		}
+		for _, k := range slices.Sorted(maps.Keys(s.Args)) {
+			span.Attributes = append(span.Attributes, otlpAttribute{Key: "holos." + k, Value: otlpValue{s.Args[k]}})
+		}
+		for _, dep := range s.Deps {
+			if id, ok := ids[dep]; ok {
+				span.Links = append(span.Links, otlpLink{TraceID: traceID, SpanID: id})
+			}
+		}
+		out = append(out, span)
+	}
+
+	doc := map[string]any{
+		"resourceSpans": []any{map[string]any{
+			"resource": map[string]any{
+				"attributes": []otlpAttribute{{Key: "service.name", Value: otlpValue{"holos"}}},
+			},
+			"scopeSpans": []any{map[string]any{
+				"scope": map[string]string{"name": "github.com/holos-run/holos"},
+				"spans": out,
+			}},
+		}},
+	}
+	return errors.Wrap(json.NewEncoder(w).Encode(doc))
+}
+
+func spanID(id uint64) string {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], id)
+	return hex.EncodeToString(b[:])
+}
+
+// Config represents the trace flags of the render commands.
+type Config struct {
+	// File represents the trace output file.  Empty disables tracing.
+	File string
+	// Format represents the trace file format, chrome or otlp.
+	Format string
+}
+
+// FlagSet returns the trace flags bound to c.
+func (c *Config) FlagSet() *pflag.FlagSet {
+	if c.Format == "" {
+		c.Format = FormatChrome
+	}
+	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+	fs.StringVar(&c.File, "trace-file", c.File, "write render spans to this file and print the critical path")
+	fs.StringVar(&c.Format, "trace-format", c.Format, fmt.Sprintf("trace file format (%s|%s)", FormatChrome, FormatOTLP))
+	return fs
+}
+
+// Run calls fn, recording spans when c.File is set.  The trace file and the
+// critical path summary are written whether or not fn returns an error, the
+// trace of a failed render is often the most useful one.
+func (c *Config) Run(ctx context.Context, stderr io.Writer, fn func(context.Context) error) error {
+	if c.File == "" {
+		return fn(ctx)
+	}
+	if c.Format != FormatChrome && c.Format != FormatOTLP {
+		return errors.Format("unsupported trace format %q: must be %s or %s", c.Format, FormatChrome, FormatOTLP)
+	}
+	r := New()
+	err := fn(NewContext(ctx, r))
+	return errors.Join(err, c.write(r, stderr))
+}
+
+func (c *Config) write(r *Recorder, stderr io.Writer) error {
+	f, err := os.Create(c.File)
+	if err != nil {
+		return errors.Wrap(err)
+	}
+	if err := r.Write(f, c.Format); err != nil {
+		_ = f.Close()
+		return errors.Wrap(err)
+	}
+	if err := f.Close(); err != nil {
+		return errors.Wrap(err)
+	}
+	return r.WriteSummary(stderr)
+}
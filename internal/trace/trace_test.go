@@ -0,0 +1,149 @@
+package trace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// taskGraph returns a recorder holding a diamond of task spans where b is the
+// slower branch, plus a nested span under a.
+func taskGraph() *Recorder {
+	epoch := time.Unix(1700000000, 0)
+	at := func(ms int) time.Time { return epoch.Add(time.Duration(ms) * time.Millisecond) }
+	r := &Recorder{}
+	r.spans = []*Span{
+		{Name: "a", Category: CategoryTask, Args: map[string]string{"kind": "Helm"}, StartTime: at(0), EndTime: at(10), rec: r, id: 1, lane: 1},
+		{Name: "helm pull", Category: "helm", StartTime: at(1), EndTime: at(8), rec: r, id: 2, parent: 1, lane: 1},
+		{Name: "b", Category: CategoryTask, Deps: []string{"a"}, StartTime: at(10), EndTime: at(40), rec: r, id: 3, lane: 3},
+		{Name: "c", Category: CategoryTask, Deps: []string{"a"}, StartTime: at(10), EndTime: at(20), rec: r, id: 4, lane: 4},
+		{Name: "d", Category: CategoryTask, Args: map[string]string{"kind": "Artifact"}, Deps: []string{"b", "c"}, StartTime: at(40), EndTime: at(45), rec: r, id: 5, lane: 5},
+	}
+	return r
+}
+
+func TestStartWithoutRecorder(t *testing.T) {
+	ctx, span := Start(t.Context(), CategoryTask, "a")
+	assert.Nil(t, span)
+	assert.Equal(t, t.Context(), ctx)
+	// Nil spans are safe to use.
+	span.Link("b")
+	span.End()
+}
+
+func TestStartNested(t *testing.T) {
+	r := New()
+	ctx := NewContext(t.Context(), r)
+	ctx, parent := Start(ctx, CategoryTask, "a", "kind", "Helm")
+	_, child := Start(ctx, "helm", "helm pull")
+	child.End()
+	parent.End()
+
+	spans := r.Spans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "a", spans[0].Name)
+	assert.Equal(t, map[string]string{"kind": "Helm"}, spans[0].Args)
+	assert.Equal(t, spans[0].id, spans[1].parent)
+	assert.Equal(t, spans[0].lane, spans[1].lane, "expected nested spans to share a track")
+}
+
+func TestCriticalPath(t *testing.T) {
+	var names []string
+	for _, s := range taskGraph().CriticalPath() {
+		names = append(names, s.Name)
+	}
+	assert.Equal(t, []string{"a", "b", "d"}, names)
+}
+
+func TestWriteSummary(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, taskGraph().WriteSummary(&buf))
+	assert.Equal(t, `critical path 45ms of 45ms total
+        10ms  a (Helm)
+        30ms  b
+         5ms  d (Artifact)
+`, buf.String())
+}
+
+func TestWriteChrome(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, taskGraph().Write(&buf, FormatChrome))
+
+	var doc struct {
+		TraceEvents []chromeEvent `json:"traceEvents"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	// One metadata event per root span plus one complete event per span.
+	require.Len(t, doc.TraceEvents, 9)
+	assert.Equal(t, chromeEvent{Name: "thread_name", Phase: "M", PID: 1, TID: 1, Args: map[string]string{"name": "a"}}, doc.TraceEvents[0])
+	assert.Equal(t, chromeEvent{Name: "a", Cat: CategoryTask, Phase: "X", TS: 0, Dur: 10000, PID: 1, TID: 1, Args: map[string]string{"kind": "Helm"}}, doc.TraceEvents[1])
+	assert.Equal(t, chromeEvent{Name: "helm pull", Cat: "helm", Phase: "X", TS: 1000, Dur: 7000, PID: 1, TID: 1}, doc.TraceEvents[2])
+	assert.Equal(t, map[string]string{"kind": "Artifact", "deps": "b, c"}, doc.TraceEvents[8].Args)
+}
+
+func TestWriteOTLP(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, taskGraph().Write(&buf, FormatOTLP))
+
+	var doc struct {
+		ResourceSpans []struct {
+			ScopeSpans []struct {
+				Spans []otlpSpan `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	spans := doc.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 5)
+	assert.Equal(t, "0000000000000001", spans[1].ParentSpanID)
+	assert.Equal(t, "1700000000001000000", spans[1].StartTimeUnixNano)
+	assert.Equal(t, []otlpAttribute{
+		{Key: "holos.category", Value: otlpValue{CategoryTask}},
+		{Key: "holos.kind", Value: otlpValue{"Artifact"}},
+	}, spans[4].Attributes)
+	require.Len(t, spans[4].Links, 2)
+	assert.Equal(t, "0000000000000003", spans[4].Links[0].SpanID)
+	assert.Equal(t, "0000000000000004", spans[4].Links[1].SpanID)
+}
+
+func TestConfigRun(t *testing.T) {
+	t.Run("Disabled", func(t *testing.T) {
+		cfg := &Config{}
+		err := cfg.Run(t.Context(), &bytes.Buffer{}, func(ctx context.Context) error {
+			assert.Nil(t, FromContext(ctx))
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("WritesOnError", func(t *testing.T) {
+		cfg := &Config{File: filepath.Join(t.TempDir(), "trace.json"), Format: FormatChrome}
+		var stderr bytes.Buffer
+		err := cfg.Run(t.Context(), &stderr, func(ctx context.Context) error {
+			_, span := Start(ctx, CategoryTask, "a")
+			span.End()
+			return assert.AnError
+		})
+		require.ErrorIs(t, err, assert.AnError)
+		assert.FileExists(t, cfg.File)
+		assert.Contains(t, stderr.String(), "critical path")
+	})
+
+	t.Run("UnsupportedFormat", func(t *testing.T) {
+		cfg := &Config{File: filepath.Join(t.TempDir(), "trace.json"), Format: "bad"}
+		err := cfg.Run(t.Context(), &bytes.Buffer{}, func(ctx context.Context) error {
+			t.Fatal("expected fn not to run")
+			return nil
+		})
+		require.ErrorContains(t, err, `unsupported trace format "bad"`)
+		_, statErr := os.Stat(cfg.File)
+		assert.True(t, os.IsNotExist(statErr))
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"sync"
 
 	"github.com/holos-run/holos/internal/logger"
+	"github.com/holos-run/holos/internal/redact"
 )
 
 var mu sync.Mutex
@@ -86,7 +87,7 @@ func RunCmdFunc(ctx context.Context, w io.Writer, name string, args []string, pr
 		err = fmt.Errorf("command failed:\n\t%s\n\t%w", command, err)
 		mu.Lock()
 		defer mu.Unlock()
-		_, err2 := io.Copy(w, result.Stderr)
+		_, err2 := copyStderr(w, result.Stderr)
 		if err2 != nil {
 			err = fmt.Errorf("could not copy stderr: %s: %w", err2.Error(), err)
 		}
@@ -100,7 +101,7 @@ func RunCmdW(ctx context.Context, w io.Writer, name string, args ...string) (res
 	if err != nil {
 		mu.Lock()
 		defer mu.Unlock()
-		_, err2 := io.Copy(w, result.Stderr)
+		_, err2 := copyStderr(w, result.Stderr)
 		if err2 != nil {
 			err = fmt.Errorf("could not copy stderr: %s: %w", err2.Error(), err)
 		}
@@ -113,12 +114,19 @@ func RunCmdA(ctx context.Context, w io.Writer, name string, args ...string) (res
 	result, err = RunCmd(ctx, name, args...)
 	mu.Lock()
 	defer mu.Unlock()
-	if _, err2 := io.Copy(w, result.Stderr); err2 != nil {
+	if _, err2 := copyStderr(w, result.Stderr); err2 != nil {
 		err = fmt.Errorf("could not copy stderr: %s: %w", err2.Error(), err)
 	}
 	return result, err
 }
 
+// copyStderr drains stderr to w with sensitive values masked.
+func copyStderr(w io.Writer, stderr *bytes.Buffer) (int, error) {
+	s := stderr.String()
+	stderr.Reset()
+	return io.WriteString(w, redact.String(s))
+}
+
 // RunInteractiveCmd runs a command within a context but allows the command to
 // accept stdin interactively from the user. The caller is expected to handle
 // errors.
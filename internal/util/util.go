@@ -22,6 +22,14 @@ func EnsureNewline(b []byte) []byte {
 	return b
 }
 
+// Plural returns one if n is 1, otherwise many.
+func Plural(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
 // FindCueMod returns the root module location containing the cue.mod.
 func FindCueMod(path string) (root string, err error) {
 	origPath := path